@@ -0,0 +1,69 @@
+package drift
+
+import "fmt"
+
+// DefaultRecoveryTolerance is the tolerance, in percent, within which a
+// mode counts as recovered for the adaptation objective and the
+// Transitions of a scored ExperimentResult.
+const DefaultRecoveryTolerance = 10
+
+// Transition is a terrain change in a benchmark and how quickly performance
+// came back after it.
+type Transition struct {
+	Window    int     `json:"window"` // Number of the first window on the new terrain
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Before    float64 `json:"before_pct"` // Accuracy in the last window before the change
+	Recovered bool    `json:"recovered"`
+	Windows   int     `json:"windows"` // Windows before the one that recovered, or until the end
+	Steps     int     `json:"steps"`   // Steps in those windows
+}
+
+// Metrics converts the transition to metrics stamped with its window and
+// labeled with mode and terrains, for sending to a MetricSink.
+func (t Transition) Metrics(mode string) []Metric {
+	labels := map[string]string{"mode": mode, "from": t.From, "to": t.To}
+	step := uint64(t.Window)
+	recovered := 0.0
+	if t.Recovered {
+		recovered = 1
+	}
+	return []Metric{
+		{Name: "recovery_windows", Value: float64(t.Windows), Step: step, Labels: labels},
+		{Name: "recovery_steps", Value: float64(t.Steps), Step: step, Labels: labels},
+		{Name: "recovered", Value: recovered, Step: step, Labels: labels},
+	}
+}
+
+func (t Transition) String() string {
+	s := fmt.Sprintf("window %d %s→%s: ", t.Window, t.From, t.To)
+	if !t.Recovered {
+		return s + fmt.Sprintf("not recovered after %d windows (%d steps)", t.Windows, t.Steps)
+	}
+	return s + fmt.Sprintf("recovered after %d windows (%d steps)", t.Windows, t.Steps)
+}
+
+// AdaptationSpeed finds every terrain change in windows and measures how
+// long accuracy took to return to within tolerance percent of its level in
+// the last window before the change.
+func AdaptationSpeed(windows []WindowMetrics, tolerance float64) []Transition {
+	var out []Transition
+	for i := 1; i < len(windows); i++ {
+		prev, cur := windows[i-1], windows[i]
+		if cur.Terrain == prev.Terrain {
+			continue
+		}
+		t := Transition{Window: cur.WindowNum, From: prev.Terrain, To: cur.Terrain, Before: prev.Accuracy}
+		target := prev.Accuracy * (1 - tolerance/100)
+		for _, w := range windows[i:] {
+			if w.Accuracy >= target {
+				t.Recovered = true
+				break
+			}
+			t.Windows++
+			t.Steps += w.TotalSteps
+		}
+		out = append(out, t)
+	}
+	return out
+}
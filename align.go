@@ -0,0 +1,140 @@
+package drift
+
+import (
+	"fmt"
+)
+
+// LinkProjection is a learned affine map applied to a link's source
+// activations before transfer: payload = W·src + B, with W stored row-major
+// as Out rows of In weights.
+type LinkProjection struct {
+	In  int       `json:"in"`
+	Out int       `json:"out"`
+	W   []float32 `json:"w"`
+	B   []float32 `json:"b"`
+}
+
+// NewLinkProjection creates a zero projection from in to out values.
+func NewLinkProjection(in, out int) *LinkProjection {
+	return &LinkProjection{In: in, Out: out, W: make([]float32, in*out), B: make([]float32, out)}
+}
+
+// Apply writes the projection of src into dst. Missing source values count
+// as zero.
+func (p *LinkProjection) Apply(src, dst []float32) {
+	for o := 0; o < p.Out && o < len(dst); o++ {
+		sum := p.B[o]
+		row := p.W[o*p.In : (o+1)*p.In]
+		for i := 0; i < p.In && i < len(src); i++ {
+			sum += row[i] * src[i]
+		}
+		dst[o] = sum
+	}
+}
+
+// SetLinkProjection installs p on a link, or removes the projection when p
+// is nil, restoring plain copying of activations.
+func (r *Runtime) SetLinkProjection(name string, p *LinkProjection) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.link(name)
+	if l == nil {
+		return fmt.Errorf("link %q not found", name)
+	}
+	if p != nil && p.Out != l.cfg.LinkSize {
+		return fmt.Errorf("link %q: projection has %d outputs, link size is %d", name, p.Out, l.cfg.LinkSize)
+	}
+	l.proj = p
+	return nil
+}
+
+// AlignmentOptions configures AlignLink.
+type AlignmentOptions struct {
+	Samples int     // Observations to collect; defaults to 500
+	Epochs  int     // Passes over the samples; defaults to 20
+	LR      float32 // SGD learning rate; defaults to 0.01
+	// Settle is the number of forward steps each observation is held for
+	// before reading the source layer, letting it propagate through the
+	// pipelined step state. Defaults to the link's SourceLayer.
+	Settle int
+	Seed   int64
+}
+
+// AlignLink warm-starts a link before task training: it fits a projection
+// from the link's source activations to a supervisory signal, such as a
+// one-hot terrain code, and installs it on the link. sample returns an input
+// for the link's source model and the signal the payload should carry,
+// zero-padded to the link size. The source model's weights are not changed
+// and the runtime's step count is unaffected. It returns the final mean
+// squared reconstruction error.
+func (r *Runtime) AlignLink(name string, sample func() (input, target []float32), opts AlignmentOptions) (float64, error) {
+	if opts.Samples <= 0 {
+		opts.Samples = 500
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = 20
+	}
+	if opts.LR <= 0 {
+		opts.LR = 0.01
+	}
+
+	r.mu.Lock()
+	l := r.link(name)
+	if l == nil {
+		r.mu.Unlock()
+		return 0, fmt.Errorf("link %q not found", name)
+	}
+	lc := l.cfg
+	src := r.models[lc.SourceModel]
+	net, inputSize := src.net, len(src.input)
+	r.mu.Unlock()
+
+	settle := opts.Settle
+	if settle <= 0 {
+		settle = max(lc.SourceLayer, 1)
+	}
+
+	xs := make([][]float32, opts.Samples)
+	ys := make([][]float32, opts.Samples)
+	state := net.InitStepState(inputSize)
+	for k := range xs {
+		in, target := sample()
+		x := make([]float32, inputSize)
+		copy(x, in)
+		state.SetInput(x)
+		for s := 0; s < settle; s++ {
+			net.StepForward(state)
+		}
+		xs[k] = append([]float32(nil), state.GetLayerOutput(lc.SourceLayer)...)
+		ys[k] = make([]float32, lc.LinkSize)
+		copy(ys[k], target)
+	}
+
+	p := NewLinkProjection(len(xs[0]), lc.LinkSize)
+	rng := seededStream(opts.Seed, SubStream(StreamTraining, name))
+	out := make([]float32, p.Out)
+	var mse float64
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		mse = 0
+		for _, k := range perm(rng, len(xs)) {
+			x, y := xs[k], ys[k]
+			p.Apply(x, out)
+			for o := range out {
+				e := out[o] - y[o]
+				mse += float64(e * e)
+				g := opts.LR * e
+				p.B[o] -= g
+				row := p.W[o*p.In : (o+1)*p.In]
+				for i := range row {
+					row[i] -= g * x[i]
+				}
+			}
+		}
+		mse /= float64(len(xs) * p.Out)
+	}
+
+	if err := r.SetLinkProjection(name, p); err != nil {
+		return mse, err
+	}
+	return mse, nil
+}
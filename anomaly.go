@@ -0,0 +1,146 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// LinkAnomalyDetector tracks running statistics of a link's payload and raises
+// EventLinkAnomaly when the channel starts emitting out-of-distribution values.
+// Two conditions are checked on every payload:
+//
+//   - spike: some element's z-score against the long-run statistics exceeds ZThreshold
+//   - shift: a fast moving average of the payload has drifted more than
+//     ShiftThreshold long-run standard deviations from the slow one
+//
+// Either usually means the source model has collapsed or a remote peer is
+// sending corrupted frames.
+type LinkAnomalyDetector struct {
+	Link           string
+	ZThreshold     float64 // Per-element z-score that counts as a spike
+	ShiftThreshold float64 // Mean fast/slow separation, in standard deviations
+	SlowRate       float64 // EMA rate of the long-run statistics
+	FastRate       float64 // EMA rate of the short-run mean
+	Warmup         int     // Payloads observed before anomalies are reported
+
+	emit   EventHandler
+	count  int
+	mean   []float64
+	vari   []float64
+	fast   []float64
+	firing bool
+}
+
+// NewLinkAnomalyDetector creates a detector for the named link with defaults
+// suitable for payloads produced once per step.
+func NewLinkAnomalyDetector(link string, emit EventHandler) *LinkAnomalyDetector {
+	return &LinkAnomalyDetector{
+		Link:           link,
+		ZThreshold:     6,
+		ShiftThreshold: 2,
+		SlowRate:       0.001,
+		FastRate:       0.05,
+		Warmup:         200,
+		emit:           emit,
+	}
+}
+
+// Observe folds payload into the running statistics and returns the larger of
+// the spike and shift scores, each divided by its threshold, so a result
+// above 1 means the payload was anomalous. A NaN or infinite element scores
+// +Inf, even during warmup, and is left out of the statistics.
+func (d *LinkAnomalyDetector) Observe(step int, payload []float32) float64 {
+	if len(d.mean) != len(payload) {
+		d.reset(len(payload))
+	}
+	finite := true
+	for _, v := range payload {
+		if x := float64(v); math.IsNaN(x) || math.IsInf(x, 0) {
+			finite = false
+			break
+		}
+	}
+	d.count++
+	if d.count == 1 {
+		if !finite {
+			// Seeding from a corrupt payload would skew every later score.
+			d.count = 0
+			return d.report(step, math.Inf(1), math.Inf(1), 0)
+		}
+		for i, v := range payload {
+			d.mean[i] = float64(v)
+			d.fast[i] = float64(v)
+		}
+		return 0
+	}
+
+	// Until enough payloads have been seen the EMAs fall back to plain running
+	// averages, so the long-run variance isn't underestimated early on.
+	slow := math.Max(d.SlowRate, 1/float64(d.count))
+	fast := math.Max(d.FastRate, 1/float64(d.count))
+
+	var maxZ, shift float64
+	for i, v := range payload {
+		x := float64(v)
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			continue
+		}
+		std := math.Sqrt(d.vari[i]) + 1e-6
+		if z := math.Abs(x-d.mean[i]) / std; z > maxZ {
+			maxZ = z
+		}
+		d.fast[i] += fast * (x - d.fast[i])
+		shift += math.Abs(d.fast[i]-d.mean[i]) / std
+
+		delta := x - d.mean[i]
+		d.mean[i] += slow * delta
+		d.vari[i] = (1 - slow) * (d.vari[i] + slow*delta*delta)
+	}
+	if len(payload) > 0 {
+		shift /= float64(len(payload))
+	}
+	if !finite {
+		maxZ = math.Inf(1)
+	}
+
+	score := math.Max(maxZ/d.ZThreshold, shift/d.ShiftThreshold)
+	if d.count <= d.Warmup && finite {
+		return score
+	}
+	return d.report(step, score, maxZ, shift)
+}
+
+// report emits EventLinkAnomaly when score starts exceeding 1, and returns
+// score.
+func (d *LinkAnomalyDetector) report(step int, score, maxZ, shift float64) float64 {
+	anomalous := score > 1
+	if anomalous && !d.firing && d.emit != nil {
+		kind := "spike"
+		switch {
+		case math.IsInf(maxZ, 1):
+			kind = "non-finite values"
+		case shift/d.ShiftThreshold > maxZ/d.ZThreshold:
+			kind = "distribution shift"
+		}
+		d.emit(Event{
+			Kind:    EventLinkAnomaly,
+			Source:  d.Link,
+			Step:    step,
+			Value:   score,
+			Message: fmt.Sprintf("link %s payload %s (max z %.2f, shift %.2f)", d.Link, kind, maxZ, shift),
+			Time:    time.Now(),
+		})
+	}
+	d.firing = anomalous
+	return score
+}
+
+// reset discards all statistics and sizes them for n-element payloads.
+func (d *LinkAnomalyDetector) reset(n int) {
+	d.count = 0
+	d.mean = make([]float64, n)
+	d.vari = make([]float64, n)
+	d.fast = make([]float64, n)
+	d.firing = false
+}
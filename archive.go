@@ -0,0 +1,317 @@
+package drift
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// RunArchiveExt is the conventional extension of run archives.
+const RunArchiveExt = ".driftrun"
+
+// RunArchiveFormat is the manifest format version written by RunArchive.
+const RunArchiveFormat = 1
+
+// Well-known entries of a run archive. Logs and recordings are stored under
+// logs/ and recordings/ with the names they were given.
+const (
+	archiveManifest   = "manifest.json"
+	archiveConfig     = "config.json"
+	archiveCheckpoint = "checkpoint.json"
+	archiveResults    = "results.json"
+	archiveRecording  = "recordings/steps.jsonl"
+)
+
+// ArchiveEntry describes one file of a run archive.
+type ArchiveEntry struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// RunManifest is the table of contents of a run archive, stored as its
+// manifest.json.
+type RunManifest struct {
+	Format     int            `json:"format"`
+	Name       string         `json:"name"`
+	ConfigHash string         `json:"config_hash"` // See Config.Hash
+	Created    time.Time      `json:"created"`
+	Steps      uint64         `json:"steps,omitempty"` // Steps the runtime had taken when archived
+	Models     []string       `json:"models"`
+	Links      []string       `json:"links,omitempty"`
+	Files      []ArchiveEntry `json:"files"`
+}
+
+// RunArchive describes the contents of a .driftrun archive: one zip file
+// holding everything needed to reproduce and examine a run.
+type RunArchive struct {
+	Name       string
+	Config     *Config           // The exact config of the run; required
+	Runtime    *Runtime          // When set, its final weights are stored as checkpoint.json
+	Results    any               // When set, stored as results.json
+	Logs       map[string]string // Archive name to path of a metric or result log
+	Recordings map[string]string // Archive name to path of a link recording written by a Recorder
+	Recording  []StepRecord      // In-memory records, stored as recordings/steps.jsonl
+}
+
+// Write builds the archive and writes it atomically to path.
+func (a *RunArchive) Write(path string) error {
+	if a.Config == nil {
+		return fmt.Errorf("run archive: no config")
+	}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	m := RunManifest{
+		Format:  RunArchiveFormat,
+		Name:    a.Name,
+		Created: time.Now().UTC(),
+		Models:  sortedKeys(a.Config.Models),
+	}
+	if m.Name == "" {
+		m.Name = a.Config.Name
+	}
+	hash, err := a.Config.Hash()
+	if err != nil {
+		return err
+	}
+	m.ConfigHash = hash
+	for _, l := range a.Config.Links {
+		m.Links = append(m.Links, l.Name)
+	}
+	add := func(name string, data []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, ArchiveEntry{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		return nil
+	}
+
+	data, err := a.Config.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := add(archiveConfig, []byte(data)); err != nil {
+		return err
+	}
+	if a.Runtime != nil {
+		m.Steps = a.Runtime.Steps()
+		data, err := a.Runtime.checkpoint()
+		if err != nil {
+			return err
+		}
+		if err := add(archiveCheckpoint, data); err != nil {
+			return err
+		}
+	}
+	if a.Results != nil {
+		data, err := json.MarshalIndent(a.Results, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := add(archiveResults, data); err != nil {
+			return err
+		}
+	}
+	for _, dir := range []struct {
+		prefix string
+		files  map[string]string
+	}{{"logs/", a.Logs}, {"recordings/", a.Recordings}} {
+		for _, name := range sortedKeys(dir.files) {
+			data, err := os.ReadFile(dir.files[name])
+			if err != nil {
+				return err
+			}
+			if err := add(dir.prefix+name, data); err != nil {
+				return err
+			}
+		}
+	}
+	if a.Recording != nil {
+		var rec bytes.Buffer
+		enc := json.NewEncoder(&rec)
+		for _, s := range a.Recording {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		if err := add(archiveRecording, rec.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add(archiveManifest, manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644, false)
+}
+
+// ArchivedRun is a run archive opened for reading.
+type ArchivedRun struct {
+	Manifest RunManifest
+	Config   *Config
+
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+	sums  map[string]string
+}
+
+// OpenRunArchive opens the run archive at path and decodes its manifest and
+// config. The caller must Close it.
+func OpenRunArchive(path string) (*ArchivedRun, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	a := &ArchivedRun{zr: zr, files: make(map[string]*zip.File), sums: make(map[string]string)}
+	for _, f := range zr.File {
+		a.files[f.Name] = f
+	}
+	if err := a.open(); err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+func (a *ArchivedRun) open() error {
+	data, err := a.read(archiveManifest)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &a.Manifest); err != nil {
+		return fmt.Errorf("manifest: %w", err)
+	}
+	if a.Manifest.Format > RunArchiveFormat {
+		return fmt.Errorf("archive format %d is newer than supported", a.Manifest.Format)
+	}
+	for _, e := range a.Manifest.Files {
+		a.sums[e.Name] = e.SHA256
+	}
+	if data, err = a.ReadFile(archiveConfig); err != nil {
+		return err
+	}
+	a.Config, err = FromJSON(string(data))
+	return err
+}
+
+// Files returns the names of the files listed in the manifest, sorted.
+func (a *ArchivedRun) Files() []string {
+	return sortedKeys(a.sums)
+}
+
+// ReadFile returns the contents of the named file, verified against the
+// checksum recorded in the manifest.
+func (a *ArchivedRun) ReadFile(name string) ([]byte, error) {
+	sum, ok := a.sums[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: not in archive", name)
+	}
+	data, err := a.read(name)
+	if err != nil {
+		return nil, err
+	}
+	got := sha256.Sum256(data)
+	if hex.EncodeToString(got[:]) != sum {
+		return nil, fmt.Errorf("%s: checksum mismatch", name)
+	}
+	return data, nil
+}
+
+func (a *ArchivedRun) read(name string) ([]byte, error) {
+	f, ok := a.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: not in archive", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// Results decodes results.json into v.
+func (a *ArchivedRun) Results(v any) error {
+	data, err := a.ReadFile(archiveResults)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Recording returns the step records of the named recording, a name under
+// recordings/ as given to RunArchive.Recordings, or "steps.jsonl" for the
+// in-memory records.
+func (a *ArchivedRun) Recording(name string) ([]StepRecord, error) {
+	name = path.Join("recordings", name)
+	data, err := a.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var steps []StepRecord
+	err = readResultLines(bytes.NewReader(data), name, func(line json.RawMessage) error {
+		var s StepRecord
+		if err := json.Unmarshal(line, &s); err != nil {
+			return err
+		}
+		steps = append(steps, s)
+		return nil
+	})
+	return steps, err
+}
+
+// Recordings returns the names of the archived recordings, sorted.
+func (a *ArchivedRun) Recordings() []string {
+	var names []string
+	for _, name := range a.Files() {
+		if dir, file := path.Split(name); dir == "recordings/" {
+			names = append(names, file)
+		}
+	}
+	return names
+}
+
+// NewRuntime creates a runtime from the archived config, restoring the
+// archived weights when the archive has a checkpoint.
+func (a *ArchivedRun) NewRuntime() (*Runtime, error) {
+	r, err := NewRuntime(a.Config)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := a.sums[archiveCheckpoint]; !ok {
+		return r, nil
+	}
+	data, err := a.ReadFile(archiveCheckpoint)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := decodeCheckpoint(data)
+	if err != nil {
+		return nil, err
+	}
+	return r, r.restoreBundle(bundle)
+}
+
+// Close closes the archive.
+func (a *ArchivedRun) Close() error {
+	return a.zr.Close()
+}
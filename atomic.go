@@ -0,0 +1,64 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes data to path so that readers see either the old or
+// the new contents, never a partial file: the data goes to a temp file in the
+// same directory, is fsynced, and is renamed over path. With backup set, the
+// previous contents of path are first preserved in path+".bak".
+func writeFileAtomic(path string, data []byte, perm os.FileMode, backup bool) error {
+	dir := filepath.Dir(path)
+
+	if backup {
+		old, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := writeFileAtomic(path+".bak", old, perm, false); err != nil {
+				return err
+			}
+		case !os.IsNotExist(err):
+			return err
+		}
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpName, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir fsyncs a directory so a completed rename survives a crash.
+// Platforms that can't sync directories are ignored.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	defer d.Close()
+	d.Sync()
+	return nil
+}
@@ -0,0 +1,117 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// Binary configs are CBOR (RFC 8949) encodings of the JSON structure, keyed
+// by the same field names. Model definitions are embedded as byte strings
+// holding their JSON, so loading copies them instead of scanning them, which
+// is where most of the time goes when parsing a large JSON config. Files
+// start with the self-described CBOR tag, so tools can recognize them.
+
+// cborMagic is the self-described CBOR tag 55799.
+var cborMagic = []byte{0xd9, 0xd9, 0xf7}
+
+var (
+	cborEnc cbor.EncMode
+	cborDec cbor.DecMode
+)
+
+func init() {
+	// Deterministic encoding: sorted map keys and shortest numbers, so equal
+	// configs encode to equal bytes.
+	opts := cbor.CoreDetEncOptions()
+	opts.Time = cbor.TimeRFC3339Nano
+	var err error
+	if cborEnc, err = opts.EncMode(); err != nil {
+		panic(err)
+	}
+	if cborDec, err = (cbor.DecOptions{}).DecMode(); err != nil {
+		panic(err)
+	}
+}
+
+// ToBinary serializes the config to its binary (CBOR) form. Model
+// definitions are stored compacted.
+func (c *Config) ToBinary() ([]byte, error) {
+	compact := *c
+	compact.Models = make(map[string]json.RawMessage, len(c.Models))
+	for name, raw := range c.Models {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, raw); err != nil {
+			return nil, fmt.Errorf("model %q: %w", name, err)
+		}
+		compact.Models[name] = buf.Bytes()
+	}
+	data, err := cborEnc.Marshal(&compact)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), cborMagic...), data...), nil
+}
+
+// FromBinary deserializes a config from its binary form. Configs saved with
+// an older schema are migrated through the JSON form, as when loading JSON.
+func FromBinary(data []byte) (*Config, error) {
+	var c Config
+	if err := cborDec.Unmarshal(bytes.TrimPrefix(data, cborMagic), &c); err != nil {
+		return nil, fmt.Errorf("binary config: %w", err)
+	}
+	if c.SchemaVersion < CurrentSchemaVersion {
+		data, err := json.Marshal(&c)
+		if err != nil {
+			return nil, err
+		}
+		return decodeConfig(data, nil)
+	}
+	if c.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("binary config has schema version %d, newer than %d", c.SchemaVersion, CurrentSchemaVersion)
+	}
+	if c.Models == nil {
+		c.Models = make(map[string]json.RawMessage)
+	}
+	return &c, nil
+}
+
+// SaveToBinary saves the config to a binary file, atomically and under the
+// same advisory lock as SaveToFile. Binary files load several times faster
+// than JSON, for agents starting on slow hardware; JSON remains the form to
+// edit and review.
+func (c *Config) SaveToBinary(path string) error {
+	data, err := c.ToBinary()
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, data, 0644, false)
+	})
+}
+
+// LoadFromBinary loads a config from a binary file, holding a shared
+// advisory lock while reading. Like LoadFromFile, it applies DRIFT_
+// environment overrides.
+func LoadFromBinary(path string) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := FromBinary(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := c.ApplyEnvOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,98 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	cfg, err := LoadFromFile("tests/test01/drift_config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := cfg.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(data, cborMagic) {
+		t.Errorf("binary config starts with % x, want the CBOR tag % x", data[:3], cborMagic)
+	}
+	back, err := FromBinary(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := back.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := cfg.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("JSON → binary → JSON changed the config:\n%s\nwant:\n%s", got, want)
+	}
+	again, err := back.ToBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Error("re-encoding the decoded config gave different bytes")
+	}
+}
+
+// largeConfig builds a config of n models, each a stack of dense layers,
+// chained by links.
+func largeConfig(n int) *Config {
+	c := NewConfig("large")
+	for i := range n {
+		layers := make([]string, 16)
+		for j := range layers {
+			layers[j] = `{"type": "dense", "input_size": 64, "output_size": 64, "activation": "relu"}`
+		}
+		c.Models[fmt.Sprintf("m%03d", i)] = json.RawMessage(fmt.Sprintf(
+			`{"batch_size": 1, "grid_rows": 1, "grid_cols": 1, "layers_per_cell": %d, "layers": [%s]}`,
+			len(layers), strings.Join(layers, ",\n")))
+		if i > 0 {
+			c.AddLink(NeuralLinkConfig{
+				Name:         fmt.Sprintf("l%03d", i),
+				SourceModel:  fmt.Sprintf("m%03d", i-1),
+				SourceLayer:  8,
+				TargetModel:  fmt.Sprintf("m%03d", i),
+				TargetOffset: 32,
+				LinkSize:     32,
+				Enabled:      true,
+				Tags:         []string{"bench"},
+			})
+		}
+	}
+	return c
+}
+
+func BenchmarkLoadFromBinary(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "large.cbor")
+	if err := largeConfig(200).SaveToBinary(path); err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := LoadFromBinary(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadFromFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "large.json")
+	if err := largeConfig(200).SaveToFile(path); err != nil {
+		b.Fatal(err)
+	}
+	for b.Loop() {
+		if _, err := LoadFromFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// maxSnippet bounds the JSON quoted in a BuildError.
+const maxSnippet = 200
+
+// loomLayerErr matches loom's per-layer build errors.
+var loomLayerErr = regexp.MustCompile(`failed to build layer (\d+)`)
+
+// BuildError reports a model loom failed to build, with the layer at fault
+// when loom names one.
+type BuildError struct {
+	Model   string `json:"model"`
+	Layer   int    `json:"layer"`   // -1 when the failure isn't tied to a layer
+	Snippet string `json:"snippet"` // Compact JSON of the layer, or of the model
+	Err     error  `json:"-"`
+}
+
+func (e *BuildError) Error() string {
+	where := fmt.Sprintf("model %q", e.Model)
+	if e.Layer >= 0 {
+		where += fmt.Sprintf(" layer %d", e.Layer)
+	}
+	return fmt.Sprintf("%s: %v (in %s)", where, e.Err, e.Snippet)
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// BuildErrors collects the failures found by TryBuildAll.
+type BuildErrors []*BuildError
+
+func (es BuildErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "build failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As.
+func (es BuildErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+// TryBuildAll builds every model with loom and reports all failures at once,
+// as BuildErrors, rather than stopping at the first the way NewRuntime does.
+// The networks are discarded.
+func (c *Config) TryBuildAll() error {
+	var errs BuildErrors
+	for _, name := range sortedKeys(c.Models) {
+		if _, err := buildModel(name, c.Models[name]); err != nil {
+			errs = append(errs, err.(*BuildError))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// buildModel builds a model's network, turning loom errors and panics into
+// a *BuildError.
+func buildModel(name string, raw json.RawMessage) (net *nn.Network, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			net, err = nil, newBuildError(name, raw, fmt.Errorf("loom panicked: %v", p))
+		}
+	}()
+	net, err = nn.BuildNetworkFromJSON(string(raw))
+	if err != nil {
+		return nil, newBuildError(name, raw, err)
+	}
+	return net, nil
+}
+
+func newBuildError(name string, raw json.RawMessage, err error) *BuildError {
+	e := &BuildError{Model: name, Layer: -1, Err: err}
+	snippet := []byte(raw)
+	if m := loomLayerErr.FindStringSubmatch(err.Error()); m != nil {
+		e.Layer, _ = strconv.Atoi(m[1])
+		var spec struct {
+			Layers []json.RawMessage `json:"layers"`
+		}
+		if json.Unmarshal(raw, &spec) == nil && e.Layer < len(spec.Layers) {
+			snippet = spec.Layers[e.Layer]
+		}
+	}
+	e.Snippet = compactSnippet(snippet)
+	return e
+}
+
+// compactSnippet renders JSON on one line, truncated to maxSnippet bytes.
+func compactSnippet(data []byte) string {
+	var buf bytes.Buffer
+	if json.Compact(&buf, data) != nil {
+		buf.Reset()
+		buf.Write(data)
+	}
+	s := buf.String()
+	if len(s) > maxSnippet {
+		s = s[:maxSnippet] + "..."
+	}
+	return s
+}
@@ -0,0 +1,160 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Builder constructs a Config in Go code instead of JSON:
+//
+//	cfg, err := drift.NewBuilder("swarm").
+//		Model("classifier", classifierSpec).
+//		Model("navigator", navigatorSpec).
+//		Link().From("classifier", 1).To("navigator", 4).Size(16).
+//		Build()
+//
+// Errors are collected along the way and reported by Build, which also
+// validates the result.
+type Builder struct {
+	cfg   *Config
+	links []*NeuralLinkConfig
+	err   error
+}
+
+// NewBuilder starts a config with the given name.
+func NewBuilder(name string) *Builder {
+	return &Builder{cfg: NewConfig(name)}
+}
+
+// Seed sets the config's weight seed.
+func (b *Builder) Seed(seed int64) *Builder {
+	b.cfg.Seed = seed
+	return b
+}
+
+// DType sets the config's numeric type.
+func (b *Builder) DType(t DType) *Builder {
+	b.cfg.DType = t
+	return b
+}
+
+// Model adds a model. spec is a loom model definition, either as JSON
+// (json.RawMessage, []byte or string) or as any value that marshals to it.
+func (b *Builder) Model(name string, spec any) *Builder {
+	if _, dup := b.cfg.Models[name]; dup {
+		b.fail(fmt.Errorf("model %q added twice", name))
+		return b
+	}
+	var raw []byte
+	switch s := spec.(type) {
+	case json.RawMessage:
+		raw = s
+	case []byte:
+		raw = s
+	case string:
+		raw = []byte(s)
+	default:
+		if err := b.cfg.AddModel(name, spec); err != nil {
+			b.fail(fmt.Errorf("model %q: %w", name, err))
+		}
+		return b
+	}
+	if !json.Valid(raw) {
+		b.fail(fmt.Errorf("model %q: invalid JSON", name))
+		return b
+	}
+	b.cfg.Models[name] = append(json.RawMessage(nil), raw...)
+	return b
+}
+
+// Link starts an enabled link, configured through the returned LinkBuilder.
+// Unless named, the link is called "<source>_to_<target>".
+func (b *Builder) Link() *LinkBuilder {
+	l := &NeuralLinkConfig{Enabled: true}
+	b.links = append(b.links, l)
+	return &LinkBuilder{Builder: b, link: l}
+}
+
+// Build returns the finished config, or the first error met while building
+// it, or its ValidationErrors. The builder may be built again.
+func (b *Builder) Build() (*Config, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	c := b.cfg.Clone()
+	for _, l := range b.links {
+		link := *l
+		link.Tags = append([]string(nil), l.Tags...)
+		if link.Name == "" {
+			link.Name = link.SourceModel + "_to_" + link.TargetModel
+		}
+		if err := c.AddLink(link); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// LinkBuilder configures a link started by Builder.Link. The Builder's
+// methods remain available to carry on with the config.
+type LinkBuilder struct {
+	*Builder
+	link *NeuralLinkConfig
+}
+
+// Name names the link.
+func (lb *LinkBuilder) Name(name string) *LinkBuilder {
+	lb.link.Name = name
+	return lb
+}
+
+// From sets the source model and the layer whose activations are sent.
+func (lb *LinkBuilder) From(model string, layer int) *LinkBuilder {
+	lb.link.SourceModel, lb.link.SourceLayer = model, layer
+	return lb
+}
+
+// To sets the target model and the input offset the payload lands at.
+func (lb *LinkBuilder) To(model string, offset int) *LinkBuilder {
+	lb.link.TargetModel, lb.link.TargetOffset = model, offset
+	return lb
+}
+
+// Size sets the number of values transferred.
+func (lb *LinkBuilder) Size(n int) *LinkBuilder {
+	lb.link.LinkSize = n
+	return lb
+}
+
+// Disabled makes the link start disabled.
+func (lb *LinkBuilder) Disabled() *LinkBuilder {
+	lb.link.Enabled = false
+	return lb
+}
+
+// Describe sets the link's description.
+func (lb *LinkBuilder) Describe(desc string) *LinkBuilder {
+	lb.link.Description = desc
+	return lb
+}
+
+// Group puts the link in a group.
+func (lb *LinkBuilder) Group(group string) *LinkBuilder {
+	lb.link.Group = group
+	return lb
+}
+
+// Tags adds tags to the link.
+func (lb *LinkBuilder) Tags(tags ...string) *LinkBuilder {
+	lb.link.Tags = append(lb.link.Tags, tags...)
+	return lb
+}
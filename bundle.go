@@ -0,0 +1,208 @@
+package drift
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// BundleExt is the conventional extension of bundles.
+const BundleExt = ".driftbundle"
+
+// BundleFormat is the manifest format version written by SaveBundle.
+const BundleFormat = 1
+
+// Well-known entries of a bundle. Each model's weights are stored as
+// models/<name>.json, a loom saved model.
+const (
+	bundleManifest = "manifest.json"
+	bundleConfig   = "config.json"
+	bundleModels   = "models/"
+)
+
+// BundleManifest is the table of contents of a bundle, stored as its
+// manifest.json.
+type BundleManifest struct {
+	Format     int            `json:"format"`
+	Name       string         `json:"name"`
+	ConfigHash string         `json:"config_hash"` // See Config.Hash
+	Created    time.Time      `json:"created"`
+	Steps      uint64         `json:"steps,omitempty"` // Steps the runtime had taken when bundled
+	Drift      string         `json:"drift,omitempty"` // Module versions of the writer, when known
+	Loom       string         `json:"loom,omitempty"`
+	Models     []string       `json:"models"`
+	Files      []ArchiveEntry `json:"files"`
+}
+
+// SaveBundle writes the runtime's config and the trained weights of every
+// model to a single zip file at path, with a manifest of SHA-256 hashes, so a
+// trained system travels as one artifact that LoadBundle restores.
+func (r *Runtime) SaveBundle(path string) error {
+	m := BundleManifest{
+		Format:  BundleFormat,
+		Name:    r.cfg.Name,
+		Created: time.Now().UTC(),
+		Steps:   r.Steps(),
+		Models:  sortedKeys(r.cfg.Models),
+	}
+	saved, err := r.saveModels(m.Models)
+	if err != nil {
+		return err
+	}
+	m.Drift, m.Loom = moduleVersions()
+	hash, err := r.cfg.Hash()
+	if err != nil {
+		return err
+	}
+	m.ConfigHash = hash
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		m.Files = append(m.Files, ArchiveEntry{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		return nil
+	}
+	data, err := r.cfg.ToJSON()
+	if err != nil {
+		return err
+	}
+	if err := add(bundleConfig, []byte(data)); err != nil {
+		return err
+	}
+	for i, name := range m.Models {
+		data, err := json.Marshal(saved[i])
+		if err != nil {
+			return fmt.Errorf("model %q: %w", name, err)
+		}
+		if err := add(bundleModels+name+".json", data); err != nil {
+			return err
+		}
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add(bundleManifest, manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644, false)
+}
+
+// LoadBundle builds a runtime from the bundle at path, with the bundled
+// weights restored. Every file is checked against the manifest's hashes, the
+// config against the manifest's config hash, and each network against its
+// model's definition, so a damaged or mismatched bundle fails to load rather
+// than running with the wrong weights.
+func LoadBundle(path string) (*Runtime, error) {
+	r, err := loadBundle(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return r, nil
+}
+
+func loadBundle(path string) (*Runtime, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	read := func(name string) ([]byte, error) {
+		f, ok := files[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: not in bundle", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	data, err := read(bundleManifest)
+	if err != nil {
+		return nil, err
+	}
+	var m BundleManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("manifest: %w", err)
+	}
+	if m.Format > BundleFormat {
+		return nil, fmt.Errorf("bundle format %d is newer than supported", m.Format)
+	}
+	contents := make(map[string][]byte, len(m.Files))
+	for _, e := range m.Files {
+		if e.Name == bundleManifest {
+			continue
+		}
+		data, err := read(e.Name)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != e.SHA256 {
+			return nil, fmt.Errorf("%s: checksum mismatch", e.Name)
+		}
+		contents[e.Name] = data
+	}
+
+	data, ok := contents[bundleConfig]
+	if !ok {
+		return nil, fmt.Errorf("%s: not in bundle", bundleConfig)
+	}
+	cfg, err := FromJSON(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", bundleConfig, err)
+	}
+	if hash, err := cfg.Hash(); err != nil {
+		return nil, err
+	} else if hash != m.ConfigHash {
+		return nil, fmt.Errorf("%s: config hash %s does not match the manifest's %s", bundleConfig, hash, m.ConfigHash)
+	}
+	bundle := &nn.ModelBundle{Type: "modelhost/bundle", Version: loomBundleVersion}
+	for _, name := range sortedKeys(cfg.Models) {
+		file := bundleModels + name + ".json"
+		data, ok := contents[file]
+		if !ok {
+			return nil, fmt.Errorf("model %q: no weights in bundle", name)
+		}
+		var saved nn.SavedModel
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		saved.ID = name
+		bundle.Models = append(bundle.Models, saved)
+	}
+
+	r, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.restoreBundle(bundle); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
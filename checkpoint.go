@@ -0,0 +1,304 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// CheckpointVersion is the checkpoint format SaveCheckpoint writes. Version 0
+// is a bare loom model bundle, as written before checkpoints were versioned;
+// older versions are migrated when loaded.
+const CheckpointVersion = 1
+
+// loomBundleVersion is the newest loom bundle version this build reads.
+const loomBundleVersion = 1
+
+const checkpointType = "drift/checkpoint"
+
+// ErrCheckpointTooNew is returned for checkpoints written by a newer version
+// of the package or of loom.
+var ErrCheckpointTooNew = errors.New("drift: checkpoint format is newer than supported")
+
+// checkpointFile is the on-disk form of a checkpoint.
+type checkpointFile struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version"`
+	Created time.Time      `json:"created"`
+	Steps   uint64         `json:"steps"`
+	Config  string         `json:"config_hash,omitempty"` // Hash of the runtime's config
+	Drift   string         `json:"drift,omitempty"`       // Module versions of the writer, when known
+	Loom    string         `json:"loom,omitempty"`
+	Bundle  nn.ModelBundle `json:"bundle"`
+}
+
+// CheckpointMigration upgrades a decoded checkpoint document by one format
+// version, in place.
+type CheckpointMigration func(doc map[string]any) error
+
+// checkpointMigrations holds the registered migrations keyed by the version
+// they upgrade from.
+var checkpointMigrations = struct {
+	sync.RWMutex
+	m map[int]CheckpointMigration
+}{m: map[int]CheckpointMigration{
+	// Version 0 is a bare loom bundle; wrap it.
+	0: func(doc map[string]any) error {
+		bundle := make(map[string]any, len(doc))
+		for k, v := range doc {
+			bundle[k] = v
+			delete(doc, k)
+		}
+		doc["type"] = checkpointType
+		doc["bundle"] = bundle
+		return nil
+	},
+}}
+
+// RegisterCheckpointMigration registers the migration from checkpoint format
+// from to from+1, replacing any earlier one.
+func RegisterCheckpointMigration(from int, m CheckpointMigration) {
+	checkpointMigrations.Lock()
+	defer checkpointMigrations.Unlock()
+	checkpointMigrations.m[from] = m
+}
+
+// SaveCheckpoint writes the weights of every model to a checkpoint at path.
+func (r *Runtime) SaveCheckpoint(path string) error {
+	data, err := r.checkpoint()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644, false)
+}
+
+// LoadCheckpoint replaces the networks of the models found in the checkpoint
+// at path. Models absent from the checkpoint keep their current weights; a
+// model whose architecture differs from the runtime's fails the load with an
+// explanation of the differences, and nothing is replaced.
+func (r *Runtime) LoadCheckpoint(path string) error {
+	bundle, err := readCheckpoint(path)
+	if err != nil {
+		return err
+	}
+	return r.restoreBundle(bundle)
+}
+
+// checkpoint encodes the weights of every model, with models in name order
+// so that equal weights give equal bytes.
+func (r *Runtime) checkpoint() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cp := checkpointFile{
+		Type:    checkpointType,
+		Version: CheckpointVersion,
+		Created: time.Now().UTC(),
+		Steps:   r.steps,
+		Bundle:  nn.ModelBundle{Type: "modelhost/bundle", Version: loomBundleVersion},
+	}
+	cp.Drift, cp.Loom = moduleVersions()
+	cp.Config, _ = r.cfg.Hash()
+	names := make([]string, 0, len(r.models))
+	for name := range r.models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		saved, err := r.models[name].net.SerializeModel(name)
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", name, err)
+		}
+		cp.Bundle.Models = append(cp.Bundle.Models, saved)
+	}
+	return json.MarshalIndent(cp, "", "  ")
+}
+
+// readCheckpoint reads the checkpoint at path.
+func readCheckpoint(path string) (*nn.ModelBundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := decodeCheckpoint(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return bundle, nil
+}
+
+// decodeCheckpoint decodes a checkpoint of any known format, migrating it to
+// CheckpointVersion, and returns its model bundle.
+func decodeCheckpoint(data []byte) (*nn.ModelBundle, error) {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	version := 0
+	if doc["type"] == checkpointType {
+		v, _ := doc["version"].(float64)
+		version = int(v)
+	}
+	if version > CheckpointVersion {
+		return nil, fmt.Errorf("checkpoint format %d%s: %w", version, writtenBy(doc), ErrCheckpointTooNew)
+	}
+	if version < CheckpointVersion {
+		if err := migrateCheckpoint(doc, version); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+
+	var cp checkpointFile
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, err
+	}
+	if cp.Bundle.Type != "modelhost/bundle" {
+		return nil, fmt.Errorf("not a loom model bundle (type %q)", cp.Bundle.Type)
+	}
+	if cp.Bundle.Version > loomBundleVersion {
+		return nil, fmt.Errorf("loom bundle version %d%s, this build reads up to %d: %w",
+			cp.Bundle.Version, writtenBy(doc), loomBundleVersion, ErrCheckpointTooNew)
+	}
+	return &cp.Bundle, nil
+}
+
+// migrateCheckpoint upgrades doc from format version to CheckpointVersion.
+func migrateCheckpoint(doc map[string]any, version int) error {
+	checkpointMigrations.RLock()
+	defer checkpointMigrations.RUnlock()
+	for ; version < CheckpointVersion; version++ {
+		m, ok := checkpointMigrations.m[version]
+		if !ok {
+			return fmt.Errorf("no migration from checkpoint format %d", version)
+		}
+		if err := m(doc); err != nil {
+			return fmt.Errorf("migrating checkpoint format %d: %w", version, err)
+		}
+	}
+	doc["version"] = CheckpointVersion
+	return nil
+}
+
+// restoreBundle replaces the networks of the models found in bundle, after
+// checking that each matches the architecture of the model it replaces.
+func (r *Runtime) restoreBundle(bundle *nn.ModelBundle) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	nets := make(map[string]*nn.Network, len(bundle.Models))
+	for _, saved := range bundle.Models {
+		m, ok := r.models[saved.ID]
+		if !ok {
+			continue
+		}
+		current, err := m.net.SerializeModel(saved.ID)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", saved.ID, err)
+		}
+		if diffs := architectureDiff(current.Config, saved.Config); len(diffs) > 0 {
+			return fmt.Errorf("model %q: checkpoint architecture differs: %s", saved.ID, strings.Join(diffs, "; "))
+		}
+		net, err := nn.DeserializeModel(saved)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", saved.ID, err)
+		}
+		nets[saved.ID] = net
+	}
+	for name, net := range nets {
+		m := r.models[name]
+		m.net = net
+		m.state = net.InitStepState(len(m.input))
+	}
+	return nil
+}
+
+// architectureDiff describes how the saved network differs from the current
+// one, layer by layer and field by field.
+func architectureDiff(current, saved nn.NetworkConfig) []string {
+	var diffs []string
+	if a, b := current.GridRows*current.GridCols*current.LayersPerCell, saved.GridRows*saved.GridCols*saved.LayersPerCell; a != b {
+		diffs = append(diffs, fmt.Sprintf("%d layers in checkpoint, %d in config", b, a))
+	}
+	for i := 0; i < len(current.Layers) && i < len(saved.Layers); i++ {
+		var cur, old map[string]any
+		if raw, err := json.Marshal(current.Layers[i]); err == nil {
+			json.Unmarshal(raw, &cur)
+		}
+		if raw, err := json.Marshal(saved.Layers[i]); err == nil {
+			json.Unmarshal(raw, &old)
+		}
+		keys := make(map[string]bool)
+		for k := range cur {
+			keys[k] = true
+		}
+		for k := range old {
+			keys[k] = true
+		}
+		for _, k := range sortedKeys(keys) {
+			if !reflect.DeepEqual(cur[k], old[k]) {
+				diffs = append(diffs, fmt.Sprintf("layer %d %s: %v in checkpoint, %v in config", i, k, layerValue(old[k]), layerValue(cur[k])))
+			}
+		}
+	}
+	return diffs
+}
+
+// layerValue formats a decoded layer field for architectureDiff.
+func layerValue(v any) string {
+	if v == nil {
+		return "unset"
+	}
+	if _, ok := v.(map[string]any); ok {
+		return "{...}"
+	}
+	if _, ok := v.([]any); ok {
+		return "[...]"
+	}
+	return fmt.Sprint(v)
+}
+
+// writtenBy describes the writer recorded in a checkpoint document, if any.
+func writtenBy(doc map[string]any) string {
+	d, _ := doc["drift"].(string)
+	l, _ := doc["loom"].(string)
+	if d == "" && l == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (written by drift %s, loom %s)", orUnknown(d), orUnknown(l))
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
+
+// moduleVersions returns the versions of drift and loom in the running
+// binary, empty when unknown.
+func moduleVersions() (drift, loom string) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	for _, m := range append(info.Deps, &info.Main) {
+		switch m.Path {
+		case "github.com/openfluke/drift":
+			drift = m.Version
+		case "github.com/openfluke/loom":
+			loom = m.Version
+		}
+	}
+	return drift, loom
+}
@@ -0,0 +1,93 @@
+package drift
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// ErrCorruptFrame is returned when a link frame fails its checksum.
+var ErrCorruptFrame = errors.New("drift: corrupt link frame")
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// payloadChecksum returns the CRC-32C of a payload's little-endian float
+// bits, so it is the same on every platform.
+func payloadChecksum(payload []float32) uint32 {
+	var b [4]byte
+	var sum uint32
+	for _, v := range payload {
+		binary.LittleEndian.PutUint32(b[:], math.Float32bits(v))
+		sum = crc32.Update(sum, castagnoli, b[:])
+	}
+	return sum
+}
+
+// LinkFrame is a link payload in transit, for transports carrying links
+// between runtimes, e.g. across the network or through shared memory. The
+// source runtime produces frames with Frame and the target consumes them
+// with DeliverFrame, which rejects frames damaged on the way.
+type LinkFrame struct {
+	Link     string    `json:"link"`
+	Step     uint64    `json:"step"` // Source step that produced the payload
+	Payload  []float32 `json:"payload"`
+	Checksum uint32    `json:"checksum"` // CRC-32C of the payload; see Verify
+}
+
+// Verify reports whether the payload matches the frame's checksum.
+func (f LinkFrame) Verify() bool {
+	return payloadChecksum(f.Payload) == f.Checksum
+}
+
+// Frame returns a checksummed frame of the link's latest payload, or false
+// when the link doesn't exist or has carried nothing yet.
+func (r *Runtime) Frame(link string) (LinkFrame, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.link(link)
+	if l == nil || l.payload == nil {
+		return LinkFrame{}, false
+	}
+	p := append([]float32(nil), l.payload...)
+	return LinkFrame{Link: l.cfg.Name, Step: r.steps, Payload: p, Checksum: payloadChecksum(p)}, true
+}
+
+// DeliverFrame verifies a frame from a transport and queues its payload for
+// the next step, like InjectPayload. A frame failing its checksum is
+// dropped and counted in the link's Corrupted counter, and DeliverFrame
+// returns ErrCorruptFrame.
+func (r *Runtime) DeliverFrame(f LinkFrame) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.link(f.Link)
+	if l == nil {
+		return fmt.Errorf("link %q not found", f.Link)
+	}
+	if !f.Verify() {
+		l.corrupted++
+		return fmt.Errorf("link %q, step %d: %w", f.Link, f.Step, ErrCorruptFrame)
+	}
+	l.injected = make([]float32, l.cfg.LinkSize)
+	copy(l.injected, f.Payload)
+	return nil
+}
+
+// seal checksums the payload just captured, for links with
+// NeuralLinkConfig.Checksum.
+func (l *runtimeLink) seal() {
+	if l.cfg.Checksum {
+		l.sum = payloadChecksum(l.payload)
+	}
+}
+
+// intact verifies the payload against the checksum taken at capture,
+// counting a mismatch. Links without checksums are always intact.
+func (l *runtimeLink) intact() bool {
+	if !l.cfg.Checksum || payloadChecksum(l.payload) == l.sum {
+		return true
+	}
+	l.corrupted++
+	return false
+}
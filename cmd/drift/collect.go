@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/openfluke/drift"
+)
+
+// collectMain serves a fleet telemetry collector and checkpoint store.
+// DRIFT_FLEET_TOKEN, when set, is required as a bearer token on requests to
+// either.
+func collectMain(args []string) error {
+	if len(args) > 2 {
+		return errors.New("usage: drift collect [addr [checkpoint-dir]]")
+	}
+	addr, dir := ":7070", ""
+	if len(args) > 0 {
+		addr = args[0]
+	}
+	if len(args) > 1 {
+		dir = args[1]
+	}
+	token := os.Getenv("DRIFT_FLEET_TOKEN")
+	c := drift.NewFleetCollector()
+	c.Token = token
+	s, err := drift.NewCheckpointServer(dir)
+	if err != nil {
+		return err
+	}
+	s.Token = token
+
+	mux := http.NewServeMux()
+	for _, path := range []string{"/telemetry", "/fleet", "/fleet/"} {
+		mux.Handle(path, c)
+	}
+	mux.Handle("/checkpoints", s)
+	mux.Handle("/checkpoints/", s)
+	mux.Handle("/federated/", s)
+	fmt.Printf("collecting telemetry and checkpoints on %s\n", addr)
+	return http.ListenAndServe(addr, mux)
+}
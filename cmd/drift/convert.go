@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/openfluke/drift"
+)
+
+func convertMain(args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: drift convert <in> <out>")
+	}
+	in, out := args[0], args[1]
+	start := time.Now()
+	cfg, err := loadAny(in)
+	if err != nil {
+		return err
+	}
+	inTime := time.Since(start)
+	if err := saveAny(cfg, out); err != nil {
+		return err
+	}
+	// Reload the result, to check it and to compare load times.
+	start = time.Now()
+	if _, err := loadAny(out); err != nil {
+		return fmt.Errorf("reloading %s: %w", out, err)
+	}
+	outTime := time.Since(start)
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "file\tformat\tsize\tload time")
+	for _, f := range []struct {
+		path string
+		load time.Duration
+	}{{in, inTime}, {out, outTime}} {
+		st, err := os.Stat(f.path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%v\n", f.path, drift.EncodingFor(f.path), st.Size(), f.load.Round(time.Microsecond))
+	}
+	return tw.Flush()
+}
+
+// loadAny loads a config in the format its extension implies.
+func loadAny(path string) (*drift.Config, error) {
+	switch drift.EncodingFor(path) {
+	case drift.EncodingYAML:
+		return drift.LoadFromYAML(path)
+	case drift.EncodingTOML:
+		return drift.LoadFromTOML(path)
+	case drift.EncodingCBOR:
+		return drift.LoadFromBinary(path)
+	case drift.EncodingProto:
+		return drift.LoadFromProto(path)
+	}
+	return drift.LoadFromFile(path)
+}
+
+// saveAny saves a config in the format its extension implies.
+func saveAny(cfg *drift.Config, path string) error {
+	switch drift.EncodingFor(path) {
+	case drift.EncodingYAML:
+		return cfg.SaveToYAML(path)
+	case drift.EncodingTOML:
+		return cfg.SaveToTOML(path)
+	case drift.EncodingCBOR:
+		return cfg.SaveToBinary(path)
+	case drift.EncodingProto:
+		return cfg.SaveToProto(path)
+	}
+	return cfg.SaveToFile(path)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openfluke/drift"
+)
+
+func inspectMain(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: drift inspect <run.driftrun> [file]")
+	}
+	a, err := drift.OpenRunArchive(args[0])
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	// With a file name, print that file instead of the summary.
+	if len(args) == 2 {
+		data, err := a.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+
+	m := a.Manifest
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "run\t%s\n", m.Name)
+	fmt.Fprintf(tw, "config hash\t%s\n", m.ConfigHash)
+	fmt.Fprintf(tw, "created\t%s\n", m.Created.Format("2006-01-02 15:04:05 MST"))
+	if m.Steps > 0 {
+		fmt.Fprintf(tw, "steps\t%d\n", m.Steps)
+	}
+	fmt.Fprintf(tw, "models\t%s\n", strings.Join(m.Models, ", "))
+	fmt.Fprintf(tw, "links\t%s\n", strings.Join(m.Links, ", "))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Println("\nfiles:")
+	for _, f := range m.Files {
+		fmt.Fprintf(tw, "  %s\t%d bytes\t%.12s\n", f.Name, f.Size, f.SHA256)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var report drift.ExperimentReport
+	if a.Results(&report) == nil && len(report.Phases) > 0 {
+		fmt.Println("\nresults:")
+		for _, p := range report.Phases {
+			fmt.Fprintf(tw, "  %s\t%s\t%.1fs\n", p.Name, p.Kind, p.Elapsed)
+			for _, res := range p.Results {
+				fmt.Fprintf(tw, "    %s\t%d targets\t%.1f%%\n", res.Mode, res.TotalTargets, res.FinalAccuracy)
+			}
+			if len(p.Pareto) > 0 {
+				fmt.Fprintf(tw, "    pareto front\t%s\n", strings.Join(p.Pareto, ", "))
+			}
+		}
+		return tw.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/openfluke/drift"
+)
+
+func lintMain(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: drift lint <config.json>")
+	}
+	cfg, err := drift.LoadFromFile(args[0])
+	if err != nil {
+		return err
+	}
+	warnings := 0
+	for _, d := range cfg.Lint() {
+		fmt.Printf("%s: %s\n", args[0], d)
+		if d.Severity >= drift.SeverityWarning {
+			warnings++
+		}
+	}
+	if warnings > 0 {
+		return fmt.Errorf("%d warnings", warnings)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command drift is the command-line front end for DRIFT configs.
+//
+// Usage:
+//
+//	drift repl <config.json>       drive a live runtime interactively
+//	drift run <experiment.json>    run a composite experiment
+//	drift inspect <run.driftrun>   summarize a run archive, or print one of its files
+//	drift lint <config.json>       report likely mistakes; fails on warnings
+//	drift convert <in> <out>       convert between JSON, YAML, TOML, binary (.cbor) and protobuf (.pb)
+//	drift collect [addr [dir]]     serve a fleet telemetry collector and checkpoint store
+//	drift numerics record|verify <config.json> <trace.json>
+//	                               check numerics against a reference platform
+//
+// Experiments refer to environments registered with drift.RegisterEnvironment;
+// programs that define environments link them in and call the same runner.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: drift <command> [arguments]
+
+commands:
+  repl <config.json>       drive a live runtime interactively
+  run <experiment.json>    run a composite experiment
+  inspect <run.driftrun> [file]
+                           summarize a run archive, or print one of its files
+  lint <config.json>       report likely mistakes; fails on warnings
+  convert <in> <out>       convert between JSON, YAML, TOML, binary (.cbor) and
+                           protobuf (.pb), comparing load times
+  collect [addr [checkpoint-dir]]
+                           serve a fleet telemetry collector and checkpoint
+                           store (default :7070, checkpoints in memory)
+  numerics record <config.json> <trace.json> [steps]
+                           record a reference trace of a seeded config
+  numerics verify <config.json> <trace.json> [tolerance]
+                           compare this platform's outputs against a trace`)
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "repl":
+		err = replMain(os.Args[2:])
+	case "run":
+		err = runMain(os.Args[2:])
+	case "inspect":
+		err = inspectMain(os.Args[2:])
+	case "lint":
+		err = lintMain(os.Args[2:])
+	case "convert":
+		err = convertMain(os.Args[2:])
+	case "numerics":
+		err = numericsMain(os.Args[2:])
+	case "collect":
+		err = collectMain(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "drift:", err)
+		os.Exit(1)
+	}
+}
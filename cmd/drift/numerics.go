@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/openfluke/drift"
+)
+
+const numericsUsage = `usage: drift numerics record <config.json> <trace.json> [steps]
+       drift numerics verify <config.json> <trace.json> [tolerance]`
+
+func numericsMain(args []string) error {
+	if len(args) < 3 || len(args) > 4 {
+		return errors.New(numericsUsage)
+	}
+	cfg, err := drift.LoadFromFile(args[1])
+	if err != nil {
+		return err
+	}
+	switch args[0] {
+	case "record":
+		steps := 100
+		if len(args) == 4 {
+			if steps, err = strconv.Atoi(args[3]); err != nil {
+				return fmt.Errorf("steps: %w", err)
+			}
+		}
+		t, err := drift.RecordNumerics(cfg, steps, nil)
+		if err != nil {
+			return err
+		}
+		if err := t.Save(args[2]); err != nil {
+			return err
+		}
+		fmt.Printf("recorded %d steps on %s to %s\n", steps, t.Platform, args[2])
+		return nil
+	case "verify":
+		tol := 1e-5
+		if len(args) == 4 {
+			if tol, err = strconv.ParseFloat(args[3], 64); err != nil {
+				return fmt.Errorf("tolerance: %w", err)
+			}
+		}
+		t, err := drift.LoadNumericsTrace(args[2])
+		if err != nil {
+			return err
+		}
+		rep, err := drift.VerifyNumerics(cfg, t, tol)
+		if err != nil {
+			return err
+		}
+		fmt.Print(rep)
+		if !rep.OK() {
+			return errors.New("outputs diverged from the reference")
+		}
+		return nil
+	}
+	return errors.New(numericsUsage)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/openfluke/drift"
+)
+
+const replHelp = `commands:
+  models                      list models and their input/output sizes
+  links                       list links with state, gain, noise and transfers
+  enable <link|group>         enable a link, or every link in a group
+  disable <link|group>        disable a link, or every link in a group
+  gain <link> <g>             set a link's gain
+  noise <link> <stddev>       set a link's noise
+  inject <link> [v1 v2 ...]   deliver values over a link on the next step
+  input <model> [v1 v2 ...]   set a model's external input for later steps
+  step [n]                    run n steps (default 1)
+  stats [model]               runtime counters, or a model's output summary
+  save <path>                 write a checkpoint
+  help                        show this help
+  quit                        exit`
+
+func replMain(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: drift repl <config.json>")
+	}
+	cfg, err := drift.LoadFromFile(args[0])
+	if err != nil {
+		return err
+	}
+	r, err := drift.NewRuntime(cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("loaded %s: %d models, %d links. Type help for commands.\n", cfg.Name, len(cfg.Models), len(cfg.Links))
+	return newRepl(r, os.Stdout).run(os.Stdin)
+}
+
+type repl struct {
+	r      *drift.Runtime
+	out    io.Writer
+	inputs map[string][]float32
+}
+
+func newRepl(r *drift.Runtime, out io.Writer) *repl {
+	return &repl{r: r, out: out, inputs: make(map[string][]float32)}
+}
+
+// run reads commands from in until EOF or quit.
+func (s *repl) run(in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(s.out, "drift> ")
+		if !sc.Scan() {
+			fmt.Fprintln(s.out)
+			return sc.Err()
+		}
+		fields := strings.Fields(strings.NewReplacer("[", " ", "]", " ", ",", " ").Replace(sc.Text()))
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "quit" || fields[0] == "exit" {
+			return nil
+		}
+		if err := s.exec(fields[0], fields[1:]); err != nil {
+			fmt.Fprintln(s.out, "error:", err)
+		}
+	}
+}
+
+func (s *repl) exec(cmd string, args []string) error {
+	switch cmd {
+	case "help":
+		fmt.Fprintln(s.out, replHelp)
+	case "models":
+		tw := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "MODEL\tINPUT\tOUTPUT")
+		for _, name := range s.r.Models() {
+			fmt.Fprintf(tw, "%s\t%d\t%d\n", name, len(s.r.Input(name)), len(s.r.Output(name)))
+		}
+		tw.Flush()
+	case "links":
+		tw := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "LINK\tENABLED\tGAIN\tNOISE\tTRANSFERS")
+		for _, l := range s.r.Links() {
+			fmt.Fprintf(tw, "%s\t%v\t%g\t%g\t%d\n", l.Name, l.Enabled, l.Gain, l.Noise, l.Transfers)
+		}
+		tw.Flush()
+	case "enable", "disable":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: %s <link|group>", cmd)
+		}
+		enabled := cmd == "enable"
+		if err := s.r.SetLinkEnabled(args[0], enabled); err != nil {
+			if n := s.r.EnableGroup(args[0], enabled); n > 0 {
+				fmt.Fprintf(s.out, "%sd %d links in group %s\n", cmd, n, args[0])
+				return nil
+			}
+			return err
+		}
+	case "gain", "noise":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s <link> <value>", cmd)
+		}
+		v, err := strconv.ParseFloat(args[1], 32)
+		if err != nil {
+			return err
+		}
+		if cmd == "gain" {
+			return s.r.SetLinkGain(args[0], float32(v))
+		}
+		return s.r.SetLinkNoise(args[0], float32(v))
+	case "inject", "input":
+		if len(args) < 1 {
+			return fmt.Errorf("usage: %s <name> [v1 v2 ...]", cmd)
+		}
+		values, err := parseFloats(args[1:])
+		if err != nil {
+			return err
+		}
+		if cmd == "inject" {
+			return s.r.InjectPayload(args[0], values)
+		}
+		if s.r.Network(args[0]) == nil {
+			return fmt.Errorf("model %q not found", args[0])
+		}
+		s.inputs[args[0]] = values
+	case "step":
+		n := 1
+		if len(args) > 0 {
+			var err error
+			if n, err = strconv.Atoi(args[0]); err != nil {
+				return err
+			}
+		}
+		for i := 0; i < n; i++ {
+			if _, err := s.r.Step(s.inputs); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(s.out, "step %d\n", s.r.Steps())
+	case "stats":
+		if len(args) == 0 {
+			st := s.r.Stats()
+			fmt.Fprintf(s.out, "steps %d, uptime %s\n", st.Steps, st.Uptime.Round(1e6))
+			return nil
+		}
+		out := s.r.Output(args[0])
+		if out == nil {
+			return fmt.Errorf("model %q has no output yet", args[0])
+		}
+		printSummary(s.out, out)
+	case "save":
+		if len(args) != 1 {
+			return errors.New("usage: save <path>")
+		}
+		return s.r.SaveCheckpoint(args[0])
+	default:
+		return fmt.Errorf("unknown command %q (try help)", cmd)
+	}
+	return nil
+}
+
+func parseFloats(args []string) ([]float32, error) {
+	values := make([]float32, len(args))
+	for i, a := range args {
+		v, err := strconv.ParseFloat(a, 32)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = float32(v)
+	}
+	return values, nil
+}
+
+func printSummary(w io.Writer, v []float32) {
+	lo, hi, sum := math.Inf(1), math.Inf(-1), 0.0
+	for _, x := range v {
+		f := float64(x)
+		lo, hi, sum = math.Min(lo, f), math.Max(hi, f), sum+f
+	}
+	fmt.Fprintf(w, "size %d  mean %.4g  min %.4g  max %.4g  argmax %d\n", len(v), sum/float64(len(v)), lo, hi, drift.Argmax(v))
+	fmt.Fprintf(w, "%v\n", v)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/openfluke/drift"
+)
+
+func runMain(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: drift run <experiment.json>")
+	}
+	e, err := drift.LoadExperiment(args[0])
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := drift.RunExperiment(ctx, e, func(p drift.ExperimentPhase) {
+		fmt.Printf("=== %s: %s (%s)\n", p.Kind, p.Name, p.Environment)
+	})
+	if err != nil {
+		if errors.Is(err, drift.ErrUnknownEnvironment) {
+			fmt.Fprintf(os.Stderr, "registered environments: %v\n", drift.EnvironmentNames())
+		}
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	for _, p := range report.Phases {
+		fmt.Fprintf(tw, "%s\t%s\t%.1fs\n", p.Name, p.Kind, p.Elapsed)
+		for _, res := range p.Results {
+			fmt.Fprintf(tw, "  %s\t%d targets\t%.1f%%\n", res.Mode, res.TotalTargets, res.FinalAccuracy)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,122 @@
+package drift
+
+import (
+	"fmt"
+)
+
+// LinkCodec compresses a link's payload through a bottleneck: the encoder
+// maps LinkSize values to the code that crosses the channel and the decoder
+// reconstructs the payload the target receives.
+type LinkCodec struct {
+	Encoder *LinkProjection `json:"encoder"`
+	Decoder *LinkProjection `json:"decoder"`
+}
+
+// CodecOptions configures TrainLinkCodec.
+type CodecOptions struct {
+	Epochs int     // Defaults to 50
+	LR     float32 // SGD learning rate; defaults to 0.01
+	Seed   int64
+}
+
+// LinkSamples returns the payloads of link found in a recording, the usual
+// training data for TrainLinkCodec.
+func LinkSamples(steps []StepRecord, link string) [][]float32 {
+	var samples [][]float32
+	for _, s := range steps {
+		if p := s.Links[link]; len(p) > 0 {
+			samples = append(samples, p)
+		}
+	}
+	return samples
+}
+
+// TrainLinkCodec fits a linear autoencoder with a bottleneck of the given
+// size to samples, offline, and returns it with its final mean squared
+// reconstruction error.
+func TrainLinkCodec(samples [][]float32, bottleneck int, opts CodecOptions) (*LinkCodec, float64, error) {
+	if len(samples) == 0 {
+		return nil, 0, fmt.Errorf("codec: no samples")
+	}
+	size := len(samples[0])
+	if bottleneck <= 0 || bottleneck > size {
+		return nil, 0, fmt.Errorf("codec: bottleneck %d outside [1, %d]", bottleneck, size)
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = 50
+	}
+	if opts.LR <= 0 {
+		opts.LR = 0.01
+	}
+
+	rng := seededStream(opts.Seed, StreamTraining)
+	enc, dec := NewLinkProjection(size, bottleneck), NewLinkProjection(bottleneck, size)
+	for _, w := range [][]float32{enc.W, dec.W} {
+		for i := range w {
+			w[i] = (rng.Float32()*2 - 1) / float32(size)
+		}
+	}
+
+	code, out := make([]float32, bottleneck), make([]float32, size)
+	gradCode := make([]float32, bottleneck)
+	x := make([]float32, size)
+	var mse float64
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		mse = 0
+		for _, k := range perm(rng, len(samples)) {
+			copy(x, samples[k])
+			enc.Apply(x, code)
+			dec.Apply(code, out)
+			for j := range gradCode {
+				gradCode[j] = 0
+			}
+			for o := range out {
+				e := out[o] - x[o]
+				mse += float64(e * e)
+				row := dec.W[o*bottleneck : (o+1)*bottleneck]
+				for j := range row {
+					gradCode[j] += e * row[j]
+					row[j] -= opts.LR * e * code[j]
+				}
+				dec.B[o] -= opts.LR * e
+			}
+			for j, g := range gradCode {
+				row := enc.W[j*size : (j+1)*size]
+				for i := range row {
+					row[i] -= opts.LR * g * x[i]
+				}
+				enc.B[j] -= opts.LR * g
+			}
+		}
+		mse /= float64(len(samples) * size)
+	}
+	return &LinkCodec{Encoder: enc, Decoder: dec}, mse, nil
+}
+
+// SetLinkCodec installs c on a link, or removes the codec when c is nil.
+func (r *Runtime) SetLinkCodec(name string, c *LinkCodec) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l := r.link(name)
+	if l == nil {
+		return fmt.Errorf("link %q not found", name)
+	}
+	if c != nil && (c.Encoder.In != l.cfg.LinkSize || c.Decoder.Out != l.cfg.LinkSize || c.Encoder.Out != c.Decoder.In) {
+		return fmt.Errorf("link %q: codec shape %d→%d→%d doesn't fit link size %d",
+			name, c.Encoder.In, c.Encoder.Out, c.Decoder.Out, l.cfg.LinkSize)
+	}
+	l.codec = c
+	l.code = nil
+	return nil
+}
+
+// LinkCode returns the bottleneck values a codec link carried on the latest
+// step, or nil when the link has no codec.
+func (r *Runtime) LinkCode(name string) []float32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l := r.link(name); l != nil && l.codec != nil {
+		return append([]float32(nil), l.code...)
+	}
+	return nil
+}
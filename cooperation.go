@@ -0,0 +1,142 @@
+package drift
+
+import (
+	"math"
+	"sync"
+)
+
+// CooperationWindow summarizes the episodes of one window of a multi-agent
+// run.
+type CooperationWindow struct {
+	WindowNum    int                `json:"window"`
+	Episodes     int                `json:"episodes"`
+	JointSuccess float64            `json:"joint_success_pct"` // Episodes all agents completed together
+	Contribution map[string]float64 `json:"contribution"`      // Share of total contribution per agent
+	LaborBalance float64            `json:"labor_balance"`     // 1 when work is evenly split, 0 when one agent does it all
+	// CommDependence is the joint success rate with links minus the rate in
+	// episodes run with links disabled, in percentage points. It is only
+	// meaningful when the window contains both kinds of episode.
+	CommDependence float64 `json:"comm_dependence_pct"`
+	LinkedEpisodes int     `json:"linked_episodes"`
+}
+
+// Metrics converts the window to metrics stamped with the window number and
+// labeled with mode, for sending to a MetricSink.
+func (w CooperationWindow) Metrics(mode string) []Metric {
+	labels := map[string]string{"mode": mode}
+	step := uint64(w.WindowNum)
+	ms := []Metric{
+		{Name: "coop_joint_success_pct", Value: w.JointSuccess, Step: step, Labels: labels},
+		{Name: "coop_labor_balance", Value: w.LaborBalance, Step: step, Labels: labels},
+		{Name: "coop_comm_dependence_pct", Value: w.CommDependence, Step: step, Labels: labels},
+	}
+	for _, agent := range sortedKeys(w.Contribution) {
+		ms = append(ms, Metric{
+			Name:   "coop_contribution",
+			Value:  w.Contribution[agent],
+			Step:   step,
+			Labels: map[string]string{"mode": mode, "agent": agent},
+		})
+	}
+	return ms
+}
+
+// CooperationTracker accumulates episode outcomes into CooperationWindows of
+// Window episodes each.
+type CooperationTracker struct {
+	Window   int // Episodes per window; defaults to 20
+	OnWindow func(CooperationWindow)
+
+	mu      sync.Mutex
+	num     int
+	eps     int
+	success [2]int // Indexed by linked
+	count   [2]int
+	contrib map[string]float64
+}
+
+// NewCooperationTracker creates a tracker reporting every window episodes.
+func NewCooperationTracker(window int, onWindow func(CooperationWindow)) *CooperationTracker {
+	return &CooperationTracker{Window: window, OnWindow: onWindow}
+}
+
+// Episode records one finished episode: whether the agents jointly
+// succeeded, how much each agent contributed (in any unit, e.g. targets
+// reached or reward earned), and whether links were enabled.
+func (c *CooperationTracker) Episode(success bool, contributions map[string]float64, linked bool) {
+	c.mu.Lock()
+	if c.contrib == nil {
+		c.contrib = make(map[string]float64)
+	}
+	k := 0
+	if linked {
+		k = 1
+	}
+	c.count[k]++
+	if success {
+		c.success[k]++
+	}
+	for agent, v := range contributions {
+		c.contrib[agent] += v
+	}
+	c.eps++
+
+	window := c.Window
+	if window <= 0 {
+		window = 20
+	}
+	var w *CooperationWindow
+	if c.eps >= window {
+		w = c.close()
+	}
+	c.mu.Unlock()
+
+	if w != nil && c.OnWindow != nil {
+		c.OnWindow(*w)
+	}
+}
+
+// close builds the current window and resets the counters. The caller holds c.mu.
+func (c *CooperationTracker) close() *CooperationWindow {
+	c.num++
+	w := &CooperationWindow{
+		WindowNum:      c.num,
+		Episodes:       c.eps,
+		LinkedEpisodes: c.count[1],
+		Contribution:   make(map[string]float64, len(c.contrib)),
+	}
+	w.JointSuccess = pct(c.success[0]+c.success[1], c.eps)
+	if c.count[0] > 0 && c.count[1] > 0 {
+		w.CommDependence = pct(c.success[1], c.count[1]) - pct(c.success[0], c.count[0])
+	}
+
+	var total float64
+	for _, v := range c.contrib {
+		total += v
+	}
+	var entropy float64
+	for agent, v := range c.contrib {
+		share := 0.0
+		if total > 0 {
+			share = v / total
+		}
+		w.Contribution[agent] = share
+		if share > 0 {
+			entropy -= share * math.Log(share)
+		}
+	}
+	if n := len(c.contrib); n > 1 {
+		w.LaborBalance = entropy / math.Log(float64(n))
+	}
+
+	c.eps, c.success, c.count = 0, [2]int{}, [2]int{}
+	c.contrib = make(map[string]float64)
+	return w
+}
+
+func pct(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total) * 100
+}
@@ -0,0 +1,451 @@
+package drift
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DatasetFormat is the dataset layout version written by this package.
+const DatasetFormat = 1
+
+// Entry kinds of a dataset. Each kind lives in its own directory of the
+// canonical layout:
+//
+//	dataset.json            manifest
+//	configs/<name>.json     drift configs
+//	traces/<name>.jsonl     recorded link traffic, one StepRecord per line
+//	results/<name>.json     benchmark results, a JSON array of ExperimentResult
+//	runs/<name>.driftrun    run archives
+const (
+	DatasetConfig  = "config"
+	DatasetTrace   = "trace"
+	DatasetResults = "results"
+	DatasetRun     = "run"
+)
+
+const datasetManifest = "dataset.json"
+
+// datasetPaths maps each entry kind to its directory and file extension.
+var datasetPaths = map[string][2]string{
+	DatasetConfig:  {"configs", ".json"},
+	DatasetTrace:   {"traces", ".jsonl"},
+	DatasetResults: {"results", ".json"},
+	DatasetRun:     {"runs", RunArchiveExt},
+}
+
+// DatasetEntry describes one file of a dataset.
+type DatasetEntry struct {
+	Kind        string `json:"kind"`
+	Name        string `json:"name"`
+	Path        string `json:"path"`                  // Relative to the dataset directory, always the canonical one
+	Config      string `json:"config,omitempty"`      // For traces and results, the config entry they were produced with
+	ConfigHash  string `json:"config_hash,omitempty"` // Config.Hash of the entry's config
+	SHA256      string `json:"sha256"`
+	Description string `json:"description,omitempty"`
+}
+
+// DatasetManifest is the dataset.json of a dataset.
+type DatasetManifest struct {
+	Format      int            `json:"format"`
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	License     string         `json:"license,omitempty"`
+	Authors     []string       `json:"authors,omitempty"`
+	Entries     []DatasetEntry `json:"entries"`
+}
+
+// Dataset is a directory of recorded traces, benchmark results, configs and
+// run archives laid out canonically, so published experiments can be
+// re-analyzed with this package.
+type Dataset struct {
+	Dir      string
+	Manifest DatasetManifest
+}
+
+// CreateDataset creates a dataset in dir, which may already exist, with the
+// metadata of m. Entries of m are ignored; they are added with the Add
+// methods.
+func CreateDataset(dir string, m DatasetManifest) (*Dataset, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	m.Format = DatasetFormat
+	m.Entries = nil
+	d := &Dataset{Dir: dir, Manifest: m}
+	return d, d.save()
+}
+
+// OpenDataset reads the manifest of the dataset in dir.
+func OpenDataset(dir string) (*Dataset, error) {
+	data, err := os.ReadFile(filepath.Join(dir, datasetManifest))
+	if err != nil {
+		return nil, err
+	}
+	d := &Dataset{Dir: dir}
+	if err := json.Unmarshal(data, &d.Manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", datasetManifest, err)
+	}
+	if d.Manifest.Format > DatasetFormat {
+		return nil, fmt.Errorf("dataset format %d is newer than supported", d.Manifest.Format)
+	}
+	return d, nil
+}
+
+// Entries returns the entries of the given kind, or all of them for "".
+func (d *Dataset) Entries(kind string) []DatasetEntry {
+	var out []DatasetEntry
+	for _, e := range d.Manifest.Entries {
+		if kind == "" || e.Kind == kind {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// AddConfig stores cfg as the config entry name.
+func (d *Dataset) AddConfig(name string, cfg *Config) error {
+	data, err := cfg.ToJSON()
+	if err != nil {
+		return err
+	}
+	hash, err := cfg.Hash()
+	if err != nil {
+		return err
+	}
+	return d.add(DatasetEntry{Kind: DatasetConfig, Name: name, ConfigHash: hash}, []byte(data))
+}
+
+// AddTrace stores recorded steps as the trace entry name, recorded with the
+// config entry config.
+func (d *Dataset) AddTrace(name, config string, steps []StepRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range steps {
+		if err := enc.Encode(s); err != nil {
+			return err
+		}
+	}
+	return d.add(DatasetEntry{Kind: DatasetTrace, Name: name, Config: config}, buf.Bytes())
+}
+
+// AddResults stores benchmark results as the results entry name, produced
+// with the config entry config.
+func (d *Dataset) AddResults(name, config string, results []ExperimentResult) error {
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	return d.add(DatasetEntry{Kind: DatasetResults, Name: name, Config: config}, data)
+}
+
+// AddRun copies the run archive at path into the dataset as the run entry
+// name.
+func (d *Dataset) AddRun(name, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return d.add(DatasetEntry{Kind: DatasetRun, Name: name}, data)
+}
+
+// Config loads the config entry name.
+func (d *Dataset) Config(name string) (*Config, error) {
+	data, err := d.read(DatasetConfig, name)
+	if err != nil {
+		return nil, err
+	}
+	return FromJSON(string(data))
+}
+
+// Trace loads the trace entry name.
+func (d *Dataset) Trace(name string) ([]StepRecord, error) {
+	data, err := d.read(DatasetTrace, name)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTrace(data, name, false)
+}
+
+// Results loads the results entry name.
+func (d *Dataset) Results(name string) ([]ExperimentResult, error) {
+	data, err := d.read(DatasetResults, name)
+	if err != nil {
+		return nil, err
+	}
+	var results []ExperimentResult
+	return results, json.Unmarshal(data, &results)
+}
+
+// Run opens the run archive entry name. The caller must Close it.
+func (d *Dataset) Run(name string) (*ArchivedRun, error) {
+	e, err := d.entry(DatasetRun, name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.read(DatasetRun, name); err != nil {
+		return nil, err
+	}
+	return OpenRunArchive(filepath.Join(d.Dir, filepath.FromSlash(e.Path)))
+}
+
+// Validate checks the dataset against its schema: every entry must have a
+// valid kind and name, sit at its canonical path and match its checksum;
+// configs must validate; traces and results must decode without unknown
+// fields and refer to a config of the dataset with the hash they recorded;
+// traces must only name models and links of that config, with payloads of
+// the link's size; and run archives must open. It returns nil or a
+// DatasetErrors listing every problem found.
+func (d *Dataset) Validate() error {
+	var errs DatasetErrors
+	if d.Manifest.Format < 1 || d.Manifest.Format > DatasetFormat {
+		errs.add("format", "unsupported format %d", d.Manifest.Format)
+	}
+	if d.Manifest.Name == "" {
+		errs.add("name", "empty dataset name")
+	}
+	seen := make(map[[2]string]bool)
+	for i, e := range d.Manifest.Entries {
+		field := fmt.Sprintf("entries[%d]", i)
+		dir, ok := datasetPaths[e.Kind]
+		if !ok {
+			errs.add(field+".kind", "unknown kind %q", e.Kind)
+			continue
+		}
+		if err := checkEntryName(e.Name); err != nil {
+			errs.add(field+".name", "%v", err)
+			continue
+		}
+		if seen[[2]string{e.Kind, e.Name}] {
+			errs.add(field+".name", "duplicate %s %q", e.Kind, e.Name)
+		}
+		seen[[2]string{e.Kind, e.Name}] = true
+		if want := path.Join(dir[0], e.Name+dir[1]); e.Path != want {
+			errs.add(field+".path", "%q, want %q", e.Path, want)
+			continue
+		}
+		data, err := d.read(e.Kind, e.Name)
+		if err != nil {
+			errs.add(field, "%v", err)
+			continue
+		}
+		switch e.Kind {
+		case DatasetConfig:
+			cfg, err := FromJSON(string(data))
+			if err == nil {
+				err = cfg.Validate()
+			}
+			if err != nil {
+				errs.add(field, "%v", err)
+			} else if hash, _ := cfg.Hash(); e.ConfigHash != "" && e.ConfigHash != hash {
+				errs.add(field+".config_hash", "does not match the config")
+			}
+		case DatasetTrace:
+			cfg := d.entryConfig(&errs, field, e)
+			steps, err := decodeTrace(data, e.Name, true)
+			if err != nil {
+				errs.add(field, "%v", err)
+			} else if cfg != nil {
+				checkTrace(&errs, field, cfg, steps)
+			}
+		case DatasetResults:
+			d.entryConfig(&errs, field, e)
+			var results []ExperimentResult
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(&results); err != nil {
+				errs.add(field, "%v", err)
+			}
+			for j, r := range results {
+				if r.Mode == "" {
+					errs.add(fmt.Sprintf("%s[%d].mode", field, j), "empty mode")
+				}
+			}
+		case DatasetRun:
+			a, err := OpenRunArchive(filepath.Join(d.Dir, filepath.FromSlash(e.Path)))
+			if err != nil {
+				errs.add(field, "%v", err)
+				continue
+			}
+			a.Close()
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// DatasetErrors collects every problem found by Dataset.Validate.
+type DatasetErrors []*ValidationError
+
+func (es DatasetErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "invalid dataset: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As.
+func (es DatasetErrors) Unwrap() []error {
+	return ValidationErrors(es).Unwrap()
+}
+
+func (es *DatasetErrors) add(field, format string, args ...any) {
+	(*ValidationErrors)(es).add(field, format, args...)
+}
+
+// entryConfig loads the config a trace or results entry refers to,
+// reporting a missing reference or one whose hash has changed.
+func (d *Dataset) entryConfig(errs *DatasetErrors, field string, e DatasetEntry) *Config {
+	if e.Config == "" {
+		errs.add(field+".config", "no config")
+		return nil
+	}
+	cfg, err := d.Config(e.Config)
+	if err != nil {
+		errs.add(field+".config", "%v", err)
+		return nil
+	}
+	if hash, _ := cfg.Hash(); e.ConfigHash != "" && e.ConfigHash != hash {
+		errs.add(field+".config_hash", "config %q has changed since the entry was recorded", e.Config)
+	}
+	return cfg
+}
+
+// checkTrace reports steps naming models or links cfg doesn't have, link
+// payloads of the wrong size and steps out of order.
+func checkTrace(errs *DatasetErrors, field string, cfg *Config, steps []StepRecord) {
+	sizes := make(map[string]int, len(cfg.Links))
+	for _, l := range cfg.Links {
+		sizes[l.Name] = l.LinkSize
+	}
+	for i, s := range steps {
+		at := fmt.Sprintf("%s step %d", field, s.Step)
+		if i > 0 && s.Step <= steps[i-1].Step {
+			errs.add(at, "out of order after step %d", steps[i-1].Step)
+		}
+		for _, m := range []map[string][]float32{s.Inputs, s.Outputs} {
+			for _, name := range sortedKeys(m) {
+				if _, ok := cfg.Models[name]; !ok {
+					errs.add(at, "unknown model %q", name)
+				}
+			}
+		}
+		for _, name := range sortedKeys(s.Links) {
+			size, ok := sizes[name]
+			switch {
+			case !ok:
+				errs.add(at, "unknown link %q", name)
+			case len(s.Links[name]) != size:
+				errs.add(at, "link %q carries %d values, want %d", name, len(s.Links[name]), size)
+			}
+		}
+	}
+}
+
+// decodeTrace decodes JSON Lines step records; strict rejects unknown fields.
+func decodeTrace(data []byte, name string, strict bool) ([]StepRecord, error) {
+	var steps []StepRecord
+	err := readResultLines(bytes.NewReader(data), name, func(line json.RawMessage) error {
+		var s StepRecord
+		dec := json.NewDecoder(bytes.NewReader(line))
+		if strict {
+			dec.DisallowUnknownFields()
+		}
+		if err := dec.Decode(&s); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		steps = append(steps, s)
+		return nil
+	})
+	return steps, err
+}
+
+// add writes data at the canonical path of e and records e in the manifest,
+// replacing an entry of the same kind and name.
+func (d *Dataset) add(e DatasetEntry, data []byte) error {
+	if err := checkEntryName(e.Name); err != nil {
+		return err
+	}
+	dir := datasetPaths[e.Kind]
+	e.Path = path.Join(dir[0], e.Name+dir[1])
+	if e.Config != "" {
+		if ce, err := d.entry(DatasetConfig, e.Config); err == nil {
+			e.ConfigHash = ce.ConfigHash
+		}
+	}
+	sum := sha256.Sum256(data)
+	e.SHA256 = hex.EncodeToString(sum[:])
+	full := filepath.Join(d.Dir, filepath.FromSlash(e.Path))
+	if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+		return err
+	}
+	if err := writeFileAtomic(full, data, 0644, false); err != nil {
+		return err
+	}
+	replaced := false
+	for i, old := range d.Manifest.Entries {
+		if old.Kind == e.Kind && old.Name == e.Name {
+			if e.Description == "" {
+				e.Description = old.Description
+			}
+			d.Manifest.Entries[i] = e
+			replaced = true
+		}
+	}
+	if !replaced {
+		d.Manifest.Entries = append(d.Manifest.Entries, e)
+	}
+	return d.save()
+}
+
+// read returns the contents of an entry, verified against its checksum.
+func (d *Dataset) read(kind, name string) ([]byte, error) {
+	e, err := d.entry(kind, name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(d.Dir, filepath.FromSlash(e.Path)))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != e.SHA256 {
+		return nil, fmt.Errorf("%s %q: checksum mismatch", kind, name)
+	}
+	return data, nil
+}
+
+func (d *Dataset) entry(kind, name string) (*DatasetEntry, error) {
+	for i, e := range d.Manifest.Entries {
+		if e.Kind == kind && e.Name == name {
+			return &d.Manifest.Entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%s %q not found", kind, name)
+}
+
+func (d *Dataset) save() error {
+	data, err := json.MarshalIndent(d.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(d.Dir, datasetManifest), data, 0644, false)
+}
+
+// checkEntryName rejects names that are empty or would escape their
+// directory.
+func checkEntryName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid entry name %q", name)
+	}
+	return nil
+}
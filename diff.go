@@ -0,0 +1,176 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldChange is one field whose value differs between two configs.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// LinkChange lists the fields of a link present in both configs that
+// changed.
+type LinkChange struct {
+	Name   string        `json:"name"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// ConfigDiff is the structured changeset between two configs. Top-level
+// sections other than models and links (inputs, scenario, ...) are reported
+// in Fields as a whole when they differ.
+type ConfigDiff struct {
+	Fields         []FieldChange `json:"fields,omitempty"`
+	ModelsAdded    []string      `json:"models_added,omitempty"`
+	ModelsRemoved  []string      `json:"models_removed,omitempty"`
+	ModelsModified []string      `json:"models_modified,omitempty"`
+	LinksAdded     []string      `json:"links_added,omitempty"`
+	LinksRemoved   []string      `json:"links_removed,omitempty"`
+	LinksModified  []LinkChange  `json:"links_modified,omitempty"`
+}
+
+// Diff returns the changes that turn a into b. Model definitions are
+// compared semantically, so reformatted JSON doesn't count as a change.
+func Diff(a, b *Config) *ConfigDiff {
+	d := &ConfigDiff{}
+	for _, f := range []struct {
+		name     string
+		old, new any
+	}{
+		{"name", a.Name, b.Name},
+		{"seed", a.Seed, b.Seed},
+		{"dtype", a.DType, b.DType},
+		{"inputs", a.Inputs, b.Inputs},
+		{"resources", a.Resources, b.Resources},
+		{"metadata", a.Metadata, b.Metadata},
+		{"scenario", a.Scenario, b.Scenario},
+		{"training", a.Training, b.Training},
+		{"probes", a.Probes, b.Probes},
+	} {
+		if !reflect.DeepEqual(f.old, f.new) {
+			d.Fields = append(d.Fields, FieldChange{Field: f.name, Old: f.old, New: f.new})
+		}
+	}
+
+	for _, name := range sortedKeys(a.Models) {
+		nb, ok := b.Models[name]
+		switch {
+		case !ok:
+			d.ModelsRemoved = append(d.ModelsRemoved, name)
+		case !sameJSON(a.Models[name], nb):
+			d.ModelsModified = append(d.ModelsModified, name)
+		}
+	}
+	for _, name := range sortedKeys(b.Models) {
+		if _, ok := a.Models[name]; !ok {
+			d.ModelsAdded = append(d.ModelsAdded, name)
+		}
+	}
+
+	linksB := make(map[string]NeuralLinkConfig, len(b.Links))
+	for _, l := range b.Links {
+		linksB[l.Name] = l
+	}
+	linksA := make(map[string]bool, len(a.Links))
+	for _, la := range a.Links {
+		linksA[la.Name] = true
+		lb, ok := linksB[la.Name]
+		if !ok {
+			d.LinksRemoved = append(d.LinksRemoved, la.Name)
+			continue
+		}
+		if fields := linkFieldChanges(la, lb); len(fields) > 0 {
+			d.LinksModified = append(d.LinksModified, LinkChange{Name: la.Name, Fields: fields})
+		}
+	}
+	for _, l := range b.Links {
+		if !linksA[l.Name] {
+			d.LinksAdded = append(d.LinksAdded, l.Name)
+		}
+	}
+	return d
+}
+
+// Empty reports whether the configs were equivalent.
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Fields) == 0 &&
+		len(d.ModelsAdded)+len(d.ModelsRemoved)+len(d.ModelsModified) == 0 &&
+		len(d.LinksAdded)+len(d.LinksRemoved)+len(d.LinksModified) == 0
+}
+
+// String renders the diff one change per line, prefixed with +, - or ~.
+func (d *ConfigDiff) String() string {
+	var b strings.Builder
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "~ %s: %s -> %s\n", f.Field, diffValue(f.Old), diffValue(f.New))
+	}
+	for _, name := range d.ModelsAdded {
+		fmt.Fprintf(&b, "+ model %s\n", name)
+	}
+	for _, name := range d.ModelsRemoved {
+		fmt.Fprintf(&b, "- model %s\n", name)
+	}
+	for _, name := range d.ModelsModified {
+		fmt.Fprintf(&b, "~ model %s\n", name)
+	}
+	for _, name := range d.LinksAdded {
+		fmt.Fprintf(&b, "+ link %s\n", name)
+	}
+	for _, name := range d.LinksRemoved {
+		fmt.Fprintf(&b, "- link %s\n", name)
+	}
+	for _, c := range d.LinksModified {
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "~ link %s %s: %s -> %s\n", c.Name, f.Field, diffValue(f.Old), diffValue(f.New))
+		}
+	}
+	return b.String()
+}
+
+// linkFieldChanges compares two links field by field, naming fields by
+// their JSON keys.
+func linkFieldChanges(a, b NeuralLinkConfig) []FieldChange {
+	var changes []FieldChange
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	t := va.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fa, fb := va.Field(i).Interface(), vb.Field(i).Interface()
+		if reflect.DeepEqual(fa, fb) {
+			continue
+		}
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		changes = append(changes, FieldChange{Field: name, Old: fa, New: fb})
+	}
+	return changes
+}
+
+// sameJSON reports whether two JSON documents decode to equal values.
+func sameJSON(a, b json.RawMessage) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// diffValue formats a changed value for String.
+func diffValue(v any) string {
+	switch v.(type) {
+	case string, DType:
+		return fmt.Sprintf("%q", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
@@ -0,0 +1,103 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Stepper advances a linked system by one step. Runtime implements it, as can
+// alternative execution strategies being compared against it.
+type Stepper interface {
+	Step(inputs map[string][]float32) (map[string][]float32, error)
+}
+
+// Divergence locates the first output that differs between two runs.
+type Divergence struct {
+	Step  int     `json:"step"`
+	Model string  `json:"model"`
+	Index int     `json:"index"` // Output element, or -1 when output lengths differ
+	A     float32 `json:"a"`
+	B     float32 `json:"b"`
+}
+
+func (d *Divergence) Error() string {
+	if d.Index < 0 {
+		return fmt.Sprintf("step %d: model %s output length differs", d.Step, d.Model)
+	}
+	return fmt.Sprintf("step %d: model %s output[%d] = %g vs %g", d.Step, d.Model, d.Index, d.A, d.B)
+}
+
+// DiffSteppers runs a and b in lockstep on the inputs produced by inputs for
+// steps steps and returns the first divergence beyond tol, or nil if the runs
+// agree. Use it with two runtimes built from the same seeded config to check
+// that an optimization of the hot path didn't change results.
+func DiffSteppers(a, b Stepper, steps int, inputs func(step int) map[string][]float32, tol float64) (*Divergence, error) {
+	for step := 0; step < steps; step++ {
+		var in map[string][]float32
+		if inputs != nil {
+			in = inputs(step)
+		}
+		oa, err := a.Step(in)
+		if err != nil {
+			return nil, fmt.Errorf("a: step %d: %w", step, err)
+		}
+		ob, err := b.Step(in)
+		if err != nil {
+			return nil, fmt.Errorf("b: step %d: %w", step, err)
+		}
+		if d := compareOutputs(step, oa, ob, tol); d != nil {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// DiffTrace replays a recording made by another version of the runtime
+// through s and returns the first step whose outputs diverge beyond tol.
+// Recording with one build and replaying with another is how two versions of
+// the package are compared.
+func DiffTrace(s Stepper, trace []StepRecord, tol float64) (*Divergence, error) {
+	for i, rec := range trace {
+		out, err := s.Step(rec.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", i, err)
+		}
+		if d := compareOutputs(i, rec.Outputs, out, tol); d != nil {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// compareOutputs returns the first element of a and b, in model-name order,
+// that differs by more than tol.
+func compareOutputs(step int, a, b map[string][]float32, tol float64) *Divergence {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	for name := range b {
+		if _, ok := a[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		oa, ob := a[name], b[name]
+		if len(oa) != len(ob) {
+			return &Divergence{Step: step, Model: name, Index: -1}
+		}
+		for i := range oa {
+			x, y := float64(oa[i]), float64(ob[i])
+			if math.IsNaN(x) && math.IsNaN(y) {
+				continue
+			}
+			if diff := math.Abs(x - y); diff > tol || math.IsNaN(diff) {
+				return &Divergence{Step: step, Model: name, Index: i, A: oa[i], B: ob[i]}
+			}
+		}
+	}
+	return nil
+}
@@ -6,39 +6,190 @@
 package drift
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 )
 
 // NeuralLinkConfig defines how to connect two models.
 // Source model's layer output is injected into target model's input at specified offset.
 type NeuralLinkConfig struct {
-	Name         string `json:"name"`          // Unique identifier for this link
-	SourceModel  string `json:"source_model"`  // Name of the source model
-	SourceLayer  int    `json:"source_layer"`  // Layer index to extract activations from
-	TargetModel  string `json:"target_model"`  // Name of the target model
-	TargetOffset int    `json:"target_offset"` // Input offset where link data is injected
-	LinkSize     int    `json:"link_size"`     // Number of neurons to transfer
-	Enabled      bool   `json:"enabled"`       // Whether this link is active
-	Description  string `json:"description"`   // Human-readable description
+	Name         string   `json:"name"`            // Unique identifier for this link
+	ID           string   `json:"id,omitempty"`    // Stable key that survives renames; see LinkID
+	SourceModel  string   `json:"source_model"`    // Name of the source model
+	SourceLayer  int      `json:"source_layer"`    // Layer index to extract activations from
+	TargetModel  string   `json:"target_model"`    // Name of the target model
+	TargetOffset int      `json:"target_offset"`   // Input offset where link data is injected
+	LinkSize     int      `json:"link_size"`       // Number of neurons to transfer
+	Enabled      bool     `json:"enabled"`         // Whether this link is active
+	Description  string   `json:"description"`     // Human-readable description
+	Group        string   `json:"group,omitempty"` // Optional group for bulk operations
+	Tags         []string `json:"tags,omitempty"`  // Optional tags, also usable as groups
+
+	Active        []ActiveWindow  `json:"active,omitempty"`         // Step windows the link is live in; see ActiveWindow
+	Delivery      float64         `json:"delivery,omitempty"`       // Probability each payload is delivered; 0 means always
+	LearnDelivery bool            `json:"learn_delivery,omitempty"` // Adapt the delivery probability; see Runtime.RewardDelivery
+	Homeostasis   *Homeostasis    `json:"homeostasis,omitempty"`    // Automatic gain regulation; see Homeostasis
+	Range         *RangeAdapter   `json:"range,omitempty"`          // Maps payloads onto the range the target expects
+	Checksum      bool            `json:"checksum,omitempty"`       // Verify payloads at the target against a checksum taken at the source; see LinkFrame
+	Transforms    []TransformSpec `json:"transforms,omitempty"`     // Applied to every payload in order; see TransformSpec
+	Gain          *float64        `json:"gain,omitempty"`           // Initial payload multiplier; nil means 1. See Runtime.RampLinkGain
+}
+
+// LinkID returns the link's ID, or for links without one the ID AddLink
+// would generate: a hash of the name. Use it to key metrics and maps by
+// link. A generated ID follows the name until it is stored in the ID field,
+// as AddLink, UpdateLink and Normalize do; from then on it survives renames.
+func (l NeuralLinkConfig) LinkID() string {
+	if l.ID != "" {
+		return l.ID
+	}
+	sum := sha256.Sum256([]byte(l.Name))
+	return "link-" + hex.EncodeToString(sum[:6])
+}
+
+// InGroup reports whether the link belongs to group, either by its Group
+// field or by carrying group as a tag.
+func (l NeuralLinkConfig) InGroup(group string) bool {
+	if group == "" {
+		return false
+	}
+	if l.Group == group {
+		return true
+	}
+	for _, t := range l.Tags {
+		if t == group {
+			return true
+		}
+	}
+	return false
 }
 
 // Config holds the configuration for a DRIFT instance.
 type Config struct {
-	Name   string                     `json:"name"`
-	Models map[string]json.RawMessage `json:"models"`
-	Links  []NeuralLinkConfig         `json:"links,omitempty"`
+	SchemaVersion int `json:"schema_version,omitempty"` // See CurrentSchemaVersion
+
+	Name      string                     `json:"name"`
+	Seed      int64                      `json:"seed,omitempty"`  // Non-zero makes initial weights and random streams reproducible; see SeededSource
+	DType     DType                      `json:"dtype,omitempty"` // Numeric type; empty means float32, the only one that runs
+	Models    map[string]json.RawMessage `json:"models"`
+	Inputs    map[string][]InputSegment  `json:"inputs,omitempty"`    // Named input segments per model
+	Resources map[string]ResourceHints   `json:"resources,omitempty"` // Execution requirements per model
+	Metadata  map[string]ModelMetadata   `json:"metadata,omitempty"`  // Descriptive metadata per model
+	Links     []NeuralLinkConfig         `json:"links,omitempty"`
+	Scenario  []Intervention             `json:"scenario,omitempty"`
+	Training  []TrainingPhase            `json:"training,omitempty"` // Freeze-thaw schedule run by a Trainer
+	Probes    []Probe                    `json:"probes,omitempty"`   // Values logged to metric sinks; see Probe
+
+	warnings  []string
+	overrides []Override
+	includes  []string
+	linkIdx   map[string]int // Link positions by name; see GetLink
+	linkByID  map[string]int // Link positions by ID; see GetLinkByID
+	linkN     int            // len(Links) when the indexes were built
+
+	enabledUnset map[string]bool // Links loaded without an "enabled" field; see Normalize
 }
 
 // NewConfig creates a new Config with the given name.
 func NewConfig(name string) *Config {
 	return &Config{
-		Name:   name,
-		Models: make(map[string]json.RawMessage),
-		Links:  []NeuralLinkConfig{},
+		SchemaVersion: CurrentSchemaVersion,
+		Name:          name,
+		Models:        make(map[string]json.RawMessage),
+		Links:         []NeuralLinkConfig{},
 	}
 }
 
+// Clone returns a deep copy of the config. Model definitions, links,
+// input segments, scenario and training phases are all copied, so changes
+// to the clone never reach the original.
+func (c *Config) Clone() *Config {
+	n := *c
+	n.linkIdx, n.linkByID = nil, nil
+	if c.Models != nil {
+		n.Models = make(map[string]json.RawMessage, len(c.Models))
+		for name, raw := range c.Models {
+			n.Models[name] = append(json.RawMessage(nil), raw...)
+		}
+	}
+	if c.Inputs != nil {
+		n.Inputs = make(map[string][]InputSegment, len(c.Inputs))
+		for name, segs := range c.Inputs {
+			segs = append([]InputSegment(nil), segs...)
+			for i := range segs {
+				segs[i].Embedding = append([]float32(nil), segs[i].Embedding...)
+			}
+			n.Inputs[name] = segs
+		}
+	}
+	if c.Resources != nil {
+		n.Resources = make(map[string]ResourceHints, len(c.Resources))
+		for name, hints := range c.Resources {
+			n.Resources[name] = hints
+		}
+	}
+	if c.Metadata != nil {
+		n.Metadata = make(map[string]ModelMetadata, len(c.Metadata))
+		for name, md := range c.Metadata {
+			md.Tags = append([]string(nil), md.Tags...)
+			n.Metadata[name] = md
+		}
+	}
+	if c.Links != nil {
+		n.Links = append([]NeuralLinkConfig{}, c.Links...)
+		for i := range n.Links {
+			n.Links[i].Tags = append([]string(nil), n.Links[i].Tags...)
+			n.Links[i].Active = append([]ActiveWindow(nil), n.Links[i].Active...)
+			n.Links[i].Transforms = append([]TransformSpec(nil), n.Links[i].Transforms...)
+			if g := n.Links[i].Gain; g != nil {
+				g := *g
+				n.Links[i].Gain = &g
+			}
+			if h := n.Links[i].Homeostasis; h != nil {
+				h := *h
+				n.Links[i].Homeostasis = &h
+			}
+			if a := n.Links[i].Range; a != nil {
+				a := *a
+				if a.Source != nil {
+					src := *a.Source
+					a.Source = &src
+				}
+				n.Links[i].Range = &a
+			}
+		}
+	}
+	if c.Scenario != nil {
+		n.Scenario = append([]Intervention{}, c.Scenario...)
+		for i := range n.Scenario {
+			n.Scenario[i].Params = append(json.RawMessage(nil), n.Scenario[i].Params...)
+		}
+	}
+	if c.Training != nil {
+		n.Training = append([]TrainingPhase{}, c.Training...)
+		for i := range n.Training {
+			n.Training[i].Train = append([]string(nil), n.Training[i].Train...)
+		}
+	}
+	if c.Probes != nil {
+		n.Probes = append([]Probe{}, c.Probes...)
+	}
+	n.warnings = append([]string(nil), c.warnings...)
+	n.overrides = append([]Override(nil), c.overrides...)
+	n.includes = append([]string(nil), c.includes...)
+	if c.enabledUnset != nil {
+		n.enabledUnset = make(map[string]bool, len(c.enabledUnset))
+		for name := range c.enabledUnset {
+			n.enabledUnset[name] = true
+		}
+	}
+	return &n
+}
+
 // GetName returns the name of the config.
 func (c *Config) GetName() string {
 	return c.Name
@@ -63,9 +214,88 @@ func (c *Config) GetModel(name string, target interface{}) error {
 	return json.Unmarshal(data, target)
 }
 
-// AddLink adds a neural link configuration.
-func (c *Config) AddLink(link NeuralLinkConfig) {
+// ErrDuplicateLink is returned by AddLink for a name or ID already in use.
+var ErrDuplicateLink = errors.New("drift: duplicate link")
+
+// AddLink adds a neural link configuration. A link without an ID is given
+// the one LinkID generates from its name; a caller-supplied ID is kept. Link
+// names and IDs must be unique.
+func (c *Config) AddLink(link NeuralLinkConfig) error {
+	if c.findLink(link.Name) >= 0 {
+		return fmt.Errorf("link %q: %w", link.Name, ErrDuplicateLink)
+	}
+	if link.ID == "" {
+		link.ID = link.LinkID()
+	}
+	if _, dup := c.GetLinkByID(link.ID); dup {
+		return fmt.Errorf("link %q: ID %q: %w", link.Name, link.ID, ErrDuplicateLink)
+	}
 	c.Links = append(c.Links, link)
+	c.linkN = len(c.Links)
+	c.indexLink(len(c.Links) - 1)
+	return nil
+}
+
+// GetLinkByID returns the link with the given ID; see LinkID.
+func (c *Config) GetLinkByID(id string) (NeuralLinkConfig, bool) {
+	c.indexLinks(false)
+	i, ok := c.linkByID[id]
+	if ok && c.Links[i].LinkID() != id {
+		c.indexLinks(true)
+		i, ok = c.linkByID[id]
+	}
+	if !ok {
+		return NeuralLinkConfig{}, false
+	}
+	return c.Links[i], true
+}
+
+// GetLink returns the link called name. Lookups go through indexes kept
+// up to date by AddLink and UpdateLink and rebuilt when links have been
+// added or removed directly, so they take constant time. After renaming
+// links in place, call Normalize. Like the rest of Config, it is not safe
+// for concurrent use.
+func (c *Config) GetLink(name string) (NeuralLinkConfig, bool) {
+	i := c.findLink(name)
+	if i < 0 {
+		return NeuralLinkConfig{}, false
+	}
+	return c.Links[i], true
+}
+
+// findLink returns the position of the first link called name, or -1.
+func (c *Config) findLink(name string) int {
+	c.indexLinks(false)
+	i, ok := c.linkIdx[name]
+	if ok && c.Links[i].Name != name {
+		// Links was reordered without AddLink.
+		c.indexLinks(true)
+		i, ok = c.linkIdx[name]
+	}
+	if !ok {
+		return -1
+	}
+	return i
+}
+
+// indexLinks rebuilds the link indexes if they are missing, if links have
+// been added or removed since they were built, or if force is set.
+func (c *Config) indexLinks(force bool) {
+	if !force && c.linkIdx != nil && c.linkN == len(c.Links) {
+		return
+	}
+	c.linkIdx = make(map[string]int, len(c.Links))
+	c.linkByID = make(map[string]int, len(c.Links))
+	c.linkN = len(c.Links)
+	for i := len(c.Links) - 1; i >= 0; i-- {
+		c.indexLink(i)
+	}
+}
+
+// indexLink records the link at position i in the indexes.
+func (c *Config) indexLink(i int) {
+	c.linkIdx[c.Links[i].Name] = i
+	c.linkByID[c.Links[i].LinkID()] = i
 }
 
 // GetLinks returns all neural link configurations.
@@ -74,10 +304,14 @@ func (c *Config) GetLinks() []NeuralLinkConfig {
 }
 
 // GetLinksBySource returns all links originating from the specified model.
+// Namespaced models may be given by any name ResolveModel accepts.
 func (c *Config) GetLinksBySource(modelName string) []NeuralLinkConfig {
+	if name, err := c.ResolveModel(modelName); err == nil {
+		modelName = name
+	}
 	var result []NeuralLinkConfig
 	for _, link := range c.Links {
-		if link.SourceModel == modelName && link.Enabled {
+		if c.resolveLink(link).SourceModel == modelName && link.Enabled {
 			result = append(result, link)
 		}
 	}
@@ -85,16 +319,43 @@ func (c *Config) GetLinksBySource(modelName string) []NeuralLinkConfig {
 }
 
 // GetLinksByTarget returns all links targeting the specified model.
+// Namespaced models may be given by any name ResolveModel accepts.
 func (c *Config) GetLinksByTarget(modelName string) []NeuralLinkConfig {
+	if name, err := c.ResolveModel(modelName); err == nil {
+		modelName = name
+	}
 	var result []NeuralLinkConfig
 	for _, link := range c.Links {
-		if link.TargetModel == modelName && link.Enabled {
+		if c.resolveLink(link).TargetModel == modelName && link.Enabled {
 			result = append(result, link)
 		}
 	}
 	return result
 }
 
+// GetLinksInGroup returns all links belonging to group, enabled or not.
+func (c *Config) GetLinksInGroup(group string) []NeuralLinkConfig {
+	var result []NeuralLinkConfig
+	for _, link := range c.Links {
+		if link.InGroup(group) {
+			result = append(result, link)
+		}
+	}
+	return result
+}
+
+// EnableGroup sets Enabled on every link in group and returns how many links matched.
+func (c *Config) EnableGroup(group string, enabled bool) int {
+	n := 0
+	for i := range c.Links {
+		if c.Links[i].InGroup(group) {
+			c.Links[i].Enabled = enabled
+			n++
+		}
+	}
+	return n
+}
+
 // ToJSON serializes the config to a JSON string.
 func (c *Config) ToJSON() (string, error) {
 	data, err := json.MarshalIndent(c, "", "  ")
@@ -104,35 +365,143 @@ func (c *Config) ToJSON() (string, error) {
 	return string(data), nil
 }
 
-// FromJSON deserializes a JSON string into a Config.
+// FromJSON deserializes a JSON string into a Config, migrating it from an
+// older schema version if needed.
 func FromJSON(data string) (*Config, error) {
-	var c Config
-	err := json.Unmarshal([]byte(data), &c)
+	return decodeConfig([]byte(data), nil)
+}
+
+// SaveToFile saves the config to a JSON file, gzip-compressed when path
+// ends in ".gz" (see SaveToFileCompressed).
+// The file is replaced atomically, so a crash mid-write leaves the previous
+// version intact, and an advisory lock keeps concurrent writers from interleaving.
+func (c *Config) SaveToFile(path string) error {
+	return c.saveLocked(path, false, isGzipPath(path))
+}
+
+// SaveToFileWithBackup saves the config like SaveToFile, first copying the
+// existing file (if any) to path + ".bak".
+func (c *Config) SaveToFileWithBackup(path string) error {
+	return c.saveLocked(path, true, isGzipPath(path))
+}
+
+func (c *Config) saveLocked(path string, backup, compress bool) error {
+	unlock, err := lockFile(path, true)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return &c, nil
+	defer unlock()
+	return c.save(path, backup, compress)
 }
 
-// SaveToFile saves the config to a JSON file.
-func (c *Config) SaveToFile(path string) error {
+func (c *Config) save(path string, backup, compress bool) error {
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(path, data, 0644)
+	if compress {
+		if data, err = gzipData(data); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, data, 0644, backup)
 }
 
-// LoadFromFile loads a config from a JSON file.
+// LoadFromFile loads a config from a JSON file, holding a shared advisory
+// lock so it never observes a concurrent SaveToFile midway. Gzip-compressed
+// files are recognized by their header and decompressed, whatever their name. Files written
+// with an older schema are upgraded in memory; see Config.Warnings. Included
+// files are merged in (see Config.Includes), ${var} references are expanded
+// from the variables section, then DRIFT_ environment overrides are applied;
+// see Config.ApplyEnvOverrides.
 func LoadFromFile(path string) (*Config, error) {
+	return LoadFromFileWithVars(path, nil)
+}
+
+// LoadFromFileWithVars loads a config like LoadFromFile, expanding ${var}
+// references with vars, which take precedence over the file's variables
+// section.
+func LoadFromFileWithVars(path string, vars map[string]any) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	c, err := load(path, vars)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.ApplyEnvOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func load(path string, vars map[string]any) (*Config, error) {
+	data, _, err := readConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return loadData(data, path, vars)
+}
+
+// readConfigFile reads a config file, decompressing it if it is gzipped,
+// and reports whether it was.
+func readConfigFile(path string) ([]byte, bool, error) {
 	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	compressed := isGzip(data)
+	if compressed {
+		if data, err = gunzip(data); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if isEncryptedConfig(data) {
+		return nil, false, fmt.Errorf("%s: %w", path, ErrConfigEncrypted)
+	}
+	return data, compressed, nil
+}
+
+// loadData decodes config file contents read from path.
+func loadData(data []byte, path string, vars map[string]any) (*Config, error) {
+	data, includes, err := expandIncludes(data, path)
 	if err != nil {
 		return nil, err
 	}
-	var c Config
-	err = json.Unmarshal(data, &c)
+	c, err := decodeConfig(data, vars)
 	if err != nil {
 		return nil, err
 	}
-	return &c, nil
+	c.includes = includes
+	return c, nil
+}
+
+// UpdateFile loads the config at path, applies fn, and saves the result while
+// holding an exclusive lock throughout, so read-modify-write cycles from
+// different processes can't lose each other's changes. Nothing is written if
+// fn returns an error. A gzipped file stays gzipped.
+func UpdateFile(path string, fn func(*Config) error) error {
+	unlock, err := lockFile(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	data, compressed, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	c, err := loadData(data, path, nil)
+	if err != nil {
+		return err
+	}
+	if len(c.includes) > 0 {
+		return fmt.Errorf("%s includes other files; saving would flatten them", path)
+	}
+	if err := fn(c); err != nil {
+		return err
+	}
+	return c.save(path, false, compressed || isGzipPath(path))
 }
@@ -0,0 +1,1450 @@
+// Wire schema of a DRIFT config, for producing and consuming configs from
+// languages other than Go. It mirrors the JSON form field for field; see
+// Config.ToProto and FromProto in the drift package. After editing,
+// regenerate drift.pb.go with
+//
+//   protoc --go_out=. --go_opt=paths=source_relative driftpb/drift.proto
+//
+// DRIFT encodes deterministically: fields in field-number order, fields
+// holding their default value omitted, map entries sorted by key with both
+// key and value written, and packed repeated scalars. Encoders that
+// serialize deterministically (e.g. SetDeterministic in Go, C++ and Java)
+// produce the same bytes for the same config.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: driftpb/drift.proto
+
+package driftpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Config struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SchemaVersion int64                  `protobuf:"varint,1,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Seed          int64                  `protobuf:"varint,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	Dtype         string                 `protobuf:"bytes,4,opt,name=dtype,proto3" json:"dtype,omitempty"`
+	// Model definitions, as the compact JSON the loom backend loads.
+	Models        map[string][]byte         `protobuf:"bytes,5,rep,name=models,proto3" json:"models,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Inputs        map[string]*InputSegments `protobuf:"bytes,6,rep,name=inputs,proto3" json:"inputs,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Resources     map[string]*ResourceHints `protobuf:"bytes,7,rep,name=resources,proto3" json:"resources,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Metadata      map[string]*ModelMetadata `protobuf:"bytes,8,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Links         []*NeuralLinkConfig       `protobuf:"bytes,9,rep,name=links,proto3" json:"links,omitempty"`
+	Scenario      []*Intervention           `protobuf:"bytes,10,rep,name=scenario,proto3" json:"scenario,omitempty"`
+	Training      []*TrainingPhase          `protobuf:"bytes,11,rep,name=training,proto3" json:"training,omitempty"`
+	Probes        []*Probe                  `protobuf:"bytes,12,rep,name=probes,proto3" json:"probes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Config) Reset() {
+	*x = Config{}
+	mi := &file_driftpb_drift_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Config) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Config) ProtoMessage() {}
+
+func (x *Config) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Config.ProtoReflect.Descriptor instead.
+func (*Config) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Config) GetSchemaVersion() int64 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *Config) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Config) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *Config) GetDtype() string {
+	if x != nil {
+		return x.Dtype
+	}
+	return ""
+}
+
+func (x *Config) GetModels() map[string][]byte {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+func (x *Config) GetInputs() map[string]*InputSegments {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+func (x *Config) GetResources() map[string]*ResourceHints {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *Config) GetMetadata() map[string]*ModelMetadata {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Config) GetLinks() []*NeuralLinkConfig {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Config) GetScenario() []*Intervention {
+	if x != nil {
+		return x.Scenario
+	}
+	return nil
+}
+
+func (x *Config) GetTraining() []*TrainingPhase {
+	if x != nil {
+		return x.Training
+	}
+	return nil
+}
+
+func (x *Config) GetProbes() []*Probe {
+	if x != nil {
+		return x.Probes
+	}
+	return nil
+}
+
+type NeuralLinkConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	SourceModel   string                 `protobuf:"bytes,3,opt,name=source_model,json=sourceModel,proto3" json:"source_model,omitempty"`
+	SourceLayer   int64                  `protobuf:"varint,4,opt,name=source_layer,json=sourceLayer,proto3" json:"source_layer,omitempty"`
+	TargetModel   string                 `protobuf:"bytes,5,opt,name=target_model,json=targetModel,proto3" json:"target_model,omitempty"`
+	TargetOffset  int64                  `protobuf:"varint,6,opt,name=target_offset,json=targetOffset,proto3" json:"target_offset,omitempty"`
+	LinkSize      int64                  `protobuf:"varint,7,opt,name=link_size,json=linkSize,proto3" json:"link_size,omitempty"`
+	Enabled       bool                   `protobuf:"varint,8,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	Description   string                 `protobuf:"bytes,9,opt,name=description,proto3" json:"description,omitempty"`
+	Group         string                 `protobuf:"bytes,10,opt,name=group,proto3" json:"group,omitempty"`
+	Tags          []string               `protobuf:"bytes,11,rep,name=tags,proto3" json:"tags,omitempty"`
+	Active        []*ActiveWindow        `protobuf:"bytes,12,rep,name=active,proto3" json:"active,omitempty"`
+	Delivery      float64                `protobuf:"fixed64,13,opt,name=delivery,proto3" json:"delivery,omitempty"`
+	LearnDelivery bool                   `protobuf:"varint,14,opt,name=learn_delivery,json=learnDelivery,proto3" json:"learn_delivery,omitempty"`
+	Homeostasis   *Homeostasis           `protobuf:"bytes,15,opt,name=homeostasis,proto3" json:"homeostasis,omitempty"`
+	Range         *RangeAdapter          `protobuf:"bytes,16,opt,name=range,proto3" json:"range,omitempty"`
+	Checksum      bool                   `protobuf:"varint,17,opt,name=checksum,proto3" json:"checksum,omitempty"`
+	Transforms    []*TransformSpec       `protobuf:"bytes,18,rep,name=transforms,proto3" json:"transforms,omitempty"`
+	Gain          *float64               `protobuf:"fixed64,19,opt,name=gain,proto3,oneof" json:"gain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeuralLinkConfig) Reset() {
+	*x = NeuralLinkConfig{}
+	mi := &file_driftpb_drift_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeuralLinkConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeuralLinkConfig) ProtoMessage() {}
+
+func (x *NeuralLinkConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeuralLinkConfig.ProtoReflect.Descriptor instead.
+func (*NeuralLinkConfig) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *NeuralLinkConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetSourceModel() string {
+	if x != nil {
+		return x.SourceModel
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetSourceLayer() int64 {
+	if x != nil {
+		return x.SourceLayer
+	}
+	return 0
+}
+
+func (x *NeuralLinkConfig) GetTargetModel() string {
+	if x != nil {
+		return x.TargetModel
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetTargetOffset() int64 {
+	if x != nil {
+		return x.TargetOffset
+	}
+	return 0
+}
+
+func (x *NeuralLinkConfig) GetLinkSize() int64 {
+	if x != nil {
+		return x.LinkSize
+	}
+	return 0
+}
+
+func (x *NeuralLinkConfig) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *NeuralLinkConfig) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *NeuralLinkConfig) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *NeuralLinkConfig) GetActive() []*ActiveWindow {
+	if x != nil {
+		return x.Active
+	}
+	return nil
+}
+
+func (x *NeuralLinkConfig) GetDelivery() float64 {
+	if x != nil {
+		return x.Delivery
+	}
+	return 0
+}
+
+func (x *NeuralLinkConfig) GetLearnDelivery() bool {
+	if x != nil {
+		return x.LearnDelivery
+	}
+	return false
+}
+
+func (x *NeuralLinkConfig) GetHomeostasis() *Homeostasis {
+	if x != nil {
+		return x.Homeostasis
+	}
+	return nil
+}
+
+func (x *NeuralLinkConfig) GetRange() *RangeAdapter {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *NeuralLinkConfig) GetChecksum() bool {
+	if x != nil {
+		return x.Checksum
+	}
+	return false
+}
+
+func (x *NeuralLinkConfig) GetTransforms() []*TransformSpec {
+	if x != nil {
+		return x.Transforms
+	}
+	return nil
+}
+
+func (x *NeuralLinkConfig) GetGain() float64 {
+	if x != nil && x.Gain != nil {
+		return *x.Gain
+	}
+	return 0
+}
+
+type TransformSpec struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Min           float64                `protobuf:"fixed64,2,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,3,opt,name=max,proto3" json:"max,omitempty"`
+	Factor        float64                `protobuf:"fixed64,4,opt,name=factor,proto3" json:"factor,omitempty"`
+	Offset        float64                `protobuf:"fixed64,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransformSpec) Reset() {
+	*x = TransformSpec{}
+	mi := &file_driftpb_drift_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransformSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformSpec) ProtoMessage() {}
+
+func (x *TransformSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransformSpec.ProtoReflect.Descriptor instead.
+func (*TransformSpec) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TransformSpec) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *TransformSpec) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *TransformSpec) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *TransformSpec) GetFactor() float64 {
+	if x != nil {
+		return x.Factor
+	}
+	return 0
+}
+
+func (x *TransformSpec) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ActiveWindow struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          uint64                 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	Until         uint64                 `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	Every         uint64                 `protobuf:"varint,3,opt,name=every,proto3" json:"every,omitempty"`
+	For           uint64                 `protobuf:"varint,4,opt,name=for,proto3" json:"for,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ActiveWindow) Reset() {
+	*x = ActiveWindow{}
+	mi := &file_driftpb_drift_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ActiveWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActiveWindow) ProtoMessage() {}
+
+func (x *ActiveWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActiveWindow.ProtoReflect.Descriptor instead.
+func (*ActiveWindow) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ActiveWindow) GetFrom() uint64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *ActiveWindow) GetUntil() uint64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *ActiveWindow) GetEvery() uint64 {
+	if x != nil {
+		return x.Every
+	}
+	return 0
+}
+
+func (x *ActiveWindow) GetFor() uint64 {
+	if x != nil {
+		return x.For
+	}
+	return 0
+}
+
+type Homeostasis struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinStd        float64                `protobuf:"fixed64,1,opt,name=min_std,json=minStd,proto3" json:"min_std,omitempty"`
+	MaxStd        float64                `protobuf:"fixed64,2,opt,name=max_std,json=maxStd,proto3" json:"max_std,omitempty"`
+	MaxMean       float64                `protobuf:"fixed64,3,opt,name=max_mean,json=maxMean,proto3" json:"max_mean,omitempty"`
+	Rate          float64                `protobuf:"fixed64,4,opt,name=rate,proto3" json:"rate,omitempty"`
+	MinGain       float64                `protobuf:"fixed64,5,opt,name=min_gain,json=minGain,proto3" json:"min_gain,omitempty"`
+	MaxGain       float64                `protobuf:"fixed64,6,opt,name=max_gain,json=maxGain,proto3" json:"max_gain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Homeostasis) Reset() {
+	*x = Homeostasis{}
+	mi := &file_driftpb_drift_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Homeostasis) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Homeostasis) ProtoMessage() {}
+
+func (x *Homeostasis) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Homeostasis.ProtoReflect.Descriptor instead.
+func (*Homeostasis) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Homeostasis) GetMinStd() float64 {
+	if x != nil {
+		return x.MinStd
+	}
+	return 0
+}
+
+func (x *Homeostasis) GetMaxStd() float64 {
+	if x != nil {
+		return x.MaxStd
+	}
+	return 0
+}
+
+func (x *Homeostasis) GetMaxMean() float64 {
+	if x != nil {
+		return x.MaxMean
+	}
+	return 0
+}
+
+func (x *Homeostasis) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *Homeostasis) GetMinGain() float64 {
+	if x != nil {
+		return x.MinGain
+	}
+	return 0
+}
+
+func (x *Homeostasis) GetMaxGain() float64 {
+	if x != nil {
+		return x.MaxGain
+	}
+	return 0
+}
+
+type Range struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Min           float64                `protobuf:"fixed64,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max           float64                `protobuf:"fixed64,2,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Range) Reset() {
+	*x = Range{}
+	mi := &file_driftpb_drift_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Range) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Range) ProtoMessage() {}
+
+func (x *Range) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Range.ProtoReflect.Descriptor instead.
+func (*Range) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Range) GetMin() float64 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *Range) GetMax() float64 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+type RangeAdapter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transfer      string                 `protobuf:"bytes,1,opt,name=transfer,proto3" json:"transfer,omitempty"`
+	Source        *Range                 `protobuf:"bytes,2,opt,name=source,proto3" json:"source,omitempty"`
+	Target        *Range                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"` // Always written
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RangeAdapter) Reset() {
+	*x = RangeAdapter{}
+	mi := &file_driftpb_drift_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RangeAdapter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeAdapter) ProtoMessage() {}
+
+func (x *RangeAdapter) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeAdapter.ProtoReflect.Descriptor instead.
+func (*RangeAdapter) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RangeAdapter) GetTransfer() string {
+	if x != nil {
+		return x.Transfer
+	}
+	return ""
+}
+
+func (x *RangeAdapter) GetSource() *Range {
+	if x != nil {
+		return x.Source
+	}
+	return nil
+}
+
+func (x *RangeAdapter) GetTarget() *Range {
+	if x != nil {
+		return x.Target
+	}
+	return nil
+}
+
+type InputSegments struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Segments      []*InputSegment        `protobuf:"bytes,1,rep,name=segments,proto3" json:"segments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InputSegments) Reset() {
+	*x = InputSegments{}
+	mi := &file_driftpb_drift_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InputSegments) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputSegments) ProtoMessage() {}
+
+func (x *InputSegments) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputSegments.ProtoReflect.Descriptor instead.
+func (*InputSegments) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *InputSegments) GetSegments() []*InputSegment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+type InputSegment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	Fill          string                 `protobuf:"bytes,4,opt,name=fill,proto3" json:"fill,omitempty"`
+	FillValue     float32                `protobuf:"fixed32,5,opt,name=fill_value,json=fillValue,proto3" json:"fill_value,omitempty"`
+	Embedding     []float32              `protobuf:"fixed32,6,rep,packed,name=embedding,proto3" json:"embedding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InputSegment) Reset() {
+	*x = InputSegment{}
+	mi := &file_driftpb_drift_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InputSegment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InputSegment) ProtoMessage() {}
+
+func (x *InputSegment) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InputSegment.ProtoReflect.Descriptor instead.
+func (*InputSegment) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *InputSegment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *InputSegment) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *InputSegment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *InputSegment) GetFill() string {
+	if x != nil {
+		return x.Fill
+	}
+	return ""
+}
+
+func (x *InputSegment) GetFillValue() float32 {
+	if x != nil {
+		return x.FillValue
+	}
+	return 0
+}
+
+func (x *InputSegment) GetEmbedding() []float32 {
+	if x != nil {
+		return x.Embedding
+	}
+	return nil
+}
+
+type ResourceHints struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MaxLatencyMs  float64                `protobuf:"fixed64,1,opt,name=max_latency_ms,json=maxLatencyMs,proto3" json:"max_latency_ms,omitempty"`
+	MemoryClass   string                 `protobuf:"bytes,2,opt,name=memory_class,json=memoryClass,proto3" json:"memory_class,omitempty"`
+	RequiresFp32  bool                   `protobuf:"varint,3,opt,name=requires_fp32,json=requiresFp32,proto3" json:"requires_fp32,omitempty"`
+	DeviceClass   string                 `protobuf:"bytes,4,opt,name=device_class,json=deviceClass,proto3" json:"device_class,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceHints) Reset() {
+	*x = ResourceHints{}
+	mi := &file_driftpb_drift_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceHints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceHints) ProtoMessage() {}
+
+func (x *ResourceHints) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceHints.ProtoReflect.Descriptor instead.
+func (*ResourceHints) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ResourceHints) GetMaxLatencyMs() float64 {
+	if x != nil {
+		return x.MaxLatencyMs
+	}
+	return 0
+}
+
+func (x *ResourceHints) GetMemoryClass() string {
+	if x != nil {
+		return x.MemoryClass
+	}
+	return ""
+}
+
+func (x *ResourceHints) GetRequiresFp32() bool {
+	if x != nil {
+		return x.RequiresFp32
+	}
+	return false
+}
+
+func (x *ResourceHints) GetDeviceClass() string {
+	if x != nil {
+		return x.DeviceClass
+	}
+	return ""
+}
+
+type ModelMetadata struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []string               `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	Description   string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	Created       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created,proto3" json:"created,omitempty"` // Unset for the zero time
+	Updated       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=updated,proto3" json:"updated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelMetadata) Reset() {
+	*x = ModelMetadata{}
+	mi := &file_driftpb_drift_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelMetadata) ProtoMessage() {}
+
+func (x *ModelMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelMetadata.ProtoReflect.Descriptor instead.
+func (*ModelMetadata) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ModelMetadata) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ModelMetadata) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *ModelMetadata) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ModelMetadata) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ModelMetadata) GetCreated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Created
+	}
+	return nil
+}
+
+func (x *ModelMetadata) GetUpdated() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Updated
+	}
+	return nil
+}
+
+type Intervention struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	AtStep uint64                 `protobuf:"varint,1,opt,name=at_step,json=atStep,proto3" json:"at_step,omitempty"`
+	Action string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	Target string                 `protobuf:"bytes,3,opt,name=target,proto3" json:"target,omitempty"`
+	Value  float64                `protobuf:"fixed64,4,opt,name=value,proto3" json:"value,omitempty"`
+	// Free-form arguments for custom actions, as compact JSON.
+	Params        []byte `protobuf:"bytes,5,opt,name=params,proto3" json:"params,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Intervention) Reset() {
+	*x = Intervention{}
+	mi := &file_driftpb_drift_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Intervention) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Intervention) ProtoMessage() {}
+
+func (x *Intervention) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Intervention.ProtoReflect.Descriptor instead.
+func (*Intervention) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Intervention) GetAtStep() uint64 {
+	if x != nil {
+		return x.AtStep
+	}
+	return 0
+}
+
+func (x *Intervention) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *Intervention) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Intervention) GetValue() float64 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *Intervention) GetParams() []byte {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+type TrainingPhase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Train         []string               `protobuf:"bytes,2,rep,name=train,proto3" json:"train,omitempty"`
+	Steps         uint64                 `protobuf:"varint,3,opt,name=steps,proto3" json:"steps,omitempty"`
+	Duration      string                 `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	Lr            float32                `protobuf:"fixed32,5,opt,name=lr,proto3" json:"lr,omitempty"`
+	CoTrain       string                 `protobuf:"bytes,6,opt,name=co_train,json=coTrain,proto3" json:"co_train,omitempty"`
+	SourceCredit  float64                `protobuf:"fixed64,7,opt,name=source_credit,json=sourceCredit,proto3" json:"source_credit,omitempty"`
+	TargetCredit  float64                `protobuf:"fixed64,8,opt,name=target_credit,json=targetCredit,proto3" json:"target_credit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrainingPhase) Reset() {
+	*x = TrainingPhase{}
+	mi := &file_driftpb_drift_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrainingPhase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrainingPhase) ProtoMessage() {}
+
+func (x *TrainingPhase) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrainingPhase.ProtoReflect.Descriptor instead.
+func (*TrainingPhase) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *TrainingPhase) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TrainingPhase) GetTrain() []string {
+	if x != nil {
+		return x.Train
+	}
+	return nil
+}
+
+func (x *TrainingPhase) GetSteps() uint64 {
+	if x != nil {
+		return x.Steps
+	}
+	return 0
+}
+
+func (x *TrainingPhase) GetDuration() string {
+	if x != nil {
+		return x.Duration
+	}
+	return ""
+}
+
+func (x *TrainingPhase) GetLr() float32 {
+	if x != nil {
+		return x.Lr
+	}
+	return 0
+}
+
+func (x *TrainingPhase) GetCoTrain() string {
+	if x != nil {
+		return x.CoTrain
+	}
+	return ""
+}
+
+func (x *TrainingPhase) GetSourceCredit() float64 {
+	if x != nil {
+		return x.SourceCredit
+	}
+	return 0
+}
+
+func (x *TrainingPhase) GetTargetCredit() float64 {
+	if x != nil {
+		return x.TargetCredit
+	}
+	return 0
+}
+
+type Probe struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Model         string                 `protobuf:"bytes,2,opt,name=model,proto3" json:"model,omitempty"`
+	Layer         int64                  `protobuf:"varint,3,opt,name=layer,proto3" json:"layer,omitempty"`
+	Link          string                 `protobuf:"bytes,4,opt,name=link,proto3" json:"link,omitempty"`
+	Every         uint64                 `protobuf:"varint,5,opt,name=every,proto3" json:"every,omitempty"`
+	Reduce        string                 `protobuf:"bytes,6,opt,name=reduce,proto3" json:"reduce,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Probe) Reset() {
+	*x = Probe{}
+	mi := &file_driftpb_drift_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Probe) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Probe) ProtoMessage() {}
+
+func (x *Probe) ProtoReflect() protoreflect.Message {
+	mi := &file_driftpb_drift_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Probe.ProtoReflect.Descriptor instead.
+func (*Probe) Descriptor() ([]byte, []int) {
+	return file_driftpb_drift_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Probe) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Probe) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *Probe) GetLayer() int64 {
+	if x != nil {
+		return x.Layer
+	}
+	return 0
+}
+
+func (x *Probe) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *Probe) GetEvery() uint64 {
+	if x != nil {
+		return x.Every
+	}
+	return 0
+}
+
+func (x *Probe) GetReduce() string {
+	if x != nil {
+		return x.Reduce
+	}
+	return ""
+}
+
+var File_driftpb_drift_proto protoreflect.FileDescriptor
+
+const file_driftpb_drift_proto_rawDesc = "" +
+	"\n" +
+	"\x13driftpb/drift.proto\x12\bdrift.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd4\x06\n" +
+	"\x06Config\x12%\n" +
+	"\x0eschema_version\x18\x01 \x01(\x03R\rschemaVersion\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04seed\x18\x03 \x01(\x03R\x04seed\x12\x14\n" +
+	"\x05dtype\x18\x04 \x01(\tR\x05dtype\x124\n" +
+	"\x06models\x18\x05 \x03(\v2\x1c.drift.v1.Config.ModelsEntryR\x06models\x124\n" +
+	"\x06inputs\x18\x06 \x03(\v2\x1c.drift.v1.Config.InputsEntryR\x06inputs\x12=\n" +
+	"\tresources\x18\a \x03(\v2\x1f.drift.v1.Config.ResourcesEntryR\tresources\x12:\n" +
+	"\bmetadata\x18\b \x03(\v2\x1e.drift.v1.Config.MetadataEntryR\bmetadata\x120\n" +
+	"\x05links\x18\t \x03(\v2\x1a.drift.v1.NeuralLinkConfigR\x05links\x122\n" +
+	"\bscenario\x18\n" +
+	" \x03(\v2\x16.drift.v1.InterventionR\bscenario\x123\n" +
+	"\btraining\x18\v \x03(\v2\x17.drift.v1.TrainingPhaseR\btraining\x12'\n" +
+	"\x06probes\x18\f \x03(\v2\x0f.drift.v1.ProbeR\x06probes\x1a9\n" +
+	"\vModelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\x1aR\n" +
+	"\vInputsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.drift.v1.InputSegmentsR\x05value:\x028\x01\x1aU\n" +
+	"\x0eResourcesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.drift.v1.ResourceHintsR\x05value:\x028\x01\x1aT\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12-\n" +
+	"\x05value\x18\x02 \x01(\v2\x17.drift.v1.ModelMetadataR\x05value:\x028\x01\"\x98\x05\n" +
+	"\x10NeuralLinkConfig\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12!\n" +
+	"\fsource_model\x18\x03 \x01(\tR\vsourceModel\x12!\n" +
+	"\fsource_layer\x18\x04 \x01(\x03R\vsourceLayer\x12!\n" +
+	"\ftarget_model\x18\x05 \x01(\tR\vtargetModel\x12#\n" +
+	"\rtarget_offset\x18\x06 \x01(\x03R\ftargetOffset\x12\x1b\n" +
+	"\tlink_size\x18\a \x01(\x03R\blinkSize\x12\x18\n" +
+	"\aenabled\x18\b \x01(\bR\aenabled\x12 \n" +
+	"\vdescription\x18\t \x01(\tR\vdescription\x12\x14\n" +
+	"\x05group\x18\n" +
+	" \x01(\tR\x05group\x12\x12\n" +
+	"\x04tags\x18\v \x03(\tR\x04tags\x12.\n" +
+	"\x06active\x18\f \x03(\v2\x16.drift.v1.ActiveWindowR\x06active\x12\x1a\n" +
+	"\bdelivery\x18\r \x01(\x01R\bdelivery\x12%\n" +
+	"\x0elearn_delivery\x18\x0e \x01(\bR\rlearnDelivery\x127\n" +
+	"\vhomeostasis\x18\x0f \x01(\v2\x15.drift.v1.HomeostasisR\vhomeostasis\x12,\n" +
+	"\x05range\x18\x10 \x01(\v2\x16.drift.v1.RangeAdapterR\x05range\x12\x1a\n" +
+	"\bchecksum\x18\x11 \x01(\bR\bchecksum\x127\n" +
+	"\n" +
+	"transforms\x18\x12 \x03(\v2\x17.drift.v1.TransformSpecR\n" +
+	"transforms\x12\x17\n" +
+	"\x04gain\x18\x13 \x01(\x01H\x00R\x04gain\x88\x01\x01B\a\n" +
+	"\x05_gain\"w\n" +
+	"\rTransformSpec\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x10\n" +
+	"\x03min\x18\x02 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x03 \x01(\x01R\x03max\x12\x16\n" +
+	"\x06factor\x18\x04 \x01(\x01R\x06factor\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x01R\x06offset\"`\n" +
+	"\fActiveWindow\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x04R\x04from\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\x04R\x05until\x12\x14\n" +
+	"\x05every\x18\x03 \x01(\x04R\x05every\x12\x10\n" +
+	"\x03for\x18\x04 \x01(\x04R\x03for\"\xa4\x01\n" +
+	"\vHomeostasis\x12\x17\n" +
+	"\amin_std\x18\x01 \x01(\x01R\x06minStd\x12\x17\n" +
+	"\amax_std\x18\x02 \x01(\x01R\x06maxStd\x12\x19\n" +
+	"\bmax_mean\x18\x03 \x01(\x01R\amaxMean\x12\x12\n" +
+	"\x04rate\x18\x04 \x01(\x01R\x04rate\x12\x19\n" +
+	"\bmin_gain\x18\x05 \x01(\x01R\aminGain\x12\x19\n" +
+	"\bmax_gain\x18\x06 \x01(\x01R\amaxGain\"+\n" +
+	"\x05Range\x12\x10\n" +
+	"\x03min\x18\x01 \x01(\x01R\x03min\x12\x10\n" +
+	"\x03max\x18\x02 \x01(\x01R\x03max\"|\n" +
+	"\fRangeAdapter\x12\x1a\n" +
+	"\btransfer\x18\x01 \x01(\tR\btransfer\x12'\n" +
+	"\x06source\x18\x02 \x01(\v2\x0f.drift.v1.RangeR\x06source\x12'\n" +
+	"\x06target\x18\x03 \x01(\v2\x0f.drift.v1.RangeR\x06target\"C\n" +
+	"\rInputSegments\x122\n" +
+	"\bsegments\x18\x01 \x03(\v2\x16.drift.v1.InputSegmentR\bsegments\"\x9f\x01\n" +
+	"\fInputSegment\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04fill\x18\x04 \x01(\tR\x04fill\x12\x1d\n" +
+	"\n" +
+	"fill_value\x18\x05 \x01(\x02R\tfillValue\x12\x1c\n" +
+	"\tembedding\x18\x06 \x03(\x02R\tembedding\"\xa0\x01\n" +
+	"\rResourceHints\x12$\n" +
+	"\x0emax_latency_ms\x18\x01 \x01(\x01R\fmaxLatencyMs\x12!\n" +
+	"\fmemory_class\x18\x02 \x01(\tR\vmemoryClass\x12#\n" +
+	"\rrequires_fp32\x18\x03 \x01(\bR\frequiresFp32\x12!\n" +
+	"\fdevice_class\x18\x04 \x01(\tR\vdeviceClass\"\xe3\x01\n" +
+	"\rModelMetadata\x12\x12\n" +
+	"\x04tags\x18\x01 \x03(\tR\x04tags\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x124\n" +
+	"\acreated\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\acreated\x124\n" +
+	"\aupdated\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\aupdated\"\x85\x01\n" +
+	"\fIntervention\x12\x17\n" +
+	"\aat_step\x18\x01 \x01(\x04R\x06atStep\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12\x16\n" +
+	"\x06target\x18\x03 \x01(\tR\x06target\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\x01R\x05value\x12\x16\n" +
+	"\x06params\x18\x05 \x01(\fR\x06params\"\xe0\x01\n" +
+	"\rTrainingPhase\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05train\x18\x02 \x03(\tR\x05train\x12\x14\n" +
+	"\x05steps\x18\x03 \x01(\x04R\x05steps\x12\x1a\n" +
+	"\bduration\x18\x04 \x01(\tR\bduration\x12\x0e\n" +
+	"\x02lr\x18\x05 \x01(\x02R\x02lr\x12\x19\n" +
+	"\bco_train\x18\x06 \x01(\tR\acoTrain\x12#\n" +
+	"\rsource_credit\x18\a \x01(\x01R\fsourceCredit\x12#\n" +
+	"\rtarget_credit\x18\b \x01(\x01R\ftargetCredit\"\x89\x01\n" +
+	"\x05Probe\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05model\x18\x02 \x01(\tR\x05model\x12\x14\n" +
+	"\x05layer\x18\x03 \x01(\x03R\x05layer\x12\x12\n" +
+	"\x04link\x18\x04 \x01(\tR\x04link\x12\x14\n" +
+	"\x05every\x18\x05 \x01(\x04R\x05every\x12\x16\n" +
+	"\x06reduce\x18\x06 \x01(\tR\x06reduceB$Z\"github.com/openfluke/drift/driftpbb\x06proto3"
+
+var (
+	file_driftpb_drift_proto_rawDescOnce sync.Once
+	file_driftpb_drift_proto_rawDescData []byte
+)
+
+func file_driftpb_drift_proto_rawDescGZIP() []byte {
+	file_driftpb_drift_proto_rawDescOnce.Do(func() {
+		file_driftpb_drift_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_driftpb_drift_proto_rawDesc), len(file_driftpb_drift_proto_rawDesc)))
+	})
+	return file_driftpb_drift_proto_rawDescData
+}
+
+var file_driftpb_drift_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_driftpb_drift_proto_goTypes = []any{
+	(*Config)(nil),                // 0: drift.v1.Config
+	(*NeuralLinkConfig)(nil),      // 1: drift.v1.NeuralLinkConfig
+	(*TransformSpec)(nil),         // 2: drift.v1.TransformSpec
+	(*ActiveWindow)(nil),          // 3: drift.v1.ActiveWindow
+	(*Homeostasis)(nil),           // 4: drift.v1.Homeostasis
+	(*Range)(nil),                 // 5: drift.v1.Range
+	(*RangeAdapter)(nil),          // 6: drift.v1.RangeAdapter
+	(*InputSegments)(nil),         // 7: drift.v1.InputSegments
+	(*InputSegment)(nil),          // 8: drift.v1.InputSegment
+	(*ResourceHints)(nil),         // 9: drift.v1.ResourceHints
+	(*ModelMetadata)(nil),         // 10: drift.v1.ModelMetadata
+	(*Intervention)(nil),          // 11: drift.v1.Intervention
+	(*TrainingPhase)(nil),         // 12: drift.v1.TrainingPhase
+	(*Probe)(nil),                 // 13: drift.v1.Probe
+	nil,                           // 14: drift.v1.Config.ModelsEntry
+	nil,                           // 15: drift.v1.Config.InputsEntry
+	nil,                           // 16: drift.v1.Config.ResourcesEntry
+	nil,                           // 17: drift.v1.Config.MetadataEntry
+	(*timestamppb.Timestamp)(nil), // 18: google.protobuf.Timestamp
+}
+var file_driftpb_drift_proto_depIdxs = []int32{
+	14, // 0: drift.v1.Config.models:type_name -> drift.v1.Config.ModelsEntry
+	15, // 1: drift.v1.Config.inputs:type_name -> drift.v1.Config.InputsEntry
+	16, // 2: drift.v1.Config.resources:type_name -> drift.v1.Config.ResourcesEntry
+	17, // 3: drift.v1.Config.metadata:type_name -> drift.v1.Config.MetadataEntry
+	1,  // 4: drift.v1.Config.links:type_name -> drift.v1.NeuralLinkConfig
+	11, // 5: drift.v1.Config.scenario:type_name -> drift.v1.Intervention
+	12, // 6: drift.v1.Config.training:type_name -> drift.v1.TrainingPhase
+	13, // 7: drift.v1.Config.probes:type_name -> drift.v1.Probe
+	3,  // 8: drift.v1.NeuralLinkConfig.active:type_name -> drift.v1.ActiveWindow
+	4,  // 9: drift.v1.NeuralLinkConfig.homeostasis:type_name -> drift.v1.Homeostasis
+	6,  // 10: drift.v1.NeuralLinkConfig.range:type_name -> drift.v1.RangeAdapter
+	2,  // 11: drift.v1.NeuralLinkConfig.transforms:type_name -> drift.v1.TransformSpec
+	5,  // 12: drift.v1.RangeAdapter.source:type_name -> drift.v1.Range
+	5,  // 13: drift.v1.RangeAdapter.target:type_name -> drift.v1.Range
+	8,  // 14: drift.v1.InputSegments.segments:type_name -> drift.v1.InputSegment
+	18, // 15: drift.v1.ModelMetadata.created:type_name -> google.protobuf.Timestamp
+	18, // 16: drift.v1.ModelMetadata.updated:type_name -> google.protobuf.Timestamp
+	7,  // 17: drift.v1.Config.InputsEntry.value:type_name -> drift.v1.InputSegments
+	9,  // 18: drift.v1.Config.ResourcesEntry.value:type_name -> drift.v1.ResourceHints
+	10, // 19: drift.v1.Config.MetadataEntry.value:type_name -> drift.v1.ModelMetadata
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
+}
+
+func init() { file_driftpb_drift_proto_init() }
+func file_driftpb_drift_proto_init() {
+	if File_driftpb_drift_proto != nil {
+		return
+	}
+	file_driftpb_drift_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_driftpb_drift_proto_rawDesc), len(file_driftpb_drift_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_driftpb_drift_proto_goTypes,
+		DependencyIndexes: file_driftpb_drift_proto_depIdxs,
+		MessageInfos:      file_driftpb_drift_proto_msgTypes,
+	}.Build()
+	File_driftpb_drift_proto = out.File
+	file_driftpb_drift_proto_goTypes = nil
+	file_driftpb_drift_proto_depIdxs = nil
+}
@@ -0,0 +1,44 @@
+// Package drifttest provides helpers for testing code built on drift.
+package drifttest
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/openfluke/drift"
+)
+
+// UpdateEnv names the environment variable that makes AssertGolden rewrite
+// golden files instead of checking them.
+const UpdateEnv = "DRIFT_UPDATE_GOLDEN"
+
+// AssertGolden checks that cfg still produces the outputs recorded in the
+// golden file at path, within tol. When the file does not exist, or UpdateEnv
+// is set to a non-empty value, it is (re)generated by running steps steps on
+// inputs and the test passes.
+func AssertGolden(t testing.TB, cfg *drift.Config, path string, steps int, inputs func(step int) map[string][]float32, tol float64) {
+	t.Helper()
+
+	_, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) || os.Getenv(UpdateEnv) != "" {
+		records, err := drift.GenerateGolden(cfg, steps, inputs)
+		if err != nil {
+			t.Fatalf("generate golden %s: %v", path, err)
+		}
+		if err := drift.WriteGolden(path, records); err != nil {
+			t.Fatalf("write golden %s: %v", path, err)
+		}
+		t.Logf("wrote golden %s (%d steps)", path, len(records))
+		return
+	}
+
+	d, err := drift.CheckGolden(cfg, path, tol)
+	if err != nil {
+		t.Fatalf("check golden %s: %v", path, err)
+	}
+	if d != nil {
+		t.Errorf("golden %s: %v (set %s=1 to accept)", path, d, UpdateEnv)
+	}
+}
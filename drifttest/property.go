@@ -0,0 +1,174 @@
+package drifttest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/openfluke/drift"
+)
+
+// Property is an invariant checked after every step of a property run.
+type Property struct {
+	Name  string
+	Check func(r *drift.Runtime, inputs, outputs map[string][]float32) error
+}
+
+// Options configures CheckProperties.
+type Options struct {
+	Trials int   // Independent runtimes to try; defaults to 50
+	Steps  int   // Steps per trial; defaults to 5
+	Seed   int64 // Seeds input generation; failures report it for reproduction
+	// Input generates one observation for model. Defaults to values drawn
+	// uniformly from [-1, 1].
+	Input func(rng drift.Rand, model string, size int) []float32
+	// Setup, if set, runs on every fresh runtime before its first step.
+	Setup func(r *drift.Runtime) error
+}
+
+// CheckProperties builds a runtime from cfg for every trial, steps it on
+// generated observations and checks each property after every step. A
+// panic inside the runtime is reported as a failure of the trial rather
+// than crashing the test binary.
+func CheckProperties(t testing.TB, cfg *drift.Config, opts Options, props ...Property) {
+	t.Helper()
+	trials, steps := opts.Trials, opts.Steps
+	if trials <= 0 {
+		trials = 50
+	}
+	if steps <= 0 {
+		steps = 5
+	}
+	gen := opts.Input
+	if gen == nil {
+		gen = uniformInput
+	}
+	rng := drift.NewSeededSource(opts.Seed).Stream(drift.StreamEnvironment)
+
+	for trial := 0; trial < trials; trial++ {
+		if err := runTrial(cfg, opts.Setup, gen, rng, steps, props); err != nil {
+			t.Fatalf("trial %d (seed %d): %v", trial, opts.Seed, err)
+		}
+	}
+}
+
+func runTrial(cfg *drift.Config, setup func(*drift.Runtime) error, gen func(drift.Rand, string, int) []float32, rng drift.Rand, steps int, props []Property) (err error) {
+	var inputs map[string][]float32
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v (inputs %v)", p, inputs)
+		}
+	}()
+
+	r, err := drift.NewRuntime(cfg)
+	if err != nil {
+		return err
+	}
+	if setup != nil {
+		if err := setup(r); err != nil {
+			return fmt.Errorf("setup: %w", err)
+		}
+	}
+	for step := 0; step < steps; step++ {
+		inputs = make(map[string][]float32)
+		for _, name := range r.Models() {
+			inputs[name] = gen(rng, name, len(r.Input(name)))
+		}
+		outputs, err := r.Step(inputs)
+		if err != nil {
+			return fmt.Errorf("step %d: %w", step, err)
+		}
+		for _, p := range props {
+			if err := p.Check(r, inputs, outputs); err != nil {
+				return fmt.Errorf("step %d: %s: %w (inputs %v)", step, p.Name, err, inputs)
+			}
+		}
+	}
+	return nil
+}
+
+func uniformInput(rng drift.Rand, _ string, size int) []float32 {
+	v := make([]float32, size)
+	for i := range v {
+		v[i] = rng.Float32()*2 - 1
+	}
+	return v
+}
+
+// DisableAllLinks is a Setup that disables every link, for properties such
+// as "the swarm still runs with all links cut".
+func DisableAllLinks(r *drift.Runtime) error {
+	for _, l := range r.Links() {
+		if err := r.SetLinkEnabled(l.Name, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Simplex requires model's output to be a probability distribution:
+// non-negative and summing to 1 within tol.
+func Simplex(model string, tol float64) Property {
+	return Property{
+		Name: model + " output is a probability simplex",
+		Check: func(_ *drift.Runtime, _, outputs map[string][]float32) error {
+			var sum float64
+			for i, v := range outputs[model] {
+				if v < 0 {
+					return fmt.Errorf("output[%d] = %g is negative", i, v)
+				}
+				sum += float64(v)
+			}
+			if math.Abs(sum-1) > tol {
+				return fmt.Errorf("outputs sum to %g", sum)
+			}
+			return nil
+		},
+	}
+}
+
+// Finite requires every model output and link payload to be free of NaN
+// and Inf.
+func Finite() Property {
+	return Property{
+		Name: "outputs and link payloads are finite",
+		Check: func(r *drift.Runtime, _, outputs map[string][]float32) error {
+			for name, out := range outputs {
+				if i := firstNonFinite(out); i >= 0 {
+					return fmt.Errorf("model %s output[%d] = %g", name, i, out[i])
+				}
+			}
+			for _, l := range r.Links() {
+				p := r.LinkPayload(l.Name)
+				if i := firstNonFinite(p); i >= 0 {
+					return fmt.Errorf("link %s payload[%d] = %g", l.Name, i, p[i])
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// InRange requires every element of model's output to lie in [lo, hi].
+func InRange(model string, lo, hi float32) Property {
+	return Property{
+		Name: fmt.Sprintf("%s output in [%g, %g]", model, lo, hi),
+		Check: func(_ *drift.Runtime, _, outputs map[string][]float32) error {
+			for i, v := range outputs[model] {
+				if v < lo || v > hi {
+					return fmt.Errorf("output[%d] = %g", i, v)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func firstNonFinite(s []float32) int {
+	for i, v := range s {
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package drift
+
+import (
+	"errors"
+	"fmt"
+)
+
+// DType names the numeric type models and link payloads compute in. Only
+// Float32 runs today: Float64 is part of the schema so configs can declare
+// it ahead of backend support, but ValidateDType rejects it until loom
+// computes in float64.
+type DType string
+
+const (
+	Float32 DType = "float32"
+	Float64 DType = "float64" // Accepted by the schema; not yet runnable
+)
+
+// ErrUnsupportedDType is returned when a config declares a dtype the model
+// backend cannot run in.
+var ErrUnsupportedDType = errors.New("drift: unsupported dtype")
+
+// backendDTypes lists the dtypes the loom backend computes in.
+var backendDTypes = []DType{Float32}
+
+// Number is the set of element types accepted by the generic helpers.
+type Number interface {
+	~float32 | ~float64
+}
+
+// ConvertSlice converts s element by element.
+func ConvertSlice[To, From Number](s []From) []To {
+	if s == nil {
+		return nil
+	}
+	out := make([]To, len(s))
+	for i, v := range s {
+		out[i] = To(v)
+	}
+	return out
+}
+
+// EffectiveDType returns the declared dtype, defaulting to Float32.
+func (c *Config) EffectiveDType() DType {
+	if c.DType == "" {
+		return Float32
+	}
+	return c.DType
+}
+
+// ValidateDType checks that the declared dtype is known and that the backend
+// can run it end-to-end.
+func (c *Config) ValidateDType() error {
+	dt := c.EffectiveDType()
+	if dt != Float32 && dt != Float64 {
+		return fmt.Errorf("%w %q", ErrUnsupportedDType, dt)
+	}
+	for _, b := range backendDTypes {
+		if b == dt {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w %q: backend computes in %v", ErrUnsupportedDType, dt, backendDTypes)
+}
+
+// StepAs runs one step with inputs and outputs of element type T, converting
+// at the runtime boundary. It lets float64 control code drive a runtime
+// without hand-written conversions; precision inside the models is still the
+// config's dtype.
+func StepAs[T Number](r *Runtime, inputs map[string][]T) (map[string][]T, error) {
+	in := make(map[string][]float32, len(inputs))
+	for name, v := range inputs {
+		in[name] = ConvertSlice[float32](v)
+	}
+	out, err := r.Step(in)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[string][]T, len(out))
+	for name, v := range out {
+		res[name] = ConvertSlice[T](v)
+	}
+	return res, nil
+}
@@ -0,0 +1,166 @@
+package drift
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const encryptedConfigType = "drift/encrypted-config"
+
+// passphraseIterations is the PBKDF2-SHA256 work factor for Passphrase keys.
+const passphraseIterations = 600000
+
+// ErrConfigEncrypted is returned by LoadFromFile for configs saved with
+// SaveToFileEncrypted; load them with LoadFromFileWithKey.
+var ErrConfigEncrypted = errors.New("drift: config is encrypted")
+
+// ErrDecrypt is returned when an encrypted config can't be decrypted, either
+// because the key is wrong or because the file was altered.
+var ErrDecrypt = errors.New("drift: config decryption failed")
+
+// KeyProvider supplies the AES-256 key for an encrypted config. salt is
+// random per file, for providers that derive keys; others may ignore it.
+type KeyProvider interface {
+	ConfigKey(salt []byte) ([]byte, error)
+}
+
+// StaticKey is a KeyProvider holding a raw 32-byte key.
+type StaticKey []byte
+
+func (k StaticKey) ConfigKey([]byte) ([]byte, error) {
+	if len(k) != 32 {
+		return nil, fmt.Errorf("AES-256 key must be 32 bytes, got %d", len(k))
+	}
+	return k, nil
+}
+
+// Passphrase is a KeyProvider deriving the key from a passphrase with
+// PBKDF2-SHA256.
+type Passphrase string
+
+func (p Passphrase) ConfigKey(salt []byte) ([]byte, error) {
+	if p == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	return pbkdf2.Key(sha256.New, string(p), salt, passphraseIterations, 32)
+}
+
+// encryptedConfig is the envelope written by SaveToFileEncrypted. The
+// ciphertext is the config's JSON sealed with AES-256-GCM.
+type encryptedConfig struct {
+	Type       string `json:"type"`
+	Cipher     string `json:"cipher"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// SaveToFileEncrypted saves the config to path encrypted with AES-256-GCM
+// under the key from keys. The file is readable only by its owner.
+func (c *Config) SaveToFileEncrypted(path string, keys KeyProvider) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	env := encryptedConfig{Type: encryptedConfigType, Cipher: "aes-256-gcm", Salt: make([]byte, 16)}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return err
+	}
+	aead, err := configCipher(keys, env.Salt)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return err
+	}
+	env.Ciphertext = aead.Seal(nil, env.Nonce, data, []byte(encryptedConfigType))
+	out, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, append(out, '\n'), 0600, false)
+	})
+}
+
+// LoadFromFileWithKey loads a config like LoadFromFile, first decrypting it
+// with the key from keys if it was saved with SaveToFileEncrypted. Plain
+// configs load as usual, and files they include are read unencrypted.
+func LoadFromFileWithKey(path string, keys KeyProvider) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isEncryptedConfig(data) {
+		if data, err = decryptConfig(data, keys); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	c, err := loadData(data, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.ApplyEnvOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func decryptConfig(data []byte, keys KeyProvider) ([]byte, error) {
+	var env encryptedConfig
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Cipher != "aes-256-gcm" {
+		return nil, fmt.Errorf("unsupported cipher %q", env.Cipher)
+	}
+	aead, err := configCipher(keys, env.Salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plain, err := aead.Open(nil, env.Nonce, env.Ciphertext, []byte(encryptedConfigType))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
+
+func configCipher(keys KeyProvider, salt []byte) (cipher.AEAD, error) {
+	key, err := keys.ConfigKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isEncryptedConfig reports whether data is an encrypted config envelope.
+func isEncryptedConfig(data []byte) bool {
+	if !bytes.Contains(data, []byte(encryptedConfigType)) {
+		return false
+	}
+	var env struct {
+		Type string `json:"type"`
+	}
+	return json.Unmarshal(data, &env) == nil && env.Type == encryptedConfigType
+}
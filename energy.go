@@ -0,0 +1,165 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// layerMACs estimates the multiply-accumulates one forward pass of a layer
+// costs. Element-wise layers count one operation per value.
+func layerMACs(def nn.LayerDefinition, batch int) int64 {
+	b := int64(batch)
+	seq := int64(max(def.SeqLength, 1))
+	switch def.Type {
+	case "dense":
+		in := int64(firstPositive(def.Width, def.InputSize, def.InputHeight))
+		out := int64(firstPositive(def.OutputSize, def.Height))
+		return b * in * out
+	case "swiglu":
+		in := int64(firstPositive(def.InputSize, def.InputHeight))
+		hidden := int64(firstPositive(def.HiddenSize, def.OutputSize))
+		return b * 3 * in * hidden
+	case "rnn":
+		in, h := int64(def.InputSize), int64(def.HiddenSize)
+		return b * seq * (in*h + h*h)
+	case "lstm":
+		in, h := int64(def.InputSize), int64(def.HiddenSize)
+		return b * seq * 4 * (in*h + h*h)
+	case "mha", "multi_head_attention":
+		d := int64(def.DModel)
+		return b * (4*seq*d*d + 2*seq*seq*d)
+	case "conv2d":
+		stride := int64(max(def.Stride, 1))
+		k := int64(def.KernelSize)
+		outH, outW := int64(def.OutputHeight), int64(def.OutputWidth)
+		if outH == 0 && stride > 0 {
+			outH = (int64(def.InputHeight)+2*int64(def.Padding)-k)/stride + 1
+		}
+		if outW == 0 && stride > 0 {
+			outW = (int64(def.InputWidth)+2*int64(def.Padding)-k)/stride + 1
+		}
+		return b * outH * outW * int64(def.Filters) * int64(def.InputChannels) * k * k
+	case "layer_norm", "layernorm", "rms_norm", "rmsnorm":
+		return b * 2 * int64(def.NormSize)
+	case "parallel":
+		var sum int64
+		for _, br := range def.Branches {
+			sum += layerMACs(br, batch)
+		}
+		return sum
+	}
+	return 0
+}
+
+// ModelMACs estimates the multiply-accumulates one step of a model costs.
+func ModelMACs(raw json.RawMessage) (int64, error) {
+	spec, err := parseModel(raw)
+	if err != nil {
+		return 0, err
+	}
+	var sum int64
+	for _, def := range spec.Layers {
+		sum += layerMACs(def, spec.BatchSize)
+	}
+	return sum, nil
+}
+
+// EnergyModel prices compute per device class, in joules per MAC. A model's
+// device class is the DeviceClass of its resource hints.
+type EnergyModel struct {
+	JoulesPerMAC map[string]float64 `json:"joules_per_mac"`
+	Default      float64            `json:"default"` // For classes not listed
+}
+
+// EpisodeEnergy is the compute and energy spent during one episode.
+type EpisodeEnergy struct {
+	Episode int                `json:"episode"`
+	Steps   int                `json:"steps"`
+	MACs    map[string]int64   `json:"macs"`
+	Joules  map[string]float64 `json:"joules"`
+	Total   float64            `json:"total_joules"`
+}
+
+// Metrics converts the episode's energy to metrics stamped with the episode
+// number, one per model plus the total.
+func (e EpisodeEnergy) Metrics() []Metric {
+	step := uint64(e.Episode)
+	ms := []Metric{{Name: "energy_joules", Value: e.Total, Step: step}}
+	for _, model := range sortedKeys(e.Joules) {
+		ms = append(ms, Metric{Name: "energy_joules", Value: e.Joules[model], Step: step, Labels: map[string]string{"model": model}})
+	}
+	return ms
+}
+
+// EnergyMeter accumulates per-model compute and energy across the steps of
+// an episode.
+type EnergyMeter struct {
+	mu      sync.Mutex
+	macs    map[string]int64
+	jpm     map[string]float64
+	episode int
+	cur     EpisodeEnergy
+}
+
+// NewEnergyMeter estimates the per-step cost of every model in cfg.
+func NewEnergyMeter(cfg *Config, em EnergyModel) (*EnergyMeter, error) {
+	m := &EnergyMeter{macs: make(map[string]int64), jpm: make(map[string]float64)}
+	for name, raw := range cfg.Models {
+		macs, err := ModelMACs(raw)
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", name, err)
+		}
+		m.macs[name] = macs
+		jpm, ok := em.JoulesPerMAC[cfg.Resources[name].DeviceClass]
+		if !ok {
+			jpm = em.Default
+		}
+		m.jpm[name] = jpm
+	}
+	m.reset()
+	return m, nil
+}
+
+// StepMACs returns the estimated MACs per step of every model.
+func (m *EnergyMeter) StepMACs() map[string]int64 {
+	out := make(map[string]int64, len(m.macs))
+	for k, v := range m.macs {
+		out[k] = v
+	}
+	return out
+}
+
+// Step accounts for one runtime step, in which every model runs once.
+func (m *EnergyMeter) Step() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cur.Steps++
+	for name, macs := range m.macs {
+		m.cur.MACs[name] += macs
+		j := float64(macs) * m.jpm[name]
+		m.cur.Joules[name] += j
+		m.cur.Total += j
+	}
+}
+
+// EndEpisode returns the totals of the current episode and starts a new one.
+func (m *EnergyMeter) EndEpisode() EpisodeEnergy {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := m.cur
+	m.reset()
+	return e
+}
+
+// reset starts a new episode. The caller holds m.mu.
+func (m *EnergyMeter) reset() {
+	m.episode++
+	m.cur = EpisodeEnergy{
+		Episode: m.episode,
+		MACs:    make(map[string]int64, len(m.macs)),
+		Joules:  make(map[string]float64, len(m.macs)),
+	}
+}
@@ -0,0 +1,185 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts the names of environment variables that override config
+// fields.
+const envPrefix = "DRIFT_"
+
+// Override records one environment override applied to a config.
+type Override struct {
+	Var   string `json:"var"`
+	Field string `json:"field"` // e.g. "links[terrain_to_nav].enabled"
+	Value string `json:"value"`
+}
+
+// Overrides returns the environment overrides applied when the config was
+// loaded.
+func (c *Config) Overrides() []Override {
+	return c.overrides
+}
+
+// ApplyEnvOverrides applies the overrides found in environ, a list of
+// KEY=value strings as returned by os.Environ, in sorted order, and returns
+// the ones that took effect. LoadFromFile applies the process environment
+// this way, so deployments can flip links or swap models without editing
+// files. The recognized variables are:
+//
+//	DRIFT_SEED=<n>                       Config.Seed
+//	DRIFT_LINKS_<link>_<FIELD>=<value>   a link field, e.g.
+//	                                     DRIFT_LINKS_terrain_to_nav_ENABLED=false
+//	DRIFT_MODELS_<model>=<json>          a model definition
+//	DRIFT_MODELS_<model>_FILE=<path>     a model definition read from a file
+//
+// FIELD is the upper-cased JSON name of a NeuralLinkConfig field (ENABLED,
+// LINK_SIZE, SOURCE_LAYER, ...); TAGS takes a comma-separated list. Link and
+// model names are matched exactly, except that characters not allowed in
+// variable names may be written as underscores. An override naming a link,
+// field or model the config doesn't have is skipped with a warning (see
+// Config.Warnings), so one environment can serve several configs; other
+// variables, including other DRIFT_ ones, are ignored.
+func (c *Config) ApplyEnvOverrides(environ []string) ([]Override, error) {
+	env := append([]string(nil), environ...)
+	sort.Strings(env)
+	var applied []Override
+	for _, kv := range env {
+		key, value, _ := strings.Cut(kv, "=")
+		var (
+			field string
+			err   error
+		)
+		switch {
+		case key == envPrefix+"SEED":
+			field = "seed"
+			c.Seed, err = strconv.ParseInt(value, 10, 64)
+		case strings.HasPrefix(key, envPrefix+"LINKS_"):
+			field, err = c.overrideLink(strings.TrimPrefix(key, envPrefix+"LINKS_"), value)
+		case strings.HasPrefix(key, envPrefix+"MODELS_"):
+			field, err = c.overrideModel(strings.TrimPrefix(key, envPrefix+"MODELS_"), value)
+		default:
+			continue
+		}
+		if err != nil {
+			return applied, fmt.Errorf("%s: %w", key, err)
+		}
+		if field == "" {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s matches no link or model; ignored", key))
+			continue
+		}
+		applied = append(applied, Override{Var: key, Field: field, Value: value})
+	}
+	c.overrides = append(c.overrides, applied...)
+	return applied, nil
+}
+
+// overrideLink applies a DRIFT_LINKS_ override, returning the field it set,
+// or "" when no link and field match.
+func (c *Config) overrideLink(rest, value string) (string, error) {
+	t := reflect.TypeOf(NeuralLinkConfig{})
+	for i := range c.Links {
+		suffix, ok := strings.CutPrefix(rest, envName(c.Links[i].Name)+"_")
+		if !ok {
+			continue
+		}
+		for f := 0; f < t.NumField(); f++ {
+			jsonName, _, _ := strings.Cut(t.Field(f).Tag.Get("json"), ",")
+			if strings.ToUpper(jsonName) != suffix {
+				continue
+			}
+			if err := setFromString(reflect.ValueOf(&c.Links[i]).Elem().Field(f), value); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("links[%s].%s", c.Links[i].Name, jsonName), nil
+		}
+	}
+	return "", nil
+}
+
+// overrideModel applies a DRIFT_MODELS_ override, returning the field it
+// set, or "" when no model matches.
+func (c *Config) overrideModel(rest, value string) (string, error) {
+	for _, name := range sortedKeys(c.Models) {
+		field := fmt.Sprintf("models[%s]", name)
+		data := []byte(value)
+		switch rest {
+		case envName(name):
+		case envName(name) + "_FILE":
+			var err error
+			if data, err = os.ReadFile(value); err != nil {
+				return "", err
+			}
+			field += " (file)"
+		default:
+			continue
+		}
+		if !json.Valid(data) {
+			return "", fmt.Errorf("model %q: invalid JSON", name)
+		}
+		c.Models[name] = json.RawMessage(data)
+		return field, nil
+	}
+	return "", nil
+}
+
+// envName maps a link or model name to the form used in variable names.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// setFromString parses s into a bool, integer, float, string or string
+// slice field, or a pointer to one, which is allocated.
+func setFromString(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.Pointer:
+		p := reflect.New(v.Type().Elem())
+		if err := setFromString(p.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(p)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported field type %s", v.Type())
+		}
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+		v.Set(reflect.ValueOf(parts))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
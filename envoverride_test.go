@@ -0,0 +1,36 @@
+package drift
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	c := NewConfig("env")
+	c.Models["m"] = json.RawMessage(`{}`)
+	c.AddLink(NeuralLinkConfig{Name: "a-b", SourceModel: "m", TargetModel: "m", LinkSize: 4})
+	applied, err := c.ApplyEnvOverrides([]string{
+		"DRIFT_LINKS_a_b_ENABLED=true",
+		"DRIFT_LINKS_a_b_GAIN=0.5",
+		"DRIFT_LINKS_other_ENABLED=true",
+		"DRIFT_MODELS_other={}",
+		"HOME=/root",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("applied %v, want the two overrides of link a-b", applied)
+	}
+	l := c.Links[0]
+	if !l.Enabled || l.Gain == nil || *l.Gain != 0.5 {
+		t.Errorf("link enabled %v, gain %v; want true, 0.5", l.Enabled, l.Gain)
+	}
+	if len(c.Warnings()) != 2 {
+		t.Errorf("warnings %q, want one per unmatched override", c.Warnings())
+	}
+
+	if _, err := c.ApplyEnvOverrides([]string{"DRIFT_LINKS_a_b_LINK_SIZE=many"}); err == nil {
+		t.Error("a malformed value for a matched field was accepted")
+	}
+}
@@ -0,0 +1,111 @@
+package drift
+
+import (
+	"sync"
+	"time"
+)
+
+// EventKind identifies the type of condition an Event reports.
+type EventKind string
+
+// Event kinds raised by the built-in monitors.
+const (
+	EventRewardHacking EventKind = "reward_hacking"
+	EventLinkAnomaly   EventKind = "link_anomaly"
+	EventInputDrift    EventKind = "input_drift"
+)
+
+// Event is a condition raised by a monitor that a guard policy may act on.
+type Event struct {
+	Kind    EventKind `json:"kind"`
+	Source  string    `json:"source"`  // Monitor, link, or model that raised the event
+	Step    int       `json:"step"`    // Step or window at which the condition was observed
+	Value   float64   `json:"value"`   // Monitor-specific magnitude
+	Message string    `json:"message"` // Human-readable description
+	Time    time.Time `json:"time"`
+}
+
+// EventHandler receives events raised by monitors.
+type EventHandler func(Event)
+
+// GuardAction is the response a GuardPolicy chooses for an event.
+type GuardAction int
+
+const (
+	GuardIgnore      GuardAction = iota // Drop the event
+	GuardWarn                           // Report the event but keep running
+	GuardPause                          // Stop online learning until cleared
+	GuardHalt                           // Stop the run
+	GuardRecalibrate                    // Run the configured recalibration routine
+)
+
+// String returns the action name.
+func (a GuardAction) String() string {
+	switch a {
+	case GuardIgnore:
+		return "ignore"
+	case GuardWarn:
+		return "warn"
+	case GuardPause:
+		return "pause"
+	case GuardHalt:
+		return "halt"
+	case GuardRecalibrate:
+		return "recalibrate"
+	}
+	return "unknown"
+}
+
+// GuardPolicy maps event kinds to actions and dispatches events to the
+// handlers registered for the chosen action.
+type GuardPolicy struct {
+	mu       sync.RWMutex
+	rules    map[EventKind]GuardAction
+	handlers map[GuardAction][]EventHandler
+	fallback GuardAction
+}
+
+// NewGuardPolicy creates a policy that applies fallback to events without a rule.
+func NewGuardPolicy(fallback GuardAction) *GuardPolicy {
+	return &GuardPolicy{
+		rules:    make(map[EventKind]GuardAction),
+		handlers: make(map[GuardAction][]EventHandler),
+		fallback: fallback,
+	}
+}
+
+// SetRule sets the action taken for events of the given kind.
+func (g *GuardPolicy) SetRule(kind EventKind, action GuardAction) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rules[kind] = action
+}
+
+// On registers a handler invoked whenever an event resolves to action.
+func (g *GuardPolicy) On(action GuardAction, h EventHandler) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.handlers[action] = append(g.handlers[action], h)
+}
+
+// Decide returns the action configured for kind.
+func (g *GuardPolicy) Decide(kind EventKind) GuardAction {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if a, ok := g.rules[kind]; ok {
+		return a
+	}
+	return g.fallback
+}
+
+// Handle resolves e to an action and runs that action's handlers.
+// It has the EventHandler signature so a policy can be passed to monitors directly.
+func (g *GuardPolicy) Handle(e Event) {
+	action := g.Decide(e.Kind)
+	g.mu.RLock()
+	handlers := append([]EventHandler(nil), g.handlers[action]...)
+	g.mu.RUnlock()
+	for _, h := range handlers {
+		h(e)
+	}
+}
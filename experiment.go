@@ -0,0 +1,369 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Phase kinds of an experiment.
+const (
+	PhaseTrain     = "train"
+	PhaseBenchmark = "benchmark"
+)
+
+// Environment connects a task to an experiment. Experiments refer to
+// environments by the name they are registered under, so the task code
+// lives in Go while the structure of the experiment is data.
+type Environment interface {
+	// Attach prepares t for a training phase: it installs lessons with
+	// Teach and sets Advance and Reward as the phase needs.
+	Attach(t *Trainer, phase ExperimentPhase) error
+	// Benchmark runs the agent in t.Runtime for the phase's length under
+	// mode and reports its performance. When mode.Learn is set the
+	// environment keeps training through t.
+	Benchmark(ctx context.Context, t *Trainer, phase ExperimentPhase, mode BenchmarkMode) (ExperimentResult, error)
+}
+
+// ErrUnknownEnvironment is returned when an experiment names an environment
+// that hasn't been registered.
+var ErrUnknownEnvironment = errors.New("drift: environment not registered")
+
+// environments holds registered environment constructors keyed by name.
+var environments = struct {
+	sync.RWMutex
+	m map[string]func() Environment
+}{m: make(map[string]func() Environment)}
+
+// RegisterEnvironment registers a constructor for the environment name.
+// Every experiment phase gets a fresh environment from it. Registering the
+// same name twice replaces the earlier constructor.
+func RegisterEnvironment(name string, newEnv func() Environment) {
+	environments.Lock()
+	defer environments.Unlock()
+	environments.m[name] = newEnv
+}
+
+// EnvironmentNames returns the sorted names of all registered environments.
+func EnvironmentNames() []string {
+	environments.RLock()
+	defer environments.RUnlock()
+	return sortedKeys(environments.m)
+}
+
+func newEnvironment(name string) (Environment, error) {
+	environments.RLock()
+	defer environments.RUnlock()
+	newEnv, ok := environments.m[name]
+	if !ok {
+		return nil, fmt.Errorf("environment %q: %w", name, ErrUnknownEnvironment)
+	}
+	return newEnv(), nil
+}
+
+// BenchmarkMode is one arm of a benchmark phase. Each mode starts from a
+// copy of the weights trained so far, so modes don't influence each other.
+type BenchmarkMode struct {
+	Name  string   `json:"name"`
+	Links []string `json:"links,omitempty"` // Links or groups enabled in this mode; all others are disabled
+	Learn bool     `json:"learn,omitempty"` // Models keep learning online during the benchmark
+}
+
+// ExperimentPhase is one stage of an experiment. Train phases run the
+// embedded TrainingPhase with the environment's lessons; benchmark phases
+// run every mode for the phase's length.
+type ExperimentPhase struct {
+	TrainingPhase
+	Kind        string               `json:"kind,omitempty"` // PhaseTrain (default) or PhaseBenchmark
+	Environment string               `json:"environment"`
+	Params      map[string]float64   `json:"params,omitempty"` // Environment settings, e.g. restricting terrains
+	Modes       []BenchmarkMode      `json:"modes,omitempty"`
+	Matrix      []string             `json:"matrix,omitempty"`     // Without Modes, benchmark ModeMatrix(Matrix...)
+	Monitor     bool                 `json:"monitor,omitempty"`    // Report training windows of trained models to the log
+	Energy      *EnergyModel         `json:"energy,omitempty"`     // Prices compute for the energy objective
+	Objectives  []Objective          `json:"objectives,omitempty"` // Pareto axes of a benchmark; default DefaultObjectives
+	Randomize   *DomainRandomization `json:"randomize,omitempty"`  // Per-episode domain randomization; see Domain
+}
+
+// Experiment chains phases over one set of models, declaratively.
+type Experiment struct {
+	Name    string            `json:"name"`
+	Config  string            `json:"config"`            // Path of the drift config, relative to the experiment file
+	Phases  []ExperimentPhase `json:"phases"`            // Run in order
+	Log     string            `json:"log,omitempty"`     // JSONL file receiving windows and metrics as they complete
+	Results string            `json:"results,omitempty"` // JSON file receiving the final report
+	Archive string            `json:"archive,omitempty"` // .driftrun archive of the finished run
+
+	dir string
+}
+
+// PhaseReport is the outcome of one experiment phase.
+type PhaseReport struct {
+	Name    string             `json:"name"`
+	Kind    string             `json:"kind"`
+	Elapsed float64            `json:"elapsed_seconds"`
+	Results []ExperimentResult `json:"results,omitempty"` // One per mode of a benchmark phase
+	Pareto  []string           `json:"pareto,omitempty"`  // Modes on the Pareto front of the phase's objectives
+}
+
+// ExperimentReport is the outcome of RunExperiment.
+type ExperimentReport struct {
+	Name       string        `json:"name"`
+	ConfigHash string        `json:"config_hash"` // See Config.Hash
+	Phases     []PhaseReport `json:"phases"`
+}
+
+// LoadExperiment reads an experiment spec from a JSON file. The config path
+// in it is resolved relative to the file.
+func LoadExperiment(path string) (*Experiment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e Experiment
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	e.dir = filepath.Dir(path)
+	return &e, nil
+}
+
+// path resolves p relative to the experiment file.
+func (e *Experiment) path(p string) string {
+	if p == "" || filepath.IsAbs(p) || e.dir == "" {
+		return p
+	}
+	return filepath.Join(e.dir, p)
+}
+
+// RunExperiment builds a runtime from the experiment's config and runs its
+// phases in order. Training phases accumulate into the runtime's weights;
+// benchmark phases evaluate copies of them. onPhase, if non-nil, is called
+// as each phase starts.
+func RunExperiment(ctx context.Context, e *Experiment, onPhase func(ExperimentPhase)) (*ExperimentReport, error) {
+	cfg, err := LoadFromFile(e.path(e.Config))
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var log *ResultLog
+	if e.Log != "" {
+		if log, err = OpenResultLog(e.path(e.Log)); err != nil {
+			return nil, err
+		}
+		defer log.Close()
+	}
+
+	for _, p := range e.Phases {
+		if _, err := p.Domain(); err != nil {
+			return nil, fmt.Errorf("phase %q: %w", p.Name, err)
+		}
+	}
+	report := &ExperimentReport{Name: e.Name}
+	if report.ConfigHash, err = cfg.Hash(); err != nil {
+		return nil, err
+	}
+	for _, p := range e.Phases {
+		if p.Kind == "" {
+			p.Kind = PhaseTrain
+		}
+		if onPhase != nil {
+			onPhase(p)
+		}
+		start := time.Now()
+		pr := PhaseReport{Name: p.Name, Kind: p.Kind}
+		switch p.Kind {
+		case PhaseTrain:
+			err = trainPhase(ctx, r, p, log)
+		case PhaseBenchmark:
+			pr.Results, err = benchmarkPhase(ctx, r, p, log)
+			objectives := p.Objectives
+			if len(objectives) == 0 {
+				objectives = DefaultObjectives
+			}
+			for _, res := range ParetoFront(pr.Results, objectives) {
+				pr.Pareto = append(pr.Pareto, res.Mode)
+			}
+		default:
+			err = fmt.Errorf("unknown kind %q", p.Kind)
+		}
+		if err != nil {
+			return report, fmt.Errorf("phase %q: %w", p.Name, err)
+		}
+		pr.Elapsed = time.Since(start).Seconds()
+		report.Phases = append(report.Phases, pr)
+	}
+
+	if e.Results != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return report, err
+		}
+		if err := writeFileAtomic(e.path(e.Results), data, 0644, false); err != nil {
+			return report, err
+		}
+	}
+	if e.Archive != "" {
+		a := &RunArchive{Name: e.Name, Config: cfg, Runtime: r, Results: report}
+		if log != nil {
+			if err := log.Flush(); err != nil {
+				return report, err
+			}
+			a.Logs = map[string]string{filepath.Base(e.Log): e.path(e.Log)}
+		}
+		if err := a.Write(e.path(e.Archive)); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+func trainPhase(ctx context.Context, r *Runtime, p ExperimentPhase, log *ResultLog) error {
+	env, err := newEnvironment(p.Environment)
+	if err != nil {
+		return err
+	}
+	t := NewTrainer(r)
+	if p.Monitor && log != nil {
+		for _, model := range p.Train {
+			t.Monitors[model] = NewTrainingMonitor(model, 0, log)
+		}
+	}
+	if err := env.Attach(t, p); err != nil {
+		return err
+	}
+	if err := t.RunPhase(ctx, p.TrainingPhase); err != nil {
+		return err
+	}
+	for _, m := range t.Monitors {
+		if err := m.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func benchmarkPhase(ctx context.Context, r *Runtime, p ExperimentPhase, log *ResultLog) ([]ExperimentResult, error) {
+	modes := p.Modes
+	if len(modes) == 0 && len(p.Matrix) > 0 {
+		modes = ModeMatrix(p.Matrix...)
+	}
+	if len(modes) == 0 {
+		return nil, fmt.Errorf("benchmark has no modes")
+	}
+	return RunModes(ctx, r, modes, func(ctx context.Context, t *Trainer, mode BenchmarkMode) (ExperimentResult, error) {
+		env, err := newEnvironment(p.Environment)
+		if err != nil {
+			return ExperimentResult{}, err
+		}
+		res, err := env.Benchmark(ctx, t, p, mode)
+		if err != nil {
+			return res, err
+		}
+		if err := res.Score(t.Runtime, p.Energy); err != nil {
+			return res, err
+		}
+		if log != nil {
+			for _, w := range res.Windows {
+				if err := log.Append(WindowRecord{Mode: mode.Name, WindowMetrics: w}); err != nil {
+					return res, err
+				}
+			}
+			for _, t := range res.Transitions {
+				for _, m := range t.Metrics(mode.Name) {
+					if err := log.Record(m); err != nil {
+						return res, err
+					}
+				}
+			}
+		}
+		return res, nil
+	})
+}
+
+// ModeMatrix returns the standard comparison of links on or off crossed with
+// online learning on or off: baseline, learn, link and link+learn. links
+// names the links or groups switched on by the link modes.
+func ModeMatrix(links ...string) []BenchmarkMode {
+	return []BenchmarkMode{
+		{Name: "baseline"},
+		{Name: "learn", Learn: true},
+		{Name: "link", Links: links},
+		{Name: "link+learn", Links: links, Learn: true},
+	}
+}
+
+// RunModes runs one benchmark per mode, each on its own fork of pretrained,
+// so every mode starts from exactly the weights of a single training pass
+// and modes can't influence each other. Links are switched per mode before
+// run is called. Results missing a Mode are labeled with the mode's name,
+// and built-in objectives run doesn't score are scored; see Score.
+func RunModes(ctx context.Context, pretrained *Runtime, modes []BenchmarkMode, run func(ctx context.Context, t *Trainer, mode BenchmarkMode) (ExperimentResult, error)) ([]ExperimentResult, error) {
+	results := make([]ExperimentResult, 0, len(modes))
+	for _, mode := range modes {
+		mr, err := pretrained.Fork()
+		if err != nil {
+			return results, err
+		}
+		if err := mr.enableOnly(mode.Links); err != nil {
+			return results, fmt.Errorf("mode %q: %w", mode.Name, err)
+		}
+		res, err := run(ctx, NewTrainer(mr), mode)
+		if err != nil {
+			return results, fmt.Errorf("mode %q: %w", mode.Name, err)
+		}
+		if res.Mode == "" {
+			res.Mode = mode.Name
+		}
+		if err := res.Score(mr, nil); err != nil {
+			return results, fmt.Errorf("mode %q: %w", mode.Name, err)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Fork returns an independent runtime with r's config and copies of its
+// current networks. Step state, counters, sinks and per-link settings start
+// fresh.
+func (r *Runtime) Fork() (*Runtime, error) {
+	f, err := NewRuntime(r.Config())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.CopyWeightsFrom(r); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// enableOnly enables the named links and groups and disables every other
+// link.
+func (r *Runtime) enableOnly(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := make(map[string]bool, len(names))
+	for _, l := range r.links {
+		l.cfg.Enabled = false
+		for _, name := range names {
+			if l.cfg.Name == name || l.cfg.InGroup(name) {
+				l.cfg.Enabled = true
+				matched[name] = true
+			}
+		}
+	}
+	for _, name := range names {
+		if !matched[name] {
+			return fmt.Errorf("no link or group named %q", name)
+		}
+	}
+	return nil
+}
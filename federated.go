@@ -0,0 +1,208 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// DefaultQuorum is the number of contributions a CheckpointServer averages
+// into each federated round when CheckpointServer.Quorum is 0.
+const DefaultQuorum = 2
+
+// ErrStaleContribution is returned for a federated contribution computed
+// against a checkpoint version that is no longer the latest. Pull the new
+// version, train on it and contribute again.
+var ErrStaleContribution = errors.New("drift: contribution based on an outdated checkpoint")
+
+// FederatedRound reports a model's federated round after a contribution.
+type FederatedRound struct {
+	Model         string `json:"model"`
+	Base          int    `json:"base"`          // Checkpoint version the round's deltas apply to
+	Contributions int    `json:"contributions"` // Received so far, one per node
+	Quorum        int    `json:"quorum"`
+	Version       int    `json:"version,omitempty"` // The averaged checkpoint, once the round has closed
+}
+
+// fedContribution is the body of a federated contribution: the change a
+// node's training made to a model's weights, never the data it trained on.
+type fedContribution struct {
+	Node    string    `json:"node"`
+	Base    int       `json:"base"`    // Checkpoint version the delta is relative to
+	Samples int       `json:"samples"` // Weight of the delta in the average
+	Delta   []float32 `json:"delta"`   // Every weight slice, in forEachWeightSlice order
+}
+
+// fedRound collects the contributions to one model's next version.
+type fedRound struct {
+	base          int
+	contributions map[string]fedContribution // By node
+}
+
+// Contribute sends the change training made to a model's weights in r since
+// the version this client last pushed or pulled, for federated averaging:
+// once the server has Quorum contributions against that version, it applies
+// their average, weighted by samples, as the model's next checkpoint version,
+// which every node then picks up with Pull. Only weight deltas leave the
+// node. A typical node loop is:
+//
+//	for {
+//		n := trainLocally(r)
+//		c.Contribute(r, "navigator", n)
+//		c.Pull(r, "navigator") // Applies the average once the round closes
+//	}
+//
+// A contribution against a version the server has moved past fails with
+// ErrStaleContribution in its message; Pull and contribute again.
+func (c *CheckpointClient) Contribute(r *Runtime, model string, samples int) (FederatedRound, error) {
+	c.mu.Lock()
+	base, ok := c.bases[model]
+	version := c.versions[model]
+	c.mu.Unlock()
+	if !ok {
+		return FederatedRound{}, fmt.Errorf("model %q: no base checkpoint; push or pull it first", model)
+	}
+	saved, err := r.saveModels([]string{model})
+	if err != nil {
+		return FederatedRound{}, err
+	}
+	from, err := flatWeights(base)
+	if err != nil {
+		return FederatedRound{}, fmt.Errorf("model %q: %w", model, err)
+	}
+	delta, err := flatWeights(saved[0])
+	if err != nil {
+		return FederatedRound{}, fmt.Errorf("model %q: %w", model, err)
+	}
+	if len(delta) != len(from) {
+		return FederatedRound{}, fmt.Errorf("model %q: %d weights, base checkpoint has %d", model, len(delta), len(from))
+	}
+	for i := range delta {
+		delta[i] -= from[i]
+	}
+	var round FederatedRound
+	body := fedContribution{Node: c.Node, Base: version, Samples: samples, Delta: delta}
+	if err := postJSON(c.Client, http.MethodPost, c.URL+"/federated/"+url.PathEscape(model), body, &round, c.auth); err != nil {
+		return FederatedRound{}, fmt.Errorf("contribute %q: %w", model, err)
+	}
+	return round, nil
+}
+
+// contribute adds a node's delta to the model's current round, and closes
+// the round once it reaches the quorum.
+func (s *CheckpointServer) contribute(model string, c fedContribution) (FederatedRound, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cur, ok := s.models[model]
+	if !ok {
+		return FederatedRound{}, fmt.Errorf("model %q: no checkpoint to contribute to", model)
+	}
+	if c.Base != cur.Checkpoint.Version {
+		return FederatedRound{}, fmt.Errorf("model %q: version %d, latest is %d: %w", model, c.Base, cur.Checkpoint.Version, ErrStaleContribution)
+	}
+	var saved nn.SavedModel
+	if err := json.Unmarshal(cur.Model, &saved); err != nil {
+		return FederatedRound{}, err
+	}
+	wd, err := decodeWeights(saved)
+	if err != nil {
+		return FederatedRound{}, err
+	}
+	var weights [][]float32
+	n := 0
+	forEachWeightSlice(wd.Layers, saved.Config.Layers, func(_ nn.LayerDefinition, _ string, w []float32) {
+		weights = append(weights, w)
+		n += len(w)
+	})
+	if len(c.Delta) != n {
+		return FederatedRound{}, fmt.Errorf("model %q: delta of %d weights, model has %d", model, len(c.Delta), n)
+	}
+	if c.Samples <= 0 {
+		c.Samples = 1
+	}
+
+	round := s.rounds[model]
+	if round == nil || round.base != cur.Checkpoint.Version {
+		round = &fedRound{base: cur.Checkpoint.Version, contributions: make(map[string]fedContribution)}
+		s.rounds[model] = round
+	}
+	round.contributions[c.Node] = c
+	quorum := s.Quorum
+	if quorum <= 0 {
+		quorum = DefaultQuorum
+	}
+	status := FederatedRound{Model: model, Base: round.base, Contributions: len(round.contributions), Quorum: quorum}
+	if status.Contributions < quorum {
+		return status, nil
+	}
+
+	total := 0
+	for _, k := range round.contributions {
+		total += k.Samples
+	}
+	offset := 0
+	for _, w := range weights {
+		for i := range w {
+			var sum float64
+			for _, k := range round.contributions {
+				sum += float64(k.Samples) * float64(k.Delta[offset+i])
+			}
+			w[i] += float32(sum / float64(total))
+		}
+		offset += len(w)
+	}
+	if err := encodeWeights(&saved, wd); err != nil {
+		return status, err
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return status, err
+	}
+	cp, err := s.store(model, remotePush{Checkpoint: RemoteCheckpoint{Node: "federated"}, Model: data})
+	if err != nil {
+		return status, err
+	}
+	delete(s.rounds, model)
+	status.Version = cp.Version
+	return status, nil
+}
+
+// serveFederated handles POST /federated/{model}.
+func (s *CheckpointServer) serveFederated(w http.ResponseWriter, req *http.Request, model string) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var c fedContribution
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<30)).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	round, err := s.contribute(model, c)
+	switch {
+	case errors.Is(err, ErrStaleContribution):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		writeJSON(w, round)
+	}
+}
+
+// flatWeights returns every weight of a saved model in forEachWeightSlice
+// order.
+func flatWeights(saved nn.SavedModel) ([]float32, error) {
+	wd, err := decodeWeights(saved)
+	if err != nil {
+		return nil, err
+	}
+	var out []float32
+	forEachWeightSlice(wd.Layers, saved.Config.Layers, func(_ nn.LayerDefinition, _ string, w []float32) {
+		out = append(out, w...)
+	})
+	return out, nil
+}
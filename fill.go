@@ -0,0 +1,87 @@
+package drift
+
+import "fmt"
+
+// FillMode selects what an input segment holds when it has no data: before
+// the first payload arrives over a link, while the link is disabled, or when
+// the external input doesn't reach the segment.
+type FillMode string
+
+const (
+	FillZeros     FillMode = "zeros"     // The default
+	FillConstant  FillMode = "constant"  // Every element is FillValue
+	FillEmbedding FillMode = "embedding" // The segment's Embedding vector, e.g. a learned "no signal" code
+	FillHold      FillMode = "hold"      // The last data the segment received
+)
+
+// segmentFill is an input segment prepared for filling by the runtime.
+type segmentFill struct {
+	InputSegment
+	linkFed bool      // A link targets the segment, so its data comes from payloads
+	last    []float32 // Last data received, for FillHold
+}
+
+// validate checks the segment against the model's input size.
+func (s InputSegment) validate(inputSize int) error {
+	if s.Offset < 0 || s.Size <= 0 || s.Offset+s.Size > inputSize {
+		return fmt.Errorf("segment %q [%d, %d) outside input of size %d", s.Name, s.Offset, s.Offset+s.Size, inputSize)
+	}
+	switch s.Fill {
+	case "", FillZeros, FillConstant, FillHold:
+	case FillEmbedding:
+		if len(s.Embedding) != s.Size {
+			return fmt.Errorf("segment %q: embedding has %d values, want %d", s.Name, len(s.Embedding), s.Size)
+		}
+	default:
+		return fmt.Errorf("segment %q: unknown fill %q", s.Name, s.Fill)
+	}
+	return nil
+}
+
+// newSegmentFills prepares the segments of a model, marking those a link
+// writes into.
+func newSegmentFills(segments []InputSegment, inputSize int, links []*runtimeLink) ([]*segmentFill, error) {
+	var fills []*segmentFill
+	for _, seg := range segments {
+		if err := seg.validate(inputSize); err != nil {
+			return nil, err
+		}
+		f := &segmentFill{InputSegment: seg}
+		for _, l := range links {
+			lo, hi := l.cfg.TargetOffset, l.cfg.TargetOffset+l.cfg.LinkSize
+			if lo < seg.Offset+seg.Size && hi > seg.Offset {
+				f.linkFed = true
+			}
+		}
+		fills = append(fills, f)
+	}
+	return fills, nil
+}
+
+// apply fills the segment's elements at index from onward.
+func (f *segmentFill) apply(input []float32, from int) {
+	start, end := max(f.Offset, from), f.Offset+f.Size
+	for i := start; i < end; i++ {
+		switch f.Fill {
+		case FillConstant:
+			input[i] = f.FillValue
+		case FillEmbedding:
+			input[i] = f.Embedding[i-f.Offset]
+		case FillHold:
+			if f.last != nil {
+				input[i] = f.last[i-f.Offset]
+			} else {
+				input[i] = 0
+			}
+		default:
+			input[i] = 0
+		}
+	}
+}
+
+// remember stores the segment's current data for FillHold.
+func (f *segmentFill) remember(input []float32) {
+	if f.Fill == FillHold {
+		f.last = append(f.last[:0], input[f.Offset:f.Offset+f.Size]...)
+	}
+}
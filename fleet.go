@@ -0,0 +1,359 @@
+package drift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Health states reported in Telemetry.
+const (
+	HealthOK     = "ok"
+	HealthHalted = "halted" // Stopped by a NumericGuard; see Telemetry.Reason
+	HealthClosed = "closed" // Shut down
+)
+
+// DefaultStaleAfter is how long a FleetCollector waits for a node's next
+// report before marking it stale.
+const DefaultStaleAfter = time.Minute
+
+// Telemetry is a periodic summary of one runtime, sent by a
+// TelemetryReporter to a FleetCollector.
+type Telemetry struct {
+	Node    string             `json:"node"`
+	Config  string             `json:"config"` // Config name
+	Time    time.Time          `json:"time"`
+	Health  string             `json:"health"`           // One of the Health constants
+	Reason  string             `json:"reason,omitempty"` // Why the runtime halted
+	Steps   uint64             `json:"steps"`
+	Uptime  time.Duration      `json:"uptime"`
+	Metrics map[string]float64 `json:"metrics,omitempty"` // Latest value per metric key, e.g. reward/agent=a
+	Links   []LinkStatus       `json:"links,omitempty"`
+}
+
+// Telemetry summarizes the runtime's health and links for a node named node.
+// Metrics are left to the caller; TelemetryReporter fills them in.
+func (r *Runtime) Telemetry(node string) Telemetry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	t := Telemetry{
+		Node:   node,
+		Config: r.cfg.Name,
+		Time:   time.Now(),
+		Health: HealthOK,
+		Steps:  r.steps,
+		Uptime: time.Since(r.started),
+		Links:  make([]LinkStatus, len(r.links)),
+	}
+	switch {
+	case r.closed:
+		t.Health = HealthClosed
+	case r.halted != nil:
+		t.Health, t.Reason = HealthHalted, r.halted.Error()
+	}
+	for i, l := range r.links {
+		t.Links[i] = l.status()
+	}
+	return t
+}
+
+// TelemetryReporter sends a runtime's Telemetry to a FleetCollector. It is
+// also a MetricSink: added to the runtime with AddSink, it keeps the latest
+// value of each metric for the next report, and its Flush sends a final
+// report when the runtime shuts down.
+type TelemetryReporter struct {
+	URL     string   // Collector base URL, e.g. http://collector:7070
+	Node    string   // Defaults to the host name
+	Metrics []string // Metric names to report; empty reports all
+	Token   string   // Optional bearer token
+	Client  *http.Client
+	OnError func(error) // Optional; receives errors from background reports
+
+	rt     *Runtime
+	mu     sync.Mutex
+	latest map[string]float64
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// NewTelemetryReporter creates a reporter sending r's telemetry to the
+// collector at url.
+func NewTelemetryReporter(url, node string, r *Runtime) *TelemetryReporter {
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+	return &TelemetryReporter{URL: strings.TrimRight(url, "/"), Node: node, rt: r, latest: make(map[string]float64)}
+}
+
+// Record implements MetricSink.
+func (t *TelemetryReporter) Record(m Metric) error {
+	if len(t.Metrics) > 0 && !slices.Contains(t.Metrics, m.Name) {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.latest[metricKey(m)] = m.Value
+	return nil
+}
+
+// Flush implements MetricSink by sending a report.
+func (t *TelemetryReporter) Flush() error {
+	return t.Report()
+}
+
+// Report sends the runtime's current telemetry.
+func (t *TelemetryReporter) Report() error {
+	tel := t.rt.Telemetry(t.Node)
+	t.mu.Lock()
+	if len(t.latest) > 0 {
+		tel.Metrics = make(map[string]float64, len(t.latest))
+		for k, v := range t.latest {
+			tel.Metrics[k] = v
+		}
+	}
+	t.mu.Unlock()
+	if err := postJSON(t.Client, http.MethodPost, t.URL+"/telemetry", tel, nil, t.auth); err != nil {
+		return fmt.Errorf("telemetry: %w", err)
+	}
+	return nil
+}
+
+func (t *TelemetryReporter) auth(req *http.Request) {
+	if t.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+t.Token)
+	}
+}
+
+// Start reports every interval in the background until Stop. Failed reports
+// go to OnError and are retried at the next interval.
+func (t *TelemetryReporter) Start(interval time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stop != nil {
+		return
+	}
+	t.stop, t.done = make(chan struct{}), make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C:
+				if err := t.Report(); err != nil && t.OnError != nil {
+					t.OnError(err)
+				}
+			}
+		}
+	}(t.stop, t.done)
+}
+
+// Stop ends background reporting started by Start and waits for an
+// in-flight report to finish.
+func (t *TelemetryReporter) Stop() {
+	t.mu.Lock()
+	stop, done := t.stop, t.done
+	t.stop, t.done = nil, nil
+	t.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+// NodeSummary is one node's line in a FleetOverview.
+type NodeSummary struct {
+	Node      string        `json:"node"`
+	Config    string        `json:"config"`
+	Health    string        `json:"health"`
+	Reason    string        `json:"reason,omitempty"`
+	Steps     uint64        `json:"steps"`
+	Uptime    time.Duration `json:"uptime"`
+	Links     int           `json:"links"`
+	Enabled   int           `json:"enabled"`             // Links currently enabled
+	Corrupted uint64        `json:"corrupted,omitempty"` // Payloads that failed their checksum, over all links
+	LastSeen  time.Time     `json:"last_seen"`           // When the collector received the last report
+	Stale     bool          `json:"stale,omitempty"`     // No report within the collector's StaleAfter
+}
+
+// MetricSpread summarizes one metric key across the fleet's fresh nodes.
+type MetricSpread struct {
+	Nodes int     `json:"nodes"`
+	Min   float64 `json:"min"`
+	Mean  float64 `json:"mean"`
+	Max   float64 `json:"max"`
+}
+
+// FleetOverview is the fleet-wide view served by a FleetCollector.
+type FleetOverview struct {
+	Time      time.Time               `json:"time"`
+	Nodes     []NodeSummary           `json:"nodes"` // Sorted by node name
+	Healthy   int                     `json:"healthy"`
+	Unhealthy int                     `json:"unhealthy"` // Halted or closed
+	Stale     int                     `json:"stale"`
+	Steps     uint64                  `json:"steps"`               // Over all nodes
+	Corrupted uint64                  `json:"corrupted,omitempty"` // Over all nodes and links
+	Metrics   map[string]MetricSpread `json:"metrics,omitempty"`   // Over nodes that aren't stale
+}
+
+// FleetCollector receives Telemetry from runtimes across machines and serves
+// a fleet-wide overview. As an http.Handler it serves:
+//
+//	POST /telemetry      accept a Telemetry report
+//	GET  /fleet          the FleetOverview
+//	GET  /fleet/{node}   the node's latest Telemetry
+//
+// Mount it under a prefix with http.StripPrefix.
+type FleetCollector struct {
+	StaleAfter time.Duration // 0 means DefaultStaleAfter
+	Token      string        // When set, reports must carry it as a bearer token
+
+	mu    sync.RWMutex
+	nodes map[string]fleetNode
+}
+
+type fleetNode struct {
+	tel  Telemetry
+	seen time.Time
+}
+
+// NewFleetCollector creates an empty collector.
+func NewFleetCollector() *FleetCollector {
+	return &FleetCollector{nodes: make(map[string]fleetNode)}
+}
+
+// Receive stores a report, replacing the node's previous one.
+func (c *FleetCollector) Receive(t Telemetry) error {
+	if t.Node == "" {
+		return errors.New("telemetry without a node name")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nodes[t.Node] = fleetNode{tel: t, seen: time.Now()}
+	return nil
+}
+
+// Node returns the node's latest report.
+func (c *FleetCollector) Node(name string) (Telemetry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	n, ok := c.nodes[name]
+	return n.tel, ok
+}
+
+// Forget drops a node, e.g. one decommissioned for good.
+func (c *FleetCollector) Forget(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.nodes, name)
+}
+
+// Overview summarizes the latest report of every node.
+func (c *FleetCollector) Overview() FleetOverview {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	staleAfter := c.StaleAfter
+	if staleAfter <= 0 {
+		staleAfter = DefaultStaleAfter
+	}
+	o := FleetOverview{Time: time.Now(), Nodes: make([]NodeSummary, 0, len(c.nodes))}
+	sums := make(map[string]float64)
+	for _, name := range sortedKeys(c.nodes) {
+		n := c.nodes[name]
+		s := NodeSummary{
+			Node: name, Config: n.tel.Config, Health: n.tel.Health, Reason: n.tel.Reason,
+			Steps: n.tel.Steps, Uptime: n.tel.Uptime, Links: len(n.tel.Links),
+			LastSeen: n.seen, Stale: o.Time.Sub(n.seen) > staleAfter,
+		}
+		for _, l := range n.tel.Links {
+			if l.Enabled {
+				s.Enabled++
+			}
+			s.Corrupted += l.Corrupted
+		}
+		o.Nodes = append(o.Nodes, s)
+		o.Steps += s.Steps
+		o.Corrupted += s.Corrupted
+		switch {
+		case s.Stale:
+			o.Stale++
+			continue
+		case s.Health == HealthOK:
+			o.Healthy++
+		default:
+			o.Unhealthy++
+		}
+		for k, v := range n.tel.Metrics {
+			if o.Metrics == nil {
+				o.Metrics = make(map[string]MetricSpread)
+			}
+			m, ok := o.Metrics[k]
+			if !ok {
+				m.Min, m.Max = math.Inf(1), math.Inf(-1)
+			}
+			m.Nodes++
+			m.Min, m.Max = math.Min(m.Min, v), math.Max(m.Max, v)
+			sums[k] += v
+			o.Metrics[k] = m
+		}
+	}
+	for k, m := range o.Metrics {
+		m.Mean = sums[k] / float64(m.Nodes)
+		o.Metrics[k] = m
+	}
+	return o
+}
+
+// ServeHTTP implements http.Handler.
+func (c *FleetCollector) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case req.URL.Path == "/telemetry":
+		if req.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if c.Token != "" && req.Header.Get("Authorization") != "Bearer "+c.Token {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var t Telemetry
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := c.Receive(t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case req.Method != http.MethodGet:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	case req.URL.Path == "/fleet":
+		writeJSON(w, c.Overview())
+	case strings.HasPrefix(req.URL.Path, "/fleet/"):
+		t, ok := c.Node(strings.TrimPrefix(req.URL.Path, "/fleet/"))
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeJSON(w, t)
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
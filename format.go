@@ -0,0 +1,209 @@
+package drift
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Format returns the canonical form of a JSON config: models sorted by name,
+// links sorted by name, two-space indentation throughout (including embedded
+// model definitions), and a trailing newline. Formatting the same config twice
+// yields identical bytes, which keeps version-control diffs minimal.
+// Everything else is kept as written, down to key order, number spelling,
+// unknown fields, fields left unset, includes and ${var} references, so
+// formatting never changes what the config means.
+func Format(data []byte) ([]byte, error) {
+	members, err := jsonMembers(data)
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range members {
+		switch m.key {
+		case "models":
+			members[i].value = sortedMembers(m.value)
+		case "links":
+			members[i].value = sortedLinks(m.value)
+		}
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, joinMembers(members), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+// jsonMember is one member of a JSON object, its value as written.
+type jsonMember struct {
+	key   string
+	value json.RawMessage
+}
+
+// jsonMembers splits the JSON object data into its members, in order.
+func jsonMembers(data []byte) ([]jsonMember, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, err
+	} else if tok != json.Delim('{') {
+		return nil, fmt.Errorf("config is not a JSON object")
+	}
+	var members []jsonMember
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		m := jsonMember{key: tok.(string)}
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("data after the config object")
+	}
+	return members, nil
+}
+
+// joinMembers encodes members as a JSON object.
+func joinMembers(members []jsonMember) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	buf.WriteByte('{')
+	for i, m := range members {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		enc.Encode(m.key)
+		buf.WriteByte(':')
+		buf.Write(m.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// sortedMembers returns the JSON object raw with its members sorted by key,
+// or raw itself when it isn't an object, e.g. a ${var} reference.
+func sortedMembers(raw json.RawMessage) json.RawMessage {
+	members, err := jsonMembers(raw)
+	if err != nil {
+		return raw
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].key < members[j].key })
+	return joinMembers(members)
+}
+
+// sortedLinks returns the JSON array raw with its elements sorted by their
+// "name", or raw itself when it isn't an array.
+func sortedLinks(raw json.RawMessage) json.RawMessage {
+	var links []json.RawMessage
+	if json.Unmarshal(raw, &links) != nil {
+		return raw
+	}
+	type link struct {
+		Name string `json:"name"`
+		raw  json.RawMessage
+	}
+	named := make([]link, len(links))
+	for i, raw := range links {
+		json.Unmarshal(raw, &named[i])
+		named[i].raw = raw
+	}
+	sort.SliceStable(named, func(i, j int) bool { return named[i].Name < named[j].Name })
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, l := range named {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(l.raw)
+	}
+	buf.WriteByte(']')
+	return buf.Bytes()
+}
+
+// FormatFile rewrites the config file at path in canonical form, reporting
+// whether its contents changed. A gzipped file stays gzipped.
+func FormatFile(path string) (bool, error) {
+	changed := false
+	err := withFileLock(path, func() error {
+		data, compressed, err := readConfigFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := Format(data)
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, out) {
+			return nil
+		}
+		changed = true
+		if compressed {
+			if out, err = gzipData(out); err != nil {
+				return err
+			}
+		}
+		return writeFileAtomic(path, out, 0644, false)
+	})
+	return changed, err
+}
+
+// canonicalJSON serializes c with links sorted by name.
+func (c *Config) canonicalJSON() ([]byte, error) {
+	sorted := *c
+	sorted.Links = append([]NeuralLinkConfig(nil), c.Links...)
+	sort.SliceStable(sorted.Links, func(i, j int) bool {
+		return sorted.Links[i].Name < sorted.Links[j].Name
+	})
+	// MarshalIndent sorts map keys and re-indents the raw model bodies.
+	out, err := json.MarshalIndent(&sorted, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// Hash returns the hex SHA-256 of the config's content, for recording which
+// config produced a result. It is computed over a canonical serialization:
+// links sorted by name and every object, including model definitions, with
+// sorted keys and no whitespace. Configs that differ only in formatting,
+// key order or link order hash the same.
+func (c *Config) Hash() (string, error) {
+	data, err := c.canonicalJSON()
+	if err != nil {
+		return "", err
+	}
+	// Round trip through generic values so the keys inside model
+	// definitions are sorted too; UseNumber keeps numbers as written.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return "", err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// withFileLock runs fn while holding an exclusive lock on path.
+func withFileLock(path string, fn func() error) error {
+	unlock, err := lockFile(path, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
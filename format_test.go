@@ -0,0 +1,115 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// activeLinks returns the names of the links that run once c is normalized.
+func activeLinks(c *Config) []string {
+	c.Normalize()
+	var names []string
+	for _, l := range c.Links {
+		if l.Enabled {
+			names = append(names, l.Name)
+		}
+	}
+	return names
+}
+
+func TestFormatFileKeepsActiveLinks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	orig := `{"name":"x","models":{},"links":[` +
+		`{"name":"m","source_model":"a","target_model":"b","link_size":2,"enabled":false},` +
+		`{"name":"l","source_model":"a","target_model":"b","link_size":2}],"bogus":1}`
+	if err := os.WriteFile(path, []byte(orig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	before, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := FormatFile(path); err != nil {
+		t.Fatal(err)
+	} else if !changed {
+		t.Fatal("FormatFile left a compact config unchanged")
+	}
+	after, err := LoadFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := activeLinks(before)
+	if !slices.Equal(want, []string{"l"}) {
+		t.Fatalf("active links before formatting = %v, want [l]", want)
+	}
+	if got := activeLinks(after); !slices.Equal(got, want) {
+		t.Errorf("active links after formatting = %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"bogus": 1`) {
+		t.Errorf("formatting dropped an unknown field:\n%s", data)
+	}
+	if strings.Contains(string(data), "schema_version") {
+		t.Errorf("formatting added fields:\n%s", data)
+	}
+	if changed, err := FormatFile(path); err != nil || changed {
+		t.Errorf("formatting a formatted file: changed = %v, err = %v", changed, err)
+	}
+}
+
+func TestFormatKeepsIncludesAndTemplates(t *testing.T) {
+	in := `{"name": "${name}", "variables": {"name": "swarm"}, "includes": ["links.json"], "models": "${models}"}`
+	want := `{
+  "name": "${name}",
+  "variables": {
+    "name": "swarm"
+  },
+  "includes": [
+    "links.json"
+  ],
+  "models": "${models}"
+}
+`
+	out, err := Format([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Errorf("Format = %s, want %s", out, want)
+	}
+}
+
+func TestFormatSorts(t *testing.T) {
+	in := `{"links": [{"name": "b"}, {"name": "a", "weight": 2.50}], "models": {"z": {}, "a": {"note": "<&>"}}}`
+	want := `{
+  "links": [
+    {
+      "name": "a",
+      "weight": 2.50
+    },
+    {
+      "name": "b"
+    }
+  ],
+  "models": {
+    "a": {
+      "note": "<&>"
+    },
+    "z": {}
+  }
+}
+`
+	out, err := Format([]byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != want {
+		t.Errorf("Format = %s, want %s", out, want)
+	}
+}
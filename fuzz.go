@@ -0,0 +1,182 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ParamBound declares the range an environment parameter is fuzzed over.
+// Default is the nominal value minimization moves parameters back to.
+type ParamBound struct {
+	Name    string  `json:"name"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max"`
+	Default float64 `json:"default"`
+}
+
+// FuzzSpace declares what a scenario fuzzer may randomize.
+type FuzzSpace struct {
+	Params           []ParamBound `json:"params,omitempty"`
+	Links            []string     `json:"links,omitempty"`   // Links eligible for failure injection; empty means all
+	Actions          []string     `json:"actions,omitempty"` // Defaults to disable_link, set_gain and inject_noise
+	MaxInterventions int          `json:"max_interventions"`
+	Horizon          uint64       `json:"horizon"`    // Interventions fire at steps in [0, Horizon)
+	GainRange        [2]float64   `json:"gain_range"` // Defaults to [0, 2]
+	NoiseRange       [2]float64   `json:"noise_range"`
+}
+
+// FuzzCase is one randomized scenario: environment parameters plus
+// interventions added to the base config's scenario.
+type FuzzCase struct {
+	Params        map[string]float64 `json:"params,omitempty"`
+	Interventions []Intervention     `json:"interventions,omitempty"`
+}
+
+// FuzzEval runs the agent under cfg, whose scenario already includes the
+// case's interventions, in an environment configured by params, and returns
+// a performance score where higher is better.
+type FuzzEval func(cfg *Config, params map[string]float64) (float64, error)
+
+// FuzzOptions configures Fuzz.
+type FuzzOptions struct {
+	Iterations int
+	Seed       int64
+	Threshold  float64    // Scores below Threshold count as a collapse
+	Minimize   bool       // Shrink each failing case before reporting it
+	Log        *ResultLog // If set, every failure is appended as it is found
+}
+
+// FuzzFailure is a case under which performance collapsed.
+type FuzzFailure struct {
+	Case      FuzzCase `json:"case"`
+	Score     float64  `json:"score"`
+	Minimized FuzzCase `json:"minimized"` // Equal to Case unless Minimize is set
+	MinScore  float64  `json:"min_score"`
+}
+
+// Fuzz samples cases from space, evaluates each against base and returns
+// the ones whose score falls below opts.Threshold.
+func Fuzz(base *Config, space FuzzSpace, eval FuzzEval, opts FuzzOptions) ([]FuzzFailure, error) {
+	rng := seededStream(opts.Seed, StreamEnvironment)
+	var failures []FuzzFailure
+	for i := 0; i < opts.Iterations; i++ {
+		c := space.sample(base, rng)
+		score, err := c.run(base, eval)
+		if err != nil {
+			return failures, fmt.Errorf("case %d: %w", i, err)
+		}
+		if score >= opts.Threshold {
+			continue
+		}
+		f := FuzzFailure{Case: c, Score: score, Minimized: c, MinScore: score}
+		if opts.Minimize {
+			if f.Minimized, f.MinScore, err = minimizeCase(base, space, c, score, eval, opts.Threshold); err != nil {
+				return failures, fmt.Errorf("case %d: minimize: %w", i, err)
+			}
+		}
+		if opts.Log != nil {
+			if err := opts.Log.Append(f); err != nil {
+				return failures, err
+			}
+		}
+		failures = append(failures, f)
+	}
+	return failures, nil
+}
+
+// Config returns base with the case's interventions added to its scenario.
+func (c FuzzCase) Config(base *Config) *Config {
+	cfg := *base
+	cfg.Scenario = append(append([]Intervention(nil), base.Scenario...), c.Interventions...)
+	return &cfg
+}
+
+func (c FuzzCase) run(base *Config, eval FuzzEval) (float64, error) {
+	return eval(c.Config(base), c.Params)
+}
+
+func (s FuzzSpace) sample(base *Config, rng Rand) FuzzCase {
+	c := FuzzCase{Params: make(map[string]float64, len(s.Params))}
+	for _, p := range s.Params {
+		c.Params[p.Name] = p.Min + rng.Float64()*(p.Max-p.Min)
+	}
+
+	links := s.Links
+	if len(links) == 0 {
+		for _, l := range base.Links {
+			links = append(links, l.Name)
+		}
+	}
+	actions := s.Actions
+	if len(actions) == 0 {
+		actions = []string{ActionDisableLink, ActionSetGain, ActionInjectNoise}
+	}
+	gain := s.GainRange
+	if gain == [2]float64{} {
+		gain = [2]float64{0, 2}
+	}
+	if len(links) == 0 || s.MaxInterventions <= 0 {
+		return c
+	}
+
+	n := rng.Intn(s.MaxInterventions + 1)
+	for i := 0; i < n; i++ {
+		iv := Intervention{Action: actions[rng.Intn(len(actions))], Target: links[rng.Intn(len(links))]}
+		if s.Horizon > 0 {
+			iv.AtStep = uint64(rng.Int63()) % s.Horizon
+		}
+		switch iv.Action {
+		case ActionSetGain:
+			iv.Value = gain[0] + rng.Float64()*(gain[1]-gain[0])
+		case ActionInjectNoise:
+			iv.Value = s.NoiseRange[0] + rng.Float64()*(s.NoiseRange[1]-s.NoiseRange[0])
+		}
+		c.Interventions = append(c.Interventions, iv)
+	}
+	sort.SliceStable(c.Interventions, func(i, j int) bool {
+		return c.Interventions[i].AtStep < c.Interventions[j].AtStep
+	})
+	return c
+}
+
+// minimizeCase greedily drops interventions and resets parameters to their
+// defaults while the case keeps failing.
+func minimizeCase(base *Config, space FuzzSpace, c FuzzCase, score float64, eval FuzzEval, threshold float64) (FuzzCase, float64, error) {
+	try := func(cand FuzzCase) (bool, error) {
+		s, err := cand.run(base, eval)
+		if err != nil {
+			return false, err
+		}
+		if s < threshold {
+			c, score = cand, s
+			return true, nil
+		}
+		return false, nil
+	}
+
+	for i := 0; i < len(c.Interventions); {
+		cand := FuzzCase{Params: c.Params}
+		cand.Interventions = append(append([]Intervention(nil), c.Interventions[:i]...), c.Interventions[i+1:]...)
+		ok, err := try(cand)
+		if err != nil {
+			return c, score, err
+		}
+		if !ok {
+			i++
+		}
+	}
+	for _, p := range space.Params {
+		if c.Params[p.Name] == p.Default {
+			continue
+		}
+		params := make(map[string]float64, len(c.Params))
+		for k, v := range c.Params {
+			params[k] = v
+		}
+		params[p.Name] = p.Default
+		if _, err := try(FuzzCase{Params: params, Interventions: c.Interventions}); err != nil {
+			return c, score, err
+		}
+	}
+	return c, score, nil
+}
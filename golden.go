@@ -0,0 +1,67 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrUnseeded is returned when golden outputs are requested for a config
+// without a Seed, whose initial weights differ on every run.
+var ErrUnseeded = errors.New("drift: config has no seed")
+
+// GenerateGolden runs a seeded config for steps steps on the inputs produced
+// by inputs and returns the per-step records, suitable for WriteGolden.
+func GenerateGolden(cfg *Config, steps int, inputs func(step int) map[string][]float32) ([]StepRecord, error) {
+	if cfg.Seed == 0 {
+		return nil, ErrUnseeded
+	}
+	r, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rec := NewRecorder(nil, 0)
+	r.SetRecorder(rec)
+	for step := 0; step < steps; step++ {
+		var in map[string][]float32
+		if inputs != nil {
+			in = inputs(step)
+		}
+		if _, err := r.Step(in); err != nil {
+			return nil, fmt.Errorf("step %d: %w", step, err)
+		}
+	}
+	return rec.Steps(), nil
+}
+
+// WriteGolden atomically writes records to path as JSON Lines, readable with
+// LoadRecording.
+func WriteGolden(path string, records []StepRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0644, false)
+}
+
+// CheckGolden replays the inputs stored in the golden file at path through a
+// fresh runtime for cfg and returns the first output that moved by more than
+// tol, or nil if every step still matches.
+func CheckGolden(cfg *Config, path string, tol float64) (*Divergence, error) {
+	if cfg.Seed == 0 {
+		return nil, ErrUnseeded
+	}
+	golden, err := LoadRecording(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return DiffTrace(r, golden, tol)
+}
@@ -0,0 +1,251 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// ModelDegree counts the enabled links at one model.
+type ModelDegree struct {
+	Model    string `json:"model"`
+	In       int    `json:"in"`
+	Out      int    `json:"out"`
+	InWidth  int    `json:"in_width"` // Total LinkSize of incoming links
+	OutWidth int    `json:"out_width"`
+}
+
+// Bottleneck is a model many paths between other models run through.
+type Bottleneck struct {
+	Model       string  `json:"model"`
+	Betweenness float64 `json:"betweenness"` // Fraction of shortest paths between other models passing through it
+}
+
+// GraphMetrics describes the topology of the model-link graph.
+type GraphMetrics struct {
+	Models        int           `json:"models"`
+	Links         int           `json:"links"`
+	Degrees       []ModelDegree `json:"degrees"`         // By model name
+	Loops         [][]string    `json:"loops"`           // Strongly connected components that form communication loops
+	Reachable     int           `json:"reachable"`       // Ordered pairs of distinct models connected by a path
+	AvgPathLength float64       `json:"avg_path_length"` // Mean hops over reachable pairs
+	Diameter      int           `json:"diameter"`        // Longest shortest path
+	Bottlenecks   []Bottleneck  `json:"bottlenecks"`     // Models with non-zero betweenness, most central first
+	CutLinks      []string      `json:"cut_links"`       // Links whose loss disconnects some pair of models
+}
+
+// AnalyzeGraph computes graph metrics over cfg's models and its enabled
+// links; disabled links carry nothing and are left out. Namespaced model
+// references are resolved as by NewRuntime.
+func AnalyzeGraph(cfg *Config) *GraphMetrics {
+	nodes := sortedKeys(cfg.Models)
+	g := &GraphMetrics{Models: len(nodes)}
+	degree := make(map[string]*ModelDegree, len(nodes))
+	for _, name := range nodes {
+		degree[name] = &ModelDegree{Model: name}
+	}
+	adj := make(map[string]map[string][]string) // source -> target -> link names
+	for _, l := range cfg.Links {
+		if !l.Enabled {
+			continue
+		}
+		l = cfg.resolveLink(l)
+		src, dst := degree[l.SourceModel], degree[l.TargetModel]
+		if src == nil || dst == nil {
+			continue
+		}
+		g.Links++
+		src.Out++
+		src.OutWidth += l.LinkSize
+		dst.In++
+		dst.InWidth += l.LinkSize
+		if adj[l.SourceModel] == nil {
+			adj[l.SourceModel] = make(map[string][]string)
+		}
+		adj[l.SourceModel][l.TargetModel] = append(adj[l.SourceModel][l.TargetModel], l.Name)
+	}
+	for _, name := range nodes {
+		g.Degrees = append(g.Degrees, *degree[name])
+	}
+	succ := func(u string) []string { return sortedKeys(adj[u]) }
+
+	// Loops: components of more than one model, or one linked to itself.
+	for _, comp := range stronglyConnected(nodes, succ) {
+		if len(comp) > 1 || len(adj[comp[0]][comp[0]]) > 0 {
+			g.Loops = append(g.Loops, comp)
+		}
+	}
+
+	// Path lengths and betweenness (Brandes) from a BFS per source.
+	between := make(map[string]float64, len(nodes))
+	total := 0
+	for _, s := range nodes {
+		dist := map[string]int{s: 0}
+		paths := map[string]float64{s: 1}
+		preds := make(map[string][]string)
+		var order []string
+		for queue := []string{s}; len(queue) > 0; queue = queue[1:] {
+			u := queue[0]
+			order = append(order, u)
+			for _, v := range succ(u) {
+				if _, seen := dist[v]; !seen {
+					dist[v] = dist[u] + 1
+					queue = append(queue, v)
+				}
+				if dist[v] == dist[u]+1 {
+					paths[v] += paths[u]
+					preds[v] = append(preds[v], u)
+				}
+			}
+		}
+		for v, d := range dist {
+			if v != s {
+				g.Reachable++
+				total += d
+				g.Diameter = max(g.Diameter, d)
+			}
+		}
+		delta := make(map[string]float64, len(order))
+		for i := len(order) - 1; i > 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += paths[v] / paths[w] * (1 + delta[w])
+			}
+			between[w] += delta[w]
+		}
+	}
+	if g.Reachable > 0 {
+		g.AvgPathLength = float64(total) / float64(g.Reachable)
+	}
+	if n := len(nodes); n > 2 {
+		for _, name := range nodes {
+			if b := between[name] / float64((n-1)*(n-2)); b > 0 {
+				g.Bottlenecks = append(g.Bottlenecks, Bottleneck{Model: name, Betweenness: b})
+			}
+		}
+		sort.SliceStable(g.Bottlenecks, func(i, j int) bool {
+			return g.Bottlenecks[i].Betweenness > g.Bottlenecks[j].Betweenness
+		})
+	}
+
+	// A link is a cut when it is the only link of its pair and dropping the
+	// pair loses reachability.
+	for _, u := range nodes {
+		for _, v := range succ(u) {
+			if u == v || len(adj[u][v]) != 1 {
+				continue
+			}
+			without := func(x string) []string {
+				out := succ(x)
+				if x == u {
+					out = removeString(out, v)
+				}
+				return out
+			}
+			if reachablePairs(nodes, without) < g.Reachable {
+				g.CutLinks = append(g.CutLinks, adj[u][v][0])
+			}
+		}
+	}
+	sort.Strings(g.CutLinks)
+	return g
+}
+
+// String renders the metrics as a plain-text summary.
+func (g *GraphMetrics) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d models, %d enabled links, %d reachable pairs, avg path %.2f, diameter %d\n",
+		g.Models, g.Links, g.Reachable, g.AvgPathLength, g.Diameter)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "model\tin\tout\tin width\tout width")
+	for _, d := range g.Degrees {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\n", d.Model, d.In, d.Out, d.InWidth, d.OutWidth)
+	}
+	tw.Flush()
+	for _, loop := range g.Loops {
+		fmt.Fprintf(&b, "loop: %s\n", strings.Join(loop, " <-> "))
+	}
+	for _, bn := range g.Bottlenecks {
+		fmt.Fprintf(&b, "bottleneck: %s (betweenness %.2f)\n", bn.Model, bn.Betweenness)
+	}
+	for _, l := range g.CutLinks {
+		fmt.Fprintf(&b, "cut link: %s\n", l)
+	}
+	return b.String()
+}
+
+// stronglyConnected returns the strongly connected components of the graph
+// (Tarjan's algorithm), each sorted, in order of their first model.
+func stronglyConnected(nodes []string, succ func(string) []string) [][]string {
+	index := make(map[string]int, len(nodes))
+	low := make(map[string]int, len(nodes))
+	onStack := make(map[string]bool, len(nodes))
+	var stack []string
+	var comps [][]string
+	var visit func(string)
+	visit = func(u string) {
+		index[u] = len(index)
+		low[u] = index[u]
+		stack = append(stack, u)
+		onStack[u] = true
+		for _, v := range succ(u) {
+			if _, seen := index[v]; !seen {
+				visit(v)
+				low[u] = min(low[u], low[v])
+			} else if onStack[v] {
+				low[u] = min(low[u], index[v])
+			}
+		}
+		if low[u] != index[u] {
+			return
+		}
+		var comp []string
+		for {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[v] = false
+			comp = append(comp, v)
+			if v == u {
+				break
+			}
+		}
+		sort.Strings(comp)
+		comps = append(comps, comp)
+	}
+	for _, u := range nodes {
+		if _, seen := index[u]; !seen {
+			visit(u)
+		}
+	}
+	sort.Slice(comps, func(i, j int) bool { return comps[i][0] < comps[j][0] })
+	return comps
+}
+
+// reachablePairs counts ordered pairs of distinct nodes joined by a path.
+func reachablePairs(nodes []string, succ func(string) []string) int {
+	n := 0
+	for _, s := range nodes {
+		seen := map[string]bool{s: true}
+		for queue := []string{s}; len(queue) > 0; queue = queue[1:] {
+			for _, v := range succ(queue[0]) {
+				if !seen[v] {
+					seen[v] = true
+					queue = append(queue, v)
+				}
+			}
+		}
+		n += len(seen) - 1
+	}
+	return n
+}
+
+func removeString(list []string, s string) []string {
+	out := list[:0:0]
+	for _, e := range list {
+		if e != s {
+			out = append(out, e)
+		}
+	}
+	return out
+}
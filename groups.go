@@ -0,0 +1,79 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Model names may be namespaced with slashes, as in "perception/classifier",
+// to organize large swarms into groups. Links can refer to a namespaced
+// model by any unambiguous suffix of its path, so "classifier" resolves to
+// "perception/classifier" when no other model shares that name.
+
+// ModelGroup returns the group of a namespaced model name, "perception" for
+// "perception/classifier", or "" for a name without a namespace.
+func ModelGroup(name string) string {
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// GetModelsInGroup returns the sorted names of the models in group and its
+// subgroups.
+func (c *Config) GetModelsInGroup(group string) []string {
+	prefix := strings.TrimSuffix(group, "/") + "/"
+	var names []string
+	for name := range c.Models {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddModelToGroup adds a model under group, naming it group/name.
+func (c *Config) AddModelToGroup(group, name string, model interface{}) error {
+	if group == "" {
+		return c.AddModel(name, model)
+	}
+	return c.AddModel(strings.TrimSuffix(group, "/")+"/"+name, model)
+}
+
+// ResolveModel returns the full name of the model ref refers to: ref itself
+// when a model has that name, otherwise the single model whose name ends in
+// "/" + ref. It fails when no model or several match.
+func (c *Config) ResolveModel(ref string) (string, error) {
+	if _, ok := c.Models[ref]; ok {
+		return ref, nil
+	}
+	var matches []string
+	for name := range c.Models {
+		if strings.HasSuffix(name, "/"+ref) {
+			matches = append(matches, name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("unknown model %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	sort.Strings(matches)
+	return "", fmt.Errorf("ambiguous model %q matches %s", ref, strings.Join(matches, ", "))
+}
+
+// resolveLink returns l with its model references replaced by full names.
+// References that don't resolve are left as they are for validation to
+// report.
+func (c *Config) resolveLink(l NeuralLinkConfig) NeuralLinkConfig {
+	if name, err := c.ResolveModel(l.SourceModel); err == nil {
+		l.SourceModel = name
+	}
+	if name, err := c.ResolveModel(l.TargetModel); err == nil {
+		l.TargetModel = name
+	}
+	return l
+}
@@ -0,0 +1,49 @@
+package drift
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+)
+
+// gzipMagic starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SaveToFileCompressed saves the config like SaveToFile, gzip-compressed
+// whatever the name of path. LoadFromFile reads it back transparently.
+// Configs embedding many model definitions typically shrink tenfold.
+func (c *Config) SaveToFileCompressed(path string) error {
+	return c.saveLocked(path, false, true)
+}
+
+// isGzipPath reports whether path names a gzipped file, e.g. "swarm.json.gz".
+func isGzipPath(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".gz")
+}
+
+// isGzip reports whether data starts with a gzip header.
+func isGzip(data []byte) bool {
+	return bytes.HasPrefix(data, gzipMagic)
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
@@ -0,0 +1,123 @@
+package drift
+
+import "time"
+
+// WindowMetrics tracks benchmark performance over one fixed-length time window.
+type WindowMetrics struct {
+	WindowNum      int     `json:"window"`
+	Terrain        string  `json:"terrain"`
+	TargetsReached int     `json:"targets"`
+	TotalSteps     int     `json:"steps"`
+	EffectiveMoves int     `json:"effective_moves"`
+	Accuracy       float64 `json:"accuracy_pct"`
+}
+
+// ExperimentResult holds the benchmark results of one training mode.
+type ExperimentResult struct {
+	Mode           string             `json:"mode"`
+	Windows        []WindowMetrics    `json:"windows"`
+	TotalTargets   int                `json:"total_targets"`
+	TotalSteps     int                `json:"total_steps"`
+	FinalAccuracy  float64            `json:"final_accuracy_pct"`
+	TerrainResults map[string]int     `json:"targets_by_terrain"`
+	Scores         map[string]float64 `json:"scores,omitempty"`      // By objective; see Score
+	Transitions    []Transition       `json:"transitions,omitempty"` // Recovery after each terrain change
+}
+
+// WindowRecord is the incremental form of a window written to a ResultLog,
+// tagged with the mode it belongs to and, in logs kept across runs, the run.
+type WindowRecord struct {
+	Run     string    `json:"run,omitempty"`
+	Started time.Time `json:"started,omitzero"` // When the run started
+	Mode    string    `json:"mode"`
+	WindowMetrics
+}
+
+// Metrics converts the window to metrics stamped with the window number and
+// labeled with mode and terrain, for sending to a MetricSink.
+func (w WindowMetrics) Metrics(mode string) []Metric {
+	labels := map[string]string{"mode": mode, "terrain": w.Terrain}
+	step := uint64(w.WindowNum)
+	return []Metric{
+		{Name: "window_targets", Value: float64(w.TargetsReached), Step: step, Labels: labels},
+		{Name: "window_effective_moves", Value: float64(w.EffectiveMoves), Step: step, Labels: labels},
+		{Name: "window_accuracy_pct", Value: w.Accuracy, Step: step, Labels: labels},
+	}
+}
+
+// DefaultWindow is the length of a benchmark window.
+const DefaultWindow = 500 * time.Millisecond
+
+// WindowTracker accumulates the outcome of every step into fixed-length
+// time windows. It is the measurement shared by simulated benchmarks and
+// hardware trials, so their results compare directly. A window is closed by
+// the first step at or after its end; a partial window at the end of a run
+// is not reported.
+type WindowTracker struct {
+	Window   time.Duration       // Window length; DefaultWindow when zero
+	OnWindow func(WindowMetrics) // Called as each window closes, e.g. to log it
+
+	cur   WindowMetrics // Window in progress
+	res   ExperimentResult
+	start time.Time
+}
+
+// NewWindowTracker starts tracking a run of mode now.
+func NewWindowTracker(mode string, window time.Duration) *WindowTracker {
+	return &WindowTracker{
+		Window: window,
+		res:    ExperimentResult{Mode: mode, Windows: []WindowMetrics{}, TerrainResults: make(map[string]int)},
+		start:  time.Now(),
+	}
+}
+
+// Observe records one step taken under terrain: whether the action made
+// progress and whether it reached a target.
+func (w *WindowTracker) Observe(terrain string, effective, reached bool) {
+	cur := &w.cur
+	cur.TotalSteps++
+	w.res.TotalSteps++
+	if effective {
+		cur.EffectiveMoves++
+	}
+	if reached {
+		cur.TargetsReached++
+		w.res.TotalTargets++
+		w.res.TerrainResults[terrain]++
+	}
+	window := w.Window
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	if time.Since(w.start) < window {
+		return
+	}
+	cur.WindowNum = len(w.res.Windows)
+	cur.Terrain = terrain
+	cur.Accuracy = float64(cur.EffectiveMoves) / float64(cur.TotalSteps) * 100
+	w.res.Windows = append(w.res.Windows, *cur)
+	if w.OnWindow != nil {
+		w.OnWindow(*cur)
+	}
+	w.cur = WindowMetrics{}
+	w.start = time.Now()
+}
+
+// Result returns the run so far, with FinalAccuracy over the closed windows.
+func (w *WindowTracker) Result() ExperimentResult {
+	res := w.res
+	res.Windows = append([]WindowMetrics{}, w.res.Windows...)
+	res.TerrainResults = make(map[string]int, len(w.res.TerrainResults))
+	for k, v := range w.res.TerrainResults {
+		res.TerrainResults[k] = v
+	}
+	effective, steps := 0, 0
+	for _, win := range res.Windows {
+		effective += win.EffectiveMoves
+		steps += win.TotalSteps
+	}
+	if steps > 0 {
+		res.FinalAccuracy = float64(effective) / float64(steps) * 100
+	}
+	return res
+}
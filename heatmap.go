@@ -0,0 +1,246 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"sort"
+)
+
+// HeatmapPanel is one activation series over time: Rows[t] holds the values
+// at the t-th recorded step.
+type HeatmapPanel struct {
+	Title string      `json:"title"`
+	Steps []uint64    `json:"steps"`
+	Rows  [][]float32 `json:"rows"`
+}
+
+// LinkHeatmap extracts the payloads of link from a recording.
+func LinkHeatmap(steps []StepRecord, link string) HeatmapPanel {
+	return heatmapPanel("link "+link, steps, func(s StepRecord) []float32 { return s.Links[link] })
+}
+
+// OutputHeatmap extracts the outputs of model from a recording.
+func OutputHeatmap(steps []StepRecord, model string) HeatmapPanel {
+	return heatmapPanel("output "+model, steps, func(s StepRecord) []float32 { return s.Outputs[model] })
+}
+
+// LayerHeatmap extracts the outputs of a model's layer from a recording made
+// with Recorder.Layers set. Layers count from 1, as in link SourceLayer.
+func LayerHeatmap(steps []StepRecord, model string, layer int) HeatmapPanel {
+	title := fmt.Sprintf("%s layer %d", model, layer)
+	return heatmapPanel(title, steps, func(s StepRecord) []float32 {
+		if l := s.Layers[model]; layer >= 1 && layer <= len(l) {
+			return l[layer-1]
+		}
+		return nil
+	})
+}
+
+// HeatmapPanels returns a panel for every link, model output and recorded
+// layer in steps, in that order and sorted by name.
+func HeatmapPanels(steps []StepRecord) []HeatmapPanel {
+	links, models := map[string]bool{}, map[string]int{}
+	for _, s := range steps {
+		for name := range s.Links {
+			links[name] = true
+		}
+		for name := range s.Outputs {
+			if _, ok := models[name]; !ok {
+				models[name] = 0
+			}
+		}
+		for name, l := range s.Layers {
+			if len(l) > models[name] {
+				models[name] = len(l)
+			}
+		}
+	}
+
+	var panels []HeatmapPanel
+	for _, name := range sortedKeys(links) {
+		panels = append(panels, LinkHeatmap(steps, name))
+	}
+	for _, name := range sortedKeys(models) {
+		panels = append(panels, OutputHeatmap(steps, name))
+		for layer := 1; layer <= models[name]; layer++ {
+			panels = append(panels, LayerHeatmap(steps, name, layer))
+		}
+	}
+	return panels
+}
+
+// WriteHeatmapPNG renders p with time along the x axis and units along the y
+// axis, cell pixels per value, on a blue-white-red scale symmetric around zero.
+func WriteHeatmapPNG(w io.Writer, p HeatmapPanel, cell int) error {
+	if cell <= 0 {
+		cell = 1
+	}
+	units := p.width()
+	if len(p.Rows) == 0 || units == 0 {
+		return fmt.Errorf("heatmap %q is empty", p.Title)
+	}
+	scale := p.maxAbs()
+	img := image.NewRGBA(image.Rect(0, 0, len(p.Rows)*cell, units*cell))
+	for t, row := range p.Rows {
+		for u := 0; u < units; u++ {
+			c := color.RGBA{A: 255}
+			if u < len(row) {
+				c = divergingColor(row[u], scale)
+			}
+			for dx := 0; dx < cell; dx++ {
+				for dy := 0; dy < cell; dy++ {
+					img.SetRGBA(t*cell+dx, u*cell+dy, c)
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// WriteHeatmapHTML writes a self-contained HTML page drawing every panel on a
+// canvas, with the step and value under the cursor shown on hover. NaN and Inf
+// are drawn green, as in WriteHeatmapPNG.
+func WriteHeatmapHTML(w io.Writer, title string, panels []HeatmapPanel) error {
+	// JSON has no NaN or Inf, so non-finite values are sent as null.
+	type jsonPanel struct {
+		Title string       `json:"title"`
+		Steps []uint64     `json:"steps"`
+		Rows  [][]*float32 `json:"rows"`
+	}
+	out := make([]jsonPanel, len(panels))
+	for i, p := range panels {
+		out[i] = jsonPanel{Title: p.Title, Steps: p.Steps, Rows: make([][]*float32, len(p.Rows))}
+		for t, row := range p.Rows {
+			if row == nil {
+				continue
+			}
+			out[i].Rows[t] = make([]*float32, len(row))
+			for u := range row {
+				if isFinite(row[u]) {
+					out[i].Rows[t][u] = &row[u]
+				}
+			}
+		}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	return heatmapTemplate.Execute(w, struct {
+		Title  string
+		Panels template.JS
+	}{title, template.JS(data)})
+}
+
+func heatmapPanel(title string, steps []StepRecord, get func(StepRecord) []float32) HeatmapPanel {
+	p := HeatmapPanel{Title: title}
+	for _, s := range steps {
+		p.Steps = append(p.Steps, s.Step)
+		p.Rows = append(p.Rows, get(s))
+	}
+	return p
+}
+
+func (p HeatmapPanel) width() int {
+	n := 0
+	for _, row := range p.Rows {
+		if len(row) > n {
+			n = len(row)
+		}
+	}
+	return n
+}
+
+func (p HeatmapPanel) maxAbs() float32 {
+	var m float32
+	for _, row := range p.Rows {
+		for _, v := range row {
+			if a := float32(math.Abs(float64(v))); a > m && isFinite(v) {
+				m = a
+			}
+		}
+	}
+	if m == 0 {
+		m = 1
+	}
+	return m
+}
+
+// divergingColor maps v in [-scale, scale] to blue, white and red.
+func divergingColor(v, scale float32) color.RGBA {
+	if !isFinite(v) {
+		return color.RGBA{G: 255, A: 255}
+	}
+	x := v / scale
+	if x > 1 {
+		x = 1
+	} else if x < -1 {
+		x = -1
+	}
+	fade := uint8(255 * (1 - float32(math.Abs(float64(x)))))
+	if x >= 0 {
+		return color.RGBA{R: 255, G: fade, B: fade, A: 255}
+	}
+	return color.RGBA{R: fade, G: fade, B: 255, A: 255}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var heatmapTemplate = template.Must(template.New("heatmap").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:sans-serif;margin:1em}
+h2{font-size:1em;margin:1.2em 0 .3em}
+canvas{image-rendering:pixelated;border:1px solid #ccc}
+#tip{position:fixed;background:#fff;border:1px solid #999;padding:2px 4px;font-size:12px;display:none}
+</style></head>
+<body><h1>{{.Title}}</h1><div id="panels"></div><div id="tip"></div>
+<script>
+const panels = {{.Panels}};
+const tip = document.getElementById("tip");
+function color(v, s) {
+  if (v === null) return [0, 255, 0];
+  const x = Math.max(-1, Math.min(1, v / s)), f = Math.round(255 * (1 - Math.abs(x)));
+  return x >= 0 ? [255, f, f] : [f, f, 255];
+}
+for (const p of panels) {
+  const rows = p.rows || [], units = Math.max(0, ...rows.map(r => (r || []).length));
+  if (!rows.length || !units) continue;
+  let s = 0;
+  for (const r of rows) for (const v of (r || [])) if (v !== null) s = Math.max(s, Math.abs(v));
+  s = s || 1;
+  const h = document.createElement("h2"); h.textContent = p.title + " (|max| " + s.toPrecision(3) + ")";
+  const c = document.createElement("canvas"); c.width = rows.length; c.height = units;
+  const zoom = Math.max(1, Math.floor(900 / rows.length));
+  c.style.width = rows.length * zoom + "px"; c.style.height = units * Math.max(4, zoom) + "px";
+  const ctx = c.getContext("2d"), img = ctx.createImageData(rows.length, units);
+  rows.forEach((r, t) => { for (let u = 0; u < units; u++) {
+    const [R, G, B] = r && u < r.length ? color(r[u], s) : [0, 0, 0], i = 4 * (u * rows.length + t);
+    img.data[i] = R; img.data[i + 1] = G; img.data[i + 2] = B; img.data[i + 3] = 255;
+  }});
+  ctx.putImageData(img, 0, 0);
+  c.onmousemove = e => {
+    const b = c.getBoundingClientRect();
+    const t = Math.floor((e.clientX - b.left) / b.width * rows.length), u = Math.floor((e.clientY - b.top) / b.height * units);
+    const v = rows[t] && rows[t][u];
+    tip.style.display = "block"; tip.style.left = e.clientX + 12 + "px"; tip.style.top = e.clientY + 12 + "px";
+    tip.textContent = "step " + p.steps[t] + ", unit " + u + ": " + (v === null ? "NaN or Inf" : v);
+  };
+  c.onmouseleave = () => { tip.style.display = "none"; };
+  document.getElementById("panels").append(h, c);
+}
+</script></body></html>
+`))
@@ -0,0 +1,212 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Actuator drives real hardware with the agent's actions.
+type Actuator interface {
+	// Act carries out the action encoded by a model's output vector.
+	Act(ctx context.Context, action []float32) error
+	// Stop brings the hardware to a safe state. It is called once at the end
+	// of every trial, however the trial ends.
+	Stop() error
+}
+
+// Sensor reads observations from real hardware.
+type Sensor interface {
+	// Observe returns the current reading, including the outcome of the
+	// previous action.
+	Observe(ctx context.Context) (Observation, error)
+}
+
+// Observation is one sensor reading of a hardware trial.
+type Observation struct {
+	Inputs  map[string][]float32 // External model inputs, as passed to Runtime.Step
+	Terrain string               // Current condition, labeling the benchmark windows
+
+	// Outcome of the previous action; ignored on the first reading.
+	Effective bool // It made progress toward the target
+	Reached   bool // It reached the target
+
+	// Teach, in learning modes, trains the acting model toward Target, the
+	// action class it should have taken on the previous step.
+	Teach  bool
+	Target int
+
+	Done bool // The trial is over, e.g. the course is complete
+}
+
+// Hardware trial errors.
+var (
+	ErrAborted  = errors.New("drift: hardware trial aborted")
+	ErrWatchdog = errors.New("drift: hardware watchdog expired")
+)
+
+// HardwareTrial benchmarks an agent on real hardware: each control cycle
+// reads the Sensor, steps the runtime and sends the acting model's output to
+// the Actuator. Outcomes are measured in windows by a WindowTracker, exactly
+// as in simulated benchmarks, so Run can serve as an Environment's Benchmark.
+//
+// A trial ends when its Duration elapses, the sensor reports Done, ctx ends,
+// Abort is called, an Interlock trips or a cycle overruns the Watchdog. The
+// actuator is stopped in every case, and the windows closed so far are
+// returned along with the error.
+type HardwareTrial struct {
+	Actuator Actuator
+	Sensor   Sensor
+	Model    string        // Model whose output is the action
+	Duration time.Duration // Trial length; zero runs until Done, an abort or ctx ends
+	Period   time.Duration // Minimum cycle time; zero cycles as fast as the hardware allows
+	Window   time.Duration // Benchmark window length; DefaultWindow when zero
+	LR       float32       // Online learning rate; defaults to 0.01
+
+	// Watchdog bounds one cycle, from reading the sensor to the actuator
+	// accepting the action; zero disables it. The sensor and actuator see it
+	// as their context's deadline.
+	Watchdog time.Duration
+
+	// Interlocks inspect every reading before the agent acts on it. An error
+	// aborts the trial, e.g. when a bumper or tilt sensor trips.
+	Interlocks []func(Observation) error
+
+	OnWindow func(WindowMetrics) // Called as each window closes
+
+	mu      sync.Mutex
+	abort   chan struct{}
+	aborted error
+}
+
+// Abort stops a running trial at the end of its current cycle, reporting
+// reason. It is safe to call from any goroutine, e.g. an e-stop handler,
+// and before Run, which then aborts at once.
+func (h *HardwareTrial) Abort(reason string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.aborted != nil {
+		return
+	}
+	h.aborted = fmt.Errorf("%w: %s", ErrAborted, reason)
+	close(h.abortChan())
+}
+
+// abortChan returns the channel closed by Abort. The caller holds h.mu.
+func (h *HardwareTrial) abortChan() chan struct{} {
+	if h.abort == nil {
+		h.abort = make(chan struct{})
+	}
+	return h.abort
+}
+
+// Run runs the trial under mode on t's runtime, which must already have the
+// mode's links switched; with mode.Learn set the acting model learns from
+// observations that Teach.
+func (h *HardwareTrial) Run(ctx context.Context, t *Trainer, mode BenchmarkMode) (res ExperimentResult, err error) {
+	if h.Actuator == nil || h.Sensor == nil {
+		return ExperimentResult{}, fmt.Errorf("hardware trial needs an actuator and a sensor")
+	}
+	if t.Runtime.Network(h.Model) == nil {
+		return ExperimentResult{}, fmt.Errorf("model %q not found", h.Model)
+	}
+	h.mu.Lock()
+	abort := h.abortChan()
+	h.mu.Unlock()
+
+	lr := h.LR
+	if lr == 0 {
+		lr = 0.01
+	}
+	tracker := NewWindowTracker(mode.Name, h.Window)
+	tracker.OnWindow = h.OnWindow
+	defer func() {
+		if serr := h.Actuator.Stop(); serr != nil {
+			err = errors.Join(err, fmt.Errorf("stopping actuator: %w", serr))
+		}
+		res = tracker.Result()
+	}()
+
+	start := time.Now()
+	var input []float32
+	for first := true; h.Duration <= 0 || time.Since(start) < h.Duration; first = false {
+		select {
+		case <-abort:
+			h.mu.Lock()
+			err := h.aborted
+			h.mu.Unlock()
+			return res, err
+		case <-ctx.Done():
+			return res, ctx.Err()
+		default:
+		}
+		cycle := time.Now()
+		obs, err := h.cycle(ctx, t.Runtime)
+		if err != nil {
+			return res, err
+		}
+		if !first {
+			tracker.Observe(obs.Terrain, obs.Effective, obs.Reached)
+			if mode.Learn && obs.Teach {
+				t.update(h.Model, input, obs.Target, lr)
+			}
+		}
+		if obs.Done {
+			break
+		}
+		input = t.Runtime.Input(h.Model)
+		if wait := h.Period - time.Since(cycle); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-abort:
+			case <-ctx.Done():
+			}
+		}
+	}
+	return res, nil
+}
+
+// cycle reads the sensor and, unless the reading ends the trial, steps r
+// and acts on the result. It returns the reading.
+func (h *HardwareTrial) cycle(ctx context.Context, r *Runtime) (Observation, error) {
+	if h.Watchdog > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Watchdog)
+		defer cancel()
+	}
+	watchdog := func(stage string, err error) error {
+		if h.Watchdog > 0 && errors.Is(err, context.DeadlineExceeded) {
+			return fmt.Errorf("%w: %s took over %v", ErrWatchdog, stage, h.Watchdog)
+		}
+		return fmt.Errorf("%s: %w", stage, err)
+	}
+
+	obs, err := h.Sensor.Observe(ctx)
+	if err != nil {
+		return obs, watchdog("sensor", err)
+	}
+	if obs.Done {
+		return obs, nil
+	}
+	for _, check := range h.Interlocks {
+		if err := check(obs); err != nil {
+			return obs, fmt.Errorf("%w: interlock: %v", ErrAborted, err)
+		}
+	}
+	out, err := r.Step(obs.Inputs)
+	if err != nil {
+		return obs, err
+	}
+	if err := ctx.Err(); err != nil {
+		return obs, watchdog("step", err)
+	}
+	if err := h.Actuator.Act(ctx, out[h.Model]); err != nil {
+		return obs, watchdog("actuator", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return obs, watchdog("actuator", err)
+	}
+	return obs, nil
+}
@@ -0,0 +1,95 @@
+package drift
+
+import "math"
+
+// Homeostasis regulates a link's gain to keep the activations it injects
+// within a range, so a source that drifts over a long run can't saturate
+// its target's inputs. Statistics are running averages over roughly the
+// last 1/Rate steps; when they leave the range the gain is nudged back
+// towards it. The regulator owns the gain: manual and scenario gain
+// changes are corrected like any other drift.
+type Homeostasis struct {
+	MinStd  float64 `json:"min_std,omitempty"`  // Lower bound on the standard deviation of injected values
+	MaxStd  float64 `json:"max_std,omitempty"`  // Upper bound on the standard deviation; 0 for none
+	MaxMean float64 `json:"max_mean,omitempty"` // Upper bound on the absolute mean; 0 for none
+	Rate    float64 `json:"rate,omitempty"`     // Adaptation rate per step; 0 means 0.01
+	MinGain float64 `json:"min_gain,omitempty"` // Gain floor; 0 means 0.01
+	MaxGain float64 `json:"max_gain,omitempty"` // Gain ceiling; 0 means 100
+}
+
+// check describes what is wrong with the settings, or returns "".
+func (h *Homeostasis) check() string {
+	switch {
+	case h.MinStd < 0 || h.MaxStd < 0 || h.MaxMean < 0:
+		return "bounds must not be negative"
+	case h.MaxStd > 0 && h.MinStd > h.MaxStd:
+		return "min_std exceeds max_std"
+	case h.MinStd == 0 && h.MaxStd == 0 && h.MaxMean == 0:
+		return "no bounds set"
+	case h.Rate < 0 || h.Rate > 1:
+		return "rate must be in [0, 1]"
+	case h.MinGain < 0 || (h.MaxGain > 0 && h.MinGain > h.MaxGain):
+		return "invalid gain limits"
+	}
+	return ""
+}
+
+func (h *Homeostasis) rate() float64 {
+	if h.Rate == 0 {
+		return 0.01
+	}
+	return h.Rate
+}
+
+// payloadStats holds running averages of a link's payload.
+type payloadStats struct {
+	mean, sq float64 // Averages of values and of squared values
+}
+
+// regulate updates the link's payload statistics and adjusts its gain when
+// they fall outside the Homeostasis bounds.
+func (l *runtimeLink) regulate() {
+	h := l.cfg.Homeostasis
+	if h == nil || len(l.payload) == 0 {
+		return
+	}
+	var sum, sq float64
+	for _, v := range l.payload {
+		sum += float64(v)
+		sq += float64(v) * float64(v)
+	}
+	n := float64(len(l.payload))
+	rate := h.rate()
+	if l.stats == nil {
+		l.stats = &payloadStats{mean: sum / n, sq: sq / n}
+	} else {
+		l.stats.mean += rate * (sum/n - l.stats.mean)
+		l.stats.sq += rate * (sq/n - l.stats.sq)
+	}
+
+	// Gain scales mean and deviation alike, so the correction is the ratio
+	// of the violated bound to the current value.
+	mean := math.Abs(l.stats.mean)
+	std := math.Sqrt(math.Max(0, l.stats.sq-l.stats.mean*l.stats.mean))
+	ratio := 1.0
+	switch {
+	case h.MaxMean > 0 && mean > h.MaxMean:
+		ratio = h.MaxMean / mean
+	case h.MaxStd > 0 && std > h.MaxStd:
+		ratio = h.MaxStd / std
+	case h.MinStd > 0 && std < h.MinStd && std > 0:
+		ratio = h.MinStd / std
+	}
+	if ratio == 1 {
+		return
+	}
+	minGain, maxGain := h.MinGain, h.MaxGain
+	if minGain == 0 {
+		minGain = 0.01
+	}
+	if maxGain == 0 {
+		maxGain = 100
+	}
+	gain := float64(l.gain) * math.Pow(ratio, rate)
+	l.gain = float32(math.Max(minGain, math.Min(maxGain, gain)))
+}
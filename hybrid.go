@@ -0,0 +1,155 @@
+package drift
+
+import (
+	"math"
+	"time"
+)
+
+// BlendGate decides how much weight the oracle receives when a HybridController
+// mixes it with a learned policy. A blend of 1 means pure oracle, 0 pure model.
+type BlendGate interface {
+	Blend(step int, probs []float32) float64
+}
+
+// LinearSchedule ramps the oracle weight linearly from Start to End over Steps steps
+// and holds End afterwards.
+type LinearSchedule struct {
+	Start float64
+	End   float64
+	Steps int
+}
+
+// Blend returns the scheduled oracle weight for step.
+func (s LinearSchedule) Blend(step int, probs []float32) float64 {
+	if s.Steps <= 0 || step >= s.Steps {
+		return s.End
+	}
+	t := float64(step) / float64(s.Steps)
+	return s.Start + (s.End-s.Start)*t
+}
+
+// ConfidenceGate learns when to trust the model from its own confidence.
+// The oracle weight is 1 - sigmoid(W*confidence + B), where confidence is the
+// model's top action probability; Update nudges W and B towards trusting the
+// model whenever its choice agreed with the oracle.
+type ConfidenceGate struct {
+	W    float64
+	B    float64
+	Rate float64
+}
+
+// NewConfidenceGate returns a gate that initially defers to the oracle.
+func NewConfidenceGate(rate float64) *ConfidenceGate {
+	return &ConfidenceGate{W: 4, B: -3, Rate: rate}
+}
+
+// Blend returns the oracle weight for the given model probabilities.
+func (g *ConfidenceGate) Blend(step int, probs []float32) float64 {
+	return 1 - g.trust(confidence(probs))
+}
+
+// Update performs one logistic-regression step on whether the model's choice
+// was correct at the confidence it reported.
+func (g *ConfidenceGate) Update(probs []float32, modelCorrect bool) {
+	c := confidence(probs)
+	target := 0.0
+	if modelCorrect {
+		target = 1
+	}
+	grad := target - g.trust(c)
+	g.W += g.Rate * grad * c
+	g.B += g.Rate * grad
+}
+
+func (g *ConfidenceGate) trust(c float64) float64 {
+	return 1 / (1 + math.Exp(-(g.W*c + g.B)))
+}
+
+// HybridController mixes an oracle policy with a learned model's action
+// distribution. It is intended for safe exploration, where a heuristic keeps
+// the agent out of trouble until the model has earned the controller's trust.
+type HybridController struct {
+	Oracle Policy
+	Gate   BlendGate
+	// Greedy picks the most likely blended action instead of sampling it.
+	Greedy bool
+	// Rand samples actions; typically Runtime.Rand(StreamExploration).
+	// Defaults to an unseeded exploration stream.
+	Rand Rand
+
+	step int
+}
+
+// NewHybridController creates a controller mixing oracle with a model using gate.
+func NewHybridController(oracle Policy, gate BlendGate) *HybridController {
+	return &HybridController{Oracle: oracle, Gate: gate}
+}
+
+// Act blends the model's raw outputs for obs with the oracle's choice and returns
+// the selected action along with the oracle weight that was applied.
+func (h *HybridController) Act(obs, modelOutput []float32) (int, float64) {
+	probs := Softmax(modelOutput)
+	beta := h.Gate.Blend(h.step, probs)
+	h.step++
+
+	mixed := make([]float64, len(probs))
+	for i, p := range probs {
+		mixed[i] = (1 - beta) * float64(p)
+	}
+	if a := h.Oracle.Act(obs); a >= 0 && a < len(mixed) {
+		mixed[a] += beta
+	}
+
+	if h.Greedy {
+		best := 0
+		for i, p := range mixed {
+			if p > mixed[best] {
+				best = i
+			}
+		}
+		return best, beta
+	}
+	if h.Rand == nil {
+		h.Rand = seededStream(time.Now().UnixNano(), StreamExploration)
+	}
+	r := h.Rand.Float64()
+	for i, p := range mixed {
+		r -= p
+		if r <= 0 {
+			return i, beta
+		}
+	}
+	return len(mixed) - 1, beta
+}
+
+// Softmax converts raw scores into a probability distribution.
+func Softmax(scores []float32) []float32 {
+	out := make([]float32, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+	maxV := scores[0]
+	for _, v := range scores {
+		if v > maxV {
+			maxV = v
+		}
+	}
+	var sum float64
+	for i, v := range scores {
+		e := math.Exp(float64(v - maxV))
+		out[i] = float32(e)
+		sum += e
+	}
+	for i := range out {
+		out[i] = float32(float64(out[i]) / sum)
+	}
+	return out
+}
+
+// confidence returns the largest probability in probs.
+func confidence(probs []float32) float64 {
+	if len(probs) == 0 {
+		return 0
+	}
+	return float64(probs[Argmax(probs)])
+}
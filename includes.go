@@ -0,0 +1,186 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Config files may pull in other files with an "includes" list of paths
+// relative to the including file:
+//
+//	{"name": "swarm", "includes": ["models/classifier.json", "links/nav_links.json"]}
+//
+// Each included file is a partial config in JSON, YAML or TOML (by
+// extension, see EncodingFor), possibly gzipped, and may include further files. Included files are merged in
+// order, and the including file last: models, inputs, resources and metadata
+// are merged by name, a name defined twice being an error; links, scenario and
+// training entries are concatenated; variables and other fields take the
+// value of the last file that sets them. A file reached twice is merged
+// once, and a cycle is an error. The loaded Config is the flattened result.
+
+// includeKeys are the config sections merged by name.
+var includeKeys = map[string]string{
+	"models":    "model",
+	"inputs":    "inputs of model",
+	"resources": "resources of model",
+	"metadata":  "metadata of model",
+}
+
+// includeLists are the config sections concatenated across files.
+var includeLists = map[string]bool{"links": true, "scenario": true, "training": true}
+
+// expandIncludes flattens the includes of the document data read from path.
+// Documents without includes are returned unchanged, along with the files
+// that were merged.
+func expandIncludes(data []byte, path string) ([]byte, []string, error) {
+	doc, err := unmarshalDoc(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := doc["includes"]; !ok {
+		return data, nil, nil
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	inc := &includer{seen: map[string]bool{abs: true}, owners: make(map[string]string)}
+	out := make(map[string]any)
+	if err := inc.merge(out, doc, abs, []string{abs}); err != nil {
+		return nil, nil, err
+	}
+	data, err = json.Marshal(out)
+	return data, inc.files, err
+}
+
+type includer struct {
+	seen   map[string]bool
+	owners map[string]string // "section/name" to the file defining it
+	files  []string          // Included files in merge order
+}
+
+// merge merges doc, read from file, and everything it includes into out.
+// stack holds the chain of files leading to doc.
+func (inc *includer) merge(out, doc map[string]any, file string, stack []string) error {
+	if raw, ok := doc["includes"]; ok {
+		list, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("%s: includes must be a list of paths", file)
+		}
+		for _, entry := range list {
+			rel, ok := entry.(string)
+			if !ok {
+				return fmt.Errorf("%s: includes must be a list of paths", file)
+			}
+			path := filepath.Join(filepath.Dir(file), filepath.FromSlash(rel))
+			for _, s := range stack {
+				if s == path {
+					return fmt.Errorf("include cycle: %s -> %s", strings.Join(stack, " -> "), path)
+				}
+			}
+			if inc.seen[path] {
+				continue
+			}
+			inc.seen[path] = true
+			sub, err := readInclude(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			inc.files = append(inc.files, path)
+			if err := inc.merge(out, sub, path, append(stack[:len(stack):len(stack)], path)); err != nil {
+				return err
+			}
+		}
+	}
+	for key, v := range doc {
+		switch {
+		case key == "includes":
+		case includeKeys[key] != "":
+			section, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s: %s must be an object", file, key)
+			}
+			dst, _ := out[key].(map[string]any)
+			if dst == nil {
+				dst = make(map[string]any)
+				out[key] = dst
+			}
+			for _, name := range sortedKeys(section) {
+				if owner, dup := inc.owners[key+"/"+name]; dup {
+					return fmt.Errorf("%s %q defined in both %s and %s", includeKeys[key], name, owner, file)
+				}
+				inc.owners[key+"/"+name] = file
+				dst[name] = section[name]
+			}
+		case includeLists[key]:
+			list, ok := v.([]any)
+			if !ok && v != nil {
+				return fmt.Errorf("%s: %s must be a list", file, key)
+			}
+			dst, _ := out[key].([]any)
+			out[key] = append(dst, list...)
+		case key == "variables":
+			vars, ok := v.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s: variables must be an object", file)
+			}
+			dst, _ := out[key].(map[string]any)
+			if dst == nil {
+				dst = make(map[string]any)
+				out[key] = dst
+			}
+			for k, val := range vars {
+				dst[k] = val
+			}
+		default:
+			out[key] = v
+		}
+	}
+	return nil
+}
+
+// readInclude reads an included file, decoding it by extension.
+func readInclude(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isGzip(data) {
+		if data, err = gunzip(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if enc := EncodingFor(path); enc == EncodingYAML || enc == EncodingTOML {
+		// Go through JSON, which writes integers exactly, to end up with
+		// the json.Number values a JSON include decodes to.
+		var doc map[string]any
+		if enc == EncodingYAML {
+			err = yaml.Unmarshal(data, &doc)
+		} else {
+			err = toml.Unmarshal(data, &doc)
+		}
+		if err == nil {
+			data, err = json.Marshal(doc)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	doc, err := unmarshalDoc(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Includes returns the files merged into the config through includes, in
+// merge order, or nil if it had none.
+func (c *Config) Includes() []string {
+	return c.includes
+}
@@ -0,0 +1,152 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// InputSegment names a contiguous region of a model's input vector,
+// e.g. the 4 position values or the 16-wide link region of the navigator.
+type InputSegment struct {
+	Name      string    `json:"name"`
+	Offset    int       `json:"offset"`
+	Size      int       `json:"size"`
+	Fill      FillMode  `json:"fill,omitempty"`       // What the segment holds when it has no data
+	FillValue float32   `json:"fill_value,omitempty"` // Value for FillConstant
+	Embedding []float32 `json:"embedding,omitempty"`  // Vector for FillEmbedding
+}
+
+// InputDriftDetector compares a model's live inputs against statistics
+// gathered during training and reports a drift score per input segment.
+// The score of a segment is the average, over its elements, of the distance
+// between the live moving average and the training mean measured in training
+// standard deviations. EventInputDrift is raised when a score crosses Threshold.
+type InputDriftDetector struct {
+	Model     string
+	Segments  []InputSegment
+	Threshold float64 // Score above which a segment is considered drifted
+	Rate      float64 // EMA rate of the live statistics
+
+	emit    EventHandler
+	n       int
+	mean    []float64
+	m2      []float64
+	live    []float64
+	started bool
+	scores  map[string]float64
+	firing  map[string]bool
+	refit   int
+}
+
+// NewInputDriftDetector creates a detector for the given model and segments.
+func NewInputDriftDetector(model string, segments []InputSegment, emit EventHandler) *InputDriftDetector {
+	return &InputDriftDetector{
+		Model:     model,
+		Segments:  segments,
+		Threshold: 3,
+		Rate:      0.01,
+		emit:      emit,
+		scores:    make(map[string]float64),
+		firing:    make(map[string]bool),
+	}
+}
+
+// ObserveTraining folds a training-time input into the reference statistics.
+func (d *InputDriftDetector) ObserveTraining(input []float32) {
+	if len(d.mean) != len(input) {
+		d.mean = make([]float64, len(input))
+		d.m2 = make([]float64, len(input))
+		d.n = 0
+	}
+	d.n++
+	for i, v := range input {
+		x := float64(v)
+		delta := x - d.mean[i]
+		d.mean[i] += delta / float64(d.n)
+		d.m2[i] += delta * (x - d.mean[i])
+	}
+}
+
+// Observe folds a live input into the moving averages, updates the per-segment
+// scores, and returns them. Nothing is reported until training statistics exist.
+func (d *InputDriftDetector) Observe(step int, input []float32) map[string]float64 {
+	if d.refit > 0 {
+		d.ObserveTraining(input)
+		d.refit--
+		return d.Scores()
+	}
+	if d.n < 2 || len(input) != len(d.mean) {
+		return d.Scores()
+	}
+	if !d.started {
+		d.live = make([]float64, len(input))
+		for i, v := range input {
+			d.live[i] = float64(v)
+		}
+		d.started = true
+	} else {
+		for i, v := range input {
+			d.live[i] += d.Rate * (float64(v) - d.live[i])
+		}
+	}
+
+	// Emit only once the scores are settled: a handler may call Refit, which
+	// resets the statistics the remaining segments are scored against.
+	var events []Event
+	for _, seg := range d.Segments {
+		score := d.segmentScore(seg)
+		d.scores[seg.Name] = score
+		drifted := score > d.Threshold
+		if drifted && !d.firing[seg.Name] {
+			events = append(events, Event{
+				Kind:    EventInputDrift,
+				Source:  d.Model + "/" + seg.Name,
+				Step:    step,
+				Value:   score,
+				Message: fmt.Sprintf("model %s input segment %s drifted (score %.2f)", d.Model, seg.Name, score),
+				Time:    time.Now(),
+			})
+		}
+		d.firing[seg.Name] = drifted
+	}
+	scores := d.Scores()
+	if d.emit != nil {
+		for _, e := range events {
+			d.emit(e)
+		}
+	}
+	return scores
+}
+
+// Refit discards the reference statistics and rebuilds them from the next
+// samples live inputs, accepting the current input distribution as normal.
+func (d *InputDriftDetector) Refit(samples int) {
+	d.mean, d.m2, d.n = nil, nil, 0
+	d.started = false
+	d.refit = samples
+	d.scores = make(map[string]float64)
+	d.firing = make(map[string]bool)
+}
+
+// Scores returns a copy of the latest drift score of every segment.
+func (d *InputDriftDetector) Scores() map[string]float64 {
+	out := make(map[string]float64, len(d.scores))
+	for k, v := range d.scores {
+		out[k] = v
+	}
+	return out
+}
+
+func (d *InputDriftDetector) segmentScore(seg InputSegment) float64 {
+	end := seg.Offset + seg.Size
+	if seg.Offset < 0 || end > len(d.mean) || seg.Size <= 0 {
+		return 0
+	}
+	var sum float64
+	for i := seg.Offset; i < end; i++ {
+		std := math.Sqrt(d.m2[i]/float64(d.n-1)) + 1e-6
+		sum += math.Abs(d.live[i]-d.mean[i]) / std
+	}
+	return sum / float64(seg.Size)
+}
@@ -0,0 +1,44 @@
+package drift
+
+import "testing"
+
+func TestInputDriftRefitThenDriftAgain(t *testing.T) {
+	var events []Event
+	recal := &Recalibrator{Mode: RecalibrateRefit, RefitSamples: 4}
+	d := NewInputDriftDetector("m", []InputSegment{
+		{Name: "a", Offset: 0, Size: 2},
+		{Name: "b", Offset: 2, Size: 2},
+	}, func(e Event) {
+		events = append(events, e)
+		recal.Handle(e)
+	})
+	d.Rate = 1
+	recal.Detector = d
+	for i := range 4 {
+		v := float32(i % 2)
+		d.ObserveTraining([]float32{v, v, v, v})
+	}
+
+	d.Observe(1, []float32{10, 10, 10, 10})
+	if len(events) != 2 {
+		t.Fatalf("drift raised %d events, want one per segment", len(events))
+	}
+	for i := range 4 {
+		v := 10 + float32(i%2)
+		d.Observe(2+i, []float32{v, v, v, v})
+	}
+	if scores := d.Observe(6, []float32{10.5, 10.5, 10.5, 10.5}); scores["a"] > d.Threshold || scores["b"] > d.Threshold {
+		t.Fatalf("scores after refit = %v, want no drift", scores)
+	}
+	if len(events) != 2 {
+		t.Fatalf("refit window raised %d more events", len(events)-2)
+	}
+
+	d.Observe(7, []float32{50, 50, 50, 50})
+	if len(events) != 4 {
+		t.Fatalf("drift after refit raised %d events, want one per segment", len(events)-2)
+	}
+	if got := recal.Runs(); got != 4 {
+		t.Errorf("recalibrator ran %d times, want 4", got)
+	}
+}
@@ -0,0 +1,228 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LayerSpec describes the JSON form of a loom layer type for Validate.
+type LayerSpec struct {
+	// Required lists fields that must be set to a non-zero value. An entry
+	// "a|b" is satisfied by either field.
+	Required []string
+	// Optional lists the other fields the type accepts. "type",
+	// "activation" and documentation keys such as "comment" are accepted by
+	// every type.
+	Optional []string
+	// Values restricts string fields to the listed values.
+	Values map[string][]string
+}
+
+// Activations loom understands. It builds "linear", "none" and "gelu",
+// like any unknown name, as scaled ReLU; they are accepted because configs
+// in the wild use them.
+var layerActivations = []string{"relu", "sigmoid", "tanh", "softplus", "leaky_relu", "linear", "none", "gelu"}
+
+// modelFields are the top-level fields of a loom network definition.
+var modelFields = []string{"id", "batch_size", "grid_rows", "grid_cols", "layers_per_cell", "layers", "seed"}
+
+// noteFields are documentation keys loom ignores, accepted on models and
+// layers alike.
+var noteFields = []string{"comment", "description", "name", "notes"}
+
+var (
+	denseSpec = LayerSpec{Required: []string{"width|input_size|input_height", "height|output_size|output_height"}}
+	rnnSpec   = LayerSpec{Required: []string{"input_size", "hidden_size"}, Optional: []string{"seq_length"}}
+	normSpec  = LayerSpec{Required: []string{"norm_size"}, Optional: []string{"epsilon"}}
+	mhaSpec   = LayerSpec{Required: []string{"d_model", "num_heads"}, Optional: []string{"seq_length"}}
+)
+
+// layerSpecs is the registry of layer types Validate accepts, seeded with
+// those loom builds.
+var layerSpecs = struct {
+	sync.RWMutex
+	m map[string]LayerSpec
+}{m: map[string]LayerSpec{
+	"dense": denseSpec,
+	"conv2d": {
+		Required: []string{"input_channels", "filters", "kernel_size", "input_height", "input_width", "output_height", "output_width"},
+		Optional: []string{"stride", "padding"},
+	},
+	"mha":                  mhaSpec,
+	"multi_head_attention": mhaSpec,
+	"rnn":                  rnnSpec,
+	"lstm":                 rnnSpec,
+	"softmax": {
+		Optional: []string{"softmax_variant", "softmax_rows", "softmax_cols", "temperature", "gumbel_noise", "mask",
+			"hierarchy_levels", "adaptive_clusters", "mixture_weights", "entmax_alpha"},
+		Values: map[string][]string{"softmax_variant": {"standard", "grid", "hierarchical", "temperature", "gumbel",
+			"masked", "sparse", "adaptive", "mixture", "entmax"}},
+	},
+	"layer_norm": normSpec,
+	"layernorm":  normSpec,
+	"rms_norm":   normSpec,
+	"rmsnorm":    normSpec,
+	"swiglu":     {Required: []string{"input_size|input_height", "output_size|output_height"}},
+	"residual":   {},
+	"parallel": {
+		Required: []string{"branches"},
+		Optional: []string{"combine_mode", "grid_positions", "grid_output_rows", "grid_output_cols", "grid_output_layers"},
+		Values:   map[string][]string{"combine_mode": {"concat", "add", "avg", "average", "grid_scatter"}},
+	},
+}}
+
+// RegisterLayerType registers the spec of a layer type, replacing any
+// earlier one, for loom builds with layers this package doesn't know.
+func RegisterLayerType(name string, spec LayerSpec) {
+	layerSpecs.Lock()
+	defer layerSpecs.Unlock()
+	layerSpecs.m[name] = spec
+}
+
+// LayerTypes returns the registered layer types, sorted.
+func LayerTypes() []string {
+	layerSpecs.RLock()
+	defer layerSpecs.RUnlock()
+	return sortedKeys(layerSpecs.m)
+}
+
+// validateModel checks a model definition against the layer registry,
+// reporting problems under field. Definitions without a "layers" list are
+// not loom networks and are left alone.
+func validateModel(errs *ValidationErrors, field string, raw json.RawMessage) {
+	var doc map[string]any
+	if json.Unmarshal(raw, &doc) != nil {
+		errs.add(field, "model definition is not a JSON object")
+		return
+	}
+	layers, ok := doc["layers"].([]any)
+	if !ok {
+		return
+	}
+	for _, k := range sortedKeys(doc) {
+		if !slices.Contains(modelFields, k) && !slices.Contains(noteFields, k) {
+			errs.add(field+"."+k, "unknown field%s", suggest(k, modelFields))
+		}
+	}
+	rows, cols, per := intField(doc, "grid_rows"), intField(doc, "grid_cols"), intField(doc, "layers_per_cell")
+	if rows*cols*per != len(layers) {
+		errs.add(field+".layers", "%d layers, but grid_rows × grid_cols × layers_per_cell is %d×%d×%d", len(layers), rows, cols, per)
+	}
+	layerSpecs.RLock()
+	defer layerSpecs.RUnlock()
+	for i, l := range layers {
+		validateLayer(errs, fmt.Sprintf("%s.layers[%d]", field, i), l)
+	}
+}
+
+// validateLayer checks one layer definition, recursing into parallel
+// branches. The caller holds layerSpecs.RLock.
+func validateLayer(errs *ValidationErrors, field string, v any) {
+	def, ok := v.(map[string]any)
+	if !ok {
+		errs.add(field, "layer is not a JSON object")
+		return
+	}
+	typ, _ := def["type"].(string)
+	spec, ok := layerSpecs.m[typ]
+	if !ok {
+		errs.add(field+".type", "unknown layer type %q%s", typ, suggest(typ, sortedKeys(layerSpecs.m)))
+		return
+	}
+	known := []string{"type", "activation"}
+	for _, r := range spec.Required {
+		known = append(known, strings.Split(r, "|")...)
+	}
+	known = append(known, spec.Optional...)
+	for _, k := range sortedKeys(def) {
+		if !slices.Contains(known, k) && !slices.Contains(noteFields, k) {
+			errs.add(field+"."+k, "unknown field for %s layer%s", typ, suggest(k, known))
+		}
+	}
+	for _, r := range spec.Required {
+		set := false
+		for _, k := range strings.Split(r, "|") {
+			set = set || !isZeroJSON(def[k])
+		}
+		if !set {
+			errs.add(field, "%s layer requires %s", typ, strings.ReplaceAll(r, "|", " or "))
+		}
+	}
+	if a, ok := def["activation"].(string); ok && a != "" && !slices.Contains(layerActivations, a) {
+		errs.add(field+".activation", "unknown activation %q%s", a, suggest(a, layerActivations))
+	}
+	for k, allowed := range spec.Values {
+		if s, ok := def[k].(string); ok && s != "" && !slices.Contains(allowed, s) {
+			errs.add(field+"."+k, "unsupported value %q, want one of %s", s, strings.Join(allowed, ", "))
+		}
+	}
+	if branches, ok := def["branches"].([]any); ok {
+		for i, b := range branches {
+			validateLayer(errs, fmt.Sprintf("%s.branches[%d]", field, i), b)
+		}
+	}
+}
+
+func intField(doc map[string]any, key string) int {
+	f, _ := doc[key].(float64)
+	return int(f)
+}
+
+// isZeroJSON reports whether a decoded JSON value is absent or zero.
+func isZeroJSON(v any) bool {
+	switch t := v.(type) {
+	case nil:
+		return true
+	case float64:
+		return t == 0
+	case string:
+		return t == ""
+	case bool:
+		return !t
+	case []any:
+		return len(t) == 0
+	}
+	return false
+}
+
+// suggest returns `, did you mean "x"?` for the candidate closest to s, or
+// "" when none is close enough to be a likely typo.
+func suggest(s string, candidates []string) string {
+	sorted := append([]string(nil), candidates...)
+	sort.Strings(sorted)
+	best, bestDist := "", len(s)/3+2
+	for _, c := range sorted {
+		if d := editDistance(s, c); d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,32 @@
+package drift
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExampleConfigValidates(t *testing.T) {
+	cfg, err := LoadFromFile("tests/test01/drift_config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRuntime(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUnknownLayerFieldRejected(t *testing.T) {
+	cfg, err := LoadFromFile("tests/test01/drift_config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, raw := range cfg.Models {
+		cfg.Models[name] = []byte(strings.ReplaceAll(string(raw), `"comment"`, `"comentary"`))
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "comentary") {
+		t.Fatalf("Validate() = %v, want an unknown field error", err)
+	}
+}
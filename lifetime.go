@@ -0,0 +1,66 @@
+package drift
+
+// ActiveWindow is a span of steps in which a link is live, for experiments
+// on communication graphs that change over time. A link with windows is
+// enabled when a step enters one of them and disabled when it leaves them
+// all; in between, SetLinkEnabled and scenario actions still apply. Steps
+// count from 0, as in Intervention.AtStep.
+//
+//	{"from": 1000, "until": 5000}             // steps 1000-4999
+//	{"from": 0, "every": 1000, "for": 100}    // the first 100 of every 1000
+type ActiveWindow struct {
+	From  uint64 `json:"from,omitempty"`  // First step of the window
+	Until uint64 `json:"until,omitempty"` // Step the window closes at; 0 leaves it open
+	Every uint64 `json:"every,omitempty"` // Period of a repeating window; 0 for none
+	For   uint64 `json:"for,omitempty"`   // Steps live at the start of each period
+}
+
+// Contains reports whether step falls inside the window.
+func (w ActiveWindow) Contains(step uint64) bool {
+	if step < w.From || (w.Until != 0 && step >= w.Until) {
+		return false
+	}
+	return w.Every == 0 || (step-w.From)%w.Every < w.For
+}
+
+// check describes what is wrong with the window, or returns "".
+func (w ActiveWindow) check() string {
+	switch {
+	case w.Until != 0 && w.Until <= w.From:
+		return "window closes before it opens"
+	case w.Every == 0 && w.For != 0:
+		return "for requires every"
+	case w.Every != 0 && (w.For == 0 || w.For > w.Every):
+		return "for must be between 1 and every"
+	}
+	return ""
+}
+
+// ActiveAt reports whether the link's windows make it live at step. Links
+// without windows are governed by Enabled alone and always report true.
+func (l NeuralLinkConfig) ActiveAt(step uint64) bool {
+	if len(l.Active) == 0 {
+		return true
+	}
+	for _, w := range l.Active {
+		if w.Contains(step) {
+			return true
+		}
+	}
+	return false
+}
+
+// rewire enables and disables links whose windows open or close at the
+// current step. The caller holds r.mu.
+func (r *Runtime) rewire() {
+	for _, l := range r.links {
+		if len(l.cfg.Active) == 0 {
+			continue
+		}
+		on := l.cfg.ActiveAt(r.steps)
+		if !l.scheduled || on != l.windowOn {
+			l.cfg.Enabled = on
+			l.scheduled, l.windowOn = true, on
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// LinkEval trains and evaluates an agent built from cfg and returns a task
+// score where higher is better. It is called once per candidate by the link
+// search routines, typically running a shortened benchmark.
+type LinkEval func(cfg *Config) (float64, error)
+
+// SizeSearchOptions configures SearchLinkSize.
+type SizeSearchOptions struct {
+	Tolerance float64 // Allowed fractional drop from the full-size score; defaults to 0.05
+	MinSize   int     // Smallest size considered; defaults to 1
+	Output    string  // If set, the recommended config is saved here
+}
+
+// LinkTrial is the score of one evaluated candidate.
+type LinkTrial struct {
+	Value        int     `json:"value"` // Link size or source layer, depending on the search
+	Score        float64 `json:"score"`
+	Decodability float64 `json:"decodability,omitempty"` // Held-out probe accuracy, when probed
+}
+
+// SizeSearchResult reports the outcome of SearchLinkSize.
+type SizeSearchResult struct {
+	Link        string      `json:"link"`
+	FullSize    int         `json:"full_size"`
+	FullScore   float64     `json:"full_score"`
+	Recommended int         `json:"recommended"`
+	Trials      []LinkTrial `json:"trials"`
+	Config      *Config     `json:"-"` // base with the link at the recommended size
+}
+
+// SearchLinkSize finds the smallest size for link whose score stays within
+// opts.Tolerance of the score at its configured size. Performance is assumed
+// to grow with size, so candidates are bisected and only about log2(size)
+// evaluations are needed.
+func SearchLinkSize(base *Config, link string, eval LinkEval, opts SizeSearchOptions) (*SizeSearchResult, error) {
+	idx, err := base.linkIndex(link)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 0.05
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1
+	}
+
+	res := &SizeSearchResult{Link: link, FullSize: base.Links[idx].LinkSize}
+	try := func(size int) (float64, error) {
+		cfg := withLink(base, idx, func(l *NeuralLinkConfig) { l.LinkSize = size })
+		score, err := eval(cfg)
+		if err != nil {
+			return 0, fmt.Errorf("size %d: %w", size, err)
+		}
+		res.Trials = append(res.Trials, LinkTrial{Value: size, Score: score})
+		return score, nil
+	}
+
+	if res.FullScore, err = try(res.FullSize); err != nil {
+		return nil, err
+	}
+	threshold := res.FullScore - opts.Tolerance*math.Abs(res.FullScore)
+	lo, hi := opts.MinSize, res.FullSize
+	for lo < hi {
+		mid := (lo + hi) / 2
+		score, err := try(mid)
+		if err != nil {
+			return res, err
+		}
+		if score >= threshold {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	res.Recommended = hi
+	res.Config = withLink(base, idx, func(l *NeuralLinkConfig) { l.LinkSize = hi })
+	if opts.Output != "" {
+		if err := res.Config.SaveToFile(opts.Output); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// LayerSearchOptions configures SearchSourceLayer.
+type LayerSearchOptions struct {
+	Layers []int // Candidate source layers; defaults to every layer of the source model
+	// Probe, if set, samples an input for the source model and a class label
+	// the link is expected to convey. A linear probe is fitted on each
+	// candidate layer and its held-out accuracy reported as Decodability.
+	Probe        func() (input []float32, label int)
+	ProbeSamples int // Defaults to 400, a quarter of which are held out
+	Seed         int64
+	Output       string // If set, the config using the best layer is saved here
+}
+
+// LayerSearchResult reports the outcome of SearchSourceLayer.
+type LayerSearchResult struct {
+	Link   string      `json:"link"`
+	Best   int         `json:"best"`
+	Trials []LinkTrial `json:"trials"` // Ranked best first
+	Config *Config     `json:"-"`      // base with the link reading from Best
+}
+
+// SearchSourceLayer evaluates link reading from each candidate source layer
+// and ranks the layers by downstream score, breaking ties by probe
+// decodability.
+func SearchSourceLayer(base *Config, link string, eval LinkEval, opts LayerSearchOptions) (*LayerSearchResult, error) {
+	idx, err := base.linkIndex(link)
+	if err != nil {
+		return nil, err
+	}
+	lc := base.resolveLink(base.Links[idx])
+	layers := opts.Layers
+	if len(layers) == 0 {
+		spec, err := parseModel(base.Models[lc.SourceModel])
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", lc.SourceModel, err)
+		}
+		for i := 1; i <= len(spec.Layers); i++ {
+			layers = append(layers, i)
+		}
+	}
+
+	var decodability map[int]float64
+	if opts.Probe != nil {
+		var err error
+		if decodability, err = probeLayers(base, lc.SourceModel, layers, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &LayerSearchResult{Link: link}
+	for _, layer := range layers {
+		cfg := withLink(base, idx, func(l *NeuralLinkConfig) { l.SourceLayer = layer })
+		score, err := eval(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("layer %d: %w", layer, err)
+		}
+		res.Trials = append(res.Trials, LinkTrial{Value: layer, Score: score, Decodability: decodability[layer]})
+	}
+	sort.SliceStable(res.Trials, func(i, j int) bool {
+		a, b := res.Trials[i], res.Trials[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Decodability > b.Decodability
+	})
+
+	res.Best = res.Trials[0].Value
+	res.Config = withLink(base, idx, func(l *NeuralLinkConfig) { l.SourceLayer = res.Best })
+	if opts.Output != "" {
+		if err := res.Config.SaveToFile(opts.Output); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// probeLayers fits a linear probe from each of the model's candidate layers
+// to the labels drawn from opts.Probe and returns its held-out accuracy per
+// layer. Activations come from base's initial weights, settled by holding
+// each input for as many steps as the deepest layer needs.
+func probeLayers(base *Config, model string, layers []int, opts LayerSearchOptions) (map[int]float64, error) {
+	n := opts.ProbeSamples
+	if n <= 0 {
+		n = 400
+	}
+	r, err := NewRuntime(base)
+	if err != nil {
+		return nil, err
+	}
+	net, inputSize := r.Network(model), len(r.Input(model))
+
+	settle := 1
+	for _, l := range layers {
+		settle = max(settle, l)
+	}
+	acts := make(map[int][][]float32, len(layers))
+	labels := make([]int, n)
+	classes := 0
+	state := net.InitStepState(inputSize)
+	for k := 0; k < n; k++ {
+		in, label := opts.Probe()
+		x := make([]float32, inputSize)
+		copy(x, in)
+		state.SetInput(x)
+		for s := 0; s < settle; s++ {
+			net.StepForward(state)
+		}
+		for _, l := range layers {
+			acts[l] = append(acts[l], append([]float32(nil), state.GetLayerOutput(l)...))
+		}
+		labels[k] = label
+		classes = max(classes, label+1)
+	}
+
+	train := n - n/4
+	out := make(map[int]float64, len(layers))
+	for _, l := range layers {
+		xs := acts[l]
+		if len(xs[0]) == 0 {
+			continue
+		}
+		p := NewLinkProjection(len(xs[0]), classes)
+		y, pred := make([]float32, classes), make([]float32, classes)
+		rng := seededStream(opts.Seed, StreamTraining)
+		for epoch := 0; epoch < 20; epoch++ {
+			for _, k := range perm(rng, train) {
+				for c := range y {
+					y[c] = 0
+				}
+				y[labels[k]] = 1
+				p.Apply(xs[k], pred)
+				for c := range pred {
+					g := 0.01 * (pred[c] - y[c])
+					p.B[c] -= g
+					row := p.W[c*p.In : (c+1)*p.In]
+					for i := range row {
+						row[i] -= g * xs[k][i]
+					}
+				}
+			}
+		}
+		correct := 0
+		for k := train; k < n; k++ {
+			p.Apply(xs[k], pred)
+			if Argmax(pred) == labels[k] {
+				correct++
+			}
+		}
+		out[l] = float64(correct) / float64(n-train)
+	}
+	return out, nil
+}
+
+// withLink returns a clone of base in which the link at idx has been
+// modified by fn.
+func withLink(base *Config, idx int, fn func(*NeuralLinkConfig)) *Config {
+	cfg := base.Clone()
+	fn(&cfg.Links[idx])
+	return cfg
+}
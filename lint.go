@@ -0,0 +1,120 @@
+package drift
+
+import "fmt"
+
+// Severity ranks a lint diagnostic.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "info"
+}
+
+// MarshalText encodes the severity by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*s = SeverityInfo
+	case "warning":
+		*s = SeverityWarning
+	default:
+		return fmt.Errorf("unknown severity %q", text)
+	}
+	return nil
+}
+
+// Lint codes.
+const (
+	LintDisabledLinkMissingModel = "disabled-link-missing-model" // A disabled link names a model that doesn't exist
+	LintLinkNoDescription        = "link-no-description"         // A link has no description
+	LintModelUnlinked            = "model-unlinked"              // No link starts or ends at a model
+	LintTargetOffsetOutside      = "target-offset-outside"       // A link's payload falls partly or wholly outside the target input
+	LintLinksOverlap             = "links-overlap"               // Two enabled links write the same target inputs
+)
+
+// Diagnostic is a problem found by Lint.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Field    string   `json:"field"` // Location, in Validate's notation
+	Message  string   `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Field, d.Severity, d.Message, d.Code)
+}
+
+// Lint reports soft problems that usually point to a mistake, for CI to
+// gate on alongside Validate: links first, in order, then models.
+func (c *Config) Lint() []Diagnostic {
+	var ds []Diagnostic
+	add := func(sev Severity, code, field, format string, args ...any) {
+		ds = append(ds, Diagnostic{Severity: sev, Code: code, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	linked := make(map[string]bool, len(c.Models))
+	inputs := make(map[string]int)
+	for i, l := range c.Links {
+		field := fmt.Sprintf("links[%d]", i)
+		if l.Description == "" {
+			add(SeverityInfo, LintLinkNoDescription, field+".description", "link %q has no description", l.Name)
+		}
+		r := c.resolveLink(l)
+		_, srcOK := c.Models[r.SourceModel]
+		_, dstOK := c.Models[r.TargetModel]
+		if !l.Enabled && (!srcOK || !dstOK) {
+			missing := r.SourceModel
+			if srcOK {
+				missing = r.TargetModel
+			}
+			add(SeverityWarning, LintDisabledLinkMissingModel, field, "disabled link %q refers to missing model %q", l.Name, missing)
+		}
+		linked[r.SourceModel], linked[r.TargetModel] = true, true
+		if !dstOK {
+			continue
+		}
+		size, ok := inputs[r.TargetModel]
+		if !ok {
+			size, _ = modelInputSize(c.Models[r.TargetModel])
+			inputs[r.TargetModel] = size
+		}
+		switch {
+		case size == 0:
+		case l.TargetOffset >= size:
+			add(SeverityWarning, LintTargetOffsetOutside, field+".target_offset", "offset %d is past the %d inputs of %q; nothing is delivered", l.TargetOffset, size, r.TargetModel)
+		case l.TargetOffset+l.LinkSize > size:
+			add(SeverityWarning, LintTargetOffsetOutside, field+".target_offset", "offset %d + size %d exceeds the %d inputs of %q; the payload is clipped", l.TargetOffset, l.LinkSize, size, r.TargetModel)
+		}
+	}
+
+	for i, a := range c.Links {
+		for j := i + 1; j < len(c.Links); j++ {
+			b := c.Links[j]
+			if !a.Enabled || !b.Enabled || c.resolveLink(a).TargetModel != c.resolveLink(b).TargetModel {
+				continue
+			}
+			if a.TargetOffset < b.TargetOffset+b.LinkSize && b.TargetOffset < a.TargetOffset+a.LinkSize {
+				add(SeverityWarning, LintLinksOverlap, fmt.Sprintf("links[%d]", j), "link %q writes inputs of %q also written by %q", b.Name, c.resolveLink(b).TargetModel, a.Name)
+			}
+		}
+	}
+
+	for _, name := range sortedKeys(c.Models) {
+		if !linked[name] && len(c.Models) > 1 {
+			add(SeverityWarning, LintModelUnlinked, fmt.Sprintf("models[%s]", name), "model %q has no links", name)
+		}
+	}
+	return ds
+}
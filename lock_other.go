@@ -0,0 +1,9 @@
+//go:build !unix
+
+package drift
+
+// lockFile is a no-op on platforms without flock; concurrent access to a
+// config file is not coordinated there.
+func lockFile(path string, exclusive bool) (func() error, error) {
+	return func() error { return nil }, nil
+}
@@ -0,0 +1,54 @@
+//go:build unix
+
+package drift
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an advisory flock on path+".lock" and returns a function
+// that releases it. A separate lock file is used because atomic saves replace
+// the config file's inode. Exclusive locks create the lock file; shared ones
+// open it read-only and go without a lock when it doesn't exist, since no
+// save has taken one, so loading never writes next to the config.
+func lockFile(path string, exclusive bool) (func() error, error) {
+	f, err := openLockFile(path+".lock", exclusive)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return func() error { return nil }, nil
+	}
+	how := syscall.LOCK_SH
+	if exclusive {
+		how = syscall.LOCK_EX
+	}
+	for {
+		err = syscall.Flock(int(f.Fd()), how)
+		if err != syscall.EINTR {
+			break
+		}
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		defer f.Close()
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}, nil
+}
+
+// openLockFile opens the lock file at path, or returns nil for a shared lock
+// whose file can't be opened.
+func openLockFile(path string, exclusive bool) (*os.File, error) {
+	if exclusive {
+		return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil
+	}
+	return f, nil
+}
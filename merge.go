@@ -0,0 +1,135 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictStrategy decides what Merge does when both configs define a model
+// or link with the same name.
+type ConflictStrategy int
+
+const (
+	ConflictError     ConflictStrategy = iota // Fail without changing the config
+	ConflictOverwrite                         // The other config's definition wins
+	ConflictRename                            // The other config's entry is renamed with Prefix
+)
+
+// MergeOptions configures Config.Merge.
+type MergeOptions struct {
+	OnConflict ConflictStrategy
+	// Prefix is prepended to conflicting names under ConflictRename. It
+	// defaults to the other config's name followed by a dot.
+	Prefix string
+}
+
+// Merge adds the models, links, input segments, resource hints and
+// scenario of other to c, resolving name clashes by opts.OnConflict. Renamed
+// models and links are also renamed in every reference from other, so the
+// merged config stays consistent. On error c is left unchanged.
+func (c *Config) Merge(other *Config, opts MergeOptions) error {
+	prefix := opts.Prefix
+	if prefix == "" {
+		prefix = other.Name + "."
+	}
+
+	modelNames := make(map[string]string, len(other.Models))
+	var conflicts []string
+	for name := range other.Models {
+		modelNames[name] = name
+		if _, clash := c.Models[name]; clash {
+			conflicts = append(conflicts, "model "+name)
+			if opts.OnConflict == ConflictRename {
+				modelNames[name] = prefix + name
+			}
+		}
+	}
+	linkNames := make(map[string]string, len(other.Links))
+	existing := make(map[string]bool, len(c.Links))
+	for _, l := range c.Links {
+		existing[l.Name] = true
+	}
+	for _, l := range other.Links {
+		linkNames[l.Name] = l.Name
+		if existing[l.Name] {
+			conflicts = append(conflicts, "link "+l.Name)
+			if opts.OnConflict == ConflictRename {
+				linkNames[l.Name] = prefix + l.Name
+			}
+		}
+	}
+	if len(conflicts) > 0 && opts.OnConflict == ConflictError {
+		sort.Strings(conflicts)
+		return fmt.Errorf("merge %q: conflicting %s", other.Name, strings.Join(conflicts, ", "))
+	}
+	for name, newName := range modelNames {
+		_, inC := c.Models[newName]
+		_, inOther := other.Models[newName]
+		if newName != name && (inC || inOther) {
+			return fmt.Errorf("merge %q: renamed model %q also exists", other.Name, newName)
+		}
+	}
+	otherLinks := make(map[string]bool, len(other.Links))
+	for _, l := range other.Links {
+		otherLinks[l.Name] = true
+	}
+	for name, newName := range linkNames {
+		if newName != name && (existing[newName] || otherLinks[newName]) {
+			return fmt.Errorf("merge %q: renamed link %q also exists", other.Name, newName)
+		}
+	}
+
+	rename := func(m map[string]string, name string) string {
+		if n, ok := m[name]; ok {
+			return n
+		}
+		return name
+	}
+
+	if c.Models == nil {
+		c.Models = make(map[string]json.RawMessage)
+	}
+	for name, raw := range other.Models {
+		c.Models[modelNames[name]] = raw
+	}
+	for name, segs := range other.Inputs {
+		if c.Inputs == nil {
+			c.Inputs = make(map[string][]InputSegment)
+		}
+		c.Inputs[rename(modelNames, name)] = append([]InputSegment(nil), segs...)
+	}
+	for name, h := range other.Resources {
+		if c.Resources == nil {
+			c.Resources = make(map[string]ResourceHints)
+		}
+		c.Resources[rename(modelNames, name)] = h
+	}
+	for name, md := range other.Metadata {
+		if c.Metadata == nil {
+			c.Metadata = make(map[string]ModelMetadata)
+		}
+		md.Tags = append([]string(nil), md.Tags...)
+		c.Metadata[rename(modelNames, name)] = md
+	}
+	for _, l := range other.Links {
+		l.Name = linkNames[l.Name]
+		l.SourceModel = rename(modelNames, l.SourceModel)
+		l.TargetModel = rename(modelNames, l.TargetModel)
+		if opts.OnConflict == ConflictOverwrite && existing[l.Name] {
+			for i := range c.Links {
+				if c.Links[i].Name == l.Name {
+					c.Links[i] = l
+				}
+			}
+			continue
+		}
+		c.Links = append(c.Links, l)
+	}
+	for _, iv := range other.Scenario {
+		iv.Target = rename(linkNames, iv.Target)
+		c.Scenario = append(c.Scenario, iv)
+	}
+	return nil
+}
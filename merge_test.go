@@ -0,0 +1,79 @@
+package drift
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// mergeConfigs returns a config and another that clash on model a and link l.
+func mergeConfigs() (c, other *Config) {
+	c = NewConfig("base")
+	c.Models["a"] = json.RawMessage(`{"v":1}`)
+	c.AddLink(NeuralLinkConfig{Name: "l", SourceModel: "a", TargetModel: "a", LinkSize: 1})
+
+	other = NewConfig("other")
+	other.Models["a"] = json.RawMessage(`{"v":2}`)
+	other.Models["b"] = json.RawMessage(`{"v":3}`)
+	other.AddLink(NeuralLinkConfig{Name: "l", SourceModel: "a", TargetModel: "b", LinkSize: 2})
+	return c, other
+}
+
+func TestMergeConflictError(t *testing.T) {
+	c, other := mergeConfigs()
+	if err := c.Merge(other, MergeOptions{OnConflict: ConflictError}); err == nil {
+		t.Fatal("Merge succeeded despite conflicts")
+	}
+	if len(c.Models) != 1 || len(c.Links) != 1 || string(c.Models["a"]) != `{"v":1}` {
+		t.Errorf("failed merge changed the config: models %v, links %v", c.Models, c.Links)
+	}
+}
+
+func TestMergeConflictOverwrite(t *testing.T) {
+	c, other := mergeConfigs()
+	if err := c.Merge(other, MergeOptions{OnConflict: ConflictOverwrite}); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(c.Models["a"]); got != `{"v":2}` {
+		t.Errorf("model a = %s, want the other config's", got)
+	}
+	if _, ok := c.Models["b"]; !ok {
+		t.Error("model b was not merged")
+	}
+	if len(c.Links) != 1 {
+		t.Fatalf("links = %v, want l overwritten", c.Links)
+	}
+	if l, _ := c.GetLink("l"); l.TargetModel != "b" || l.LinkSize != 2 {
+		t.Errorf("link l = %+v, want the other config's", l)
+	}
+}
+
+func TestMergeConflictRename(t *testing.T) {
+	c, other := mergeConfigs()
+	if err := c.Merge(other, MergeOptions{OnConflict: ConflictRename}); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(c.Models["a"]); got != `{"v":1}` {
+		t.Errorf("model a = %s, want it kept", got)
+	}
+	if got := string(c.Models["other.a"]); got != `{"v":2}` {
+		t.Errorf("model other.a = %s, want the other config's a", got)
+	}
+	l, ok := c.GetLink("other.l")
+	if !ok {
+		t.Fatalf("links = %v, want l renamed to other.l", c.Links)
+	}
+	if l.SourceModel != "other.a" || l.TargetModel != "b" {
+		t.Errorf("renamed link runs %s → %s, want other.a → b", l.SourceModel, l.TargetModel)
+	}
+}
+
+func TestMergeConflictRenameCollision(t *testing.T) {
+	c, other := mergeConfigs()
+	c.AddLink(NeuralLinkConfig{Name: "other.l", SourceModel: "a", TargetModel: "a", LinkSize: 1})
+	if err := c.Merge(other, MergeOptions{OnConflict: ConflictRename}); err == nil {
+		t.Fatal("Merge renamed link l onto an existing link")
+	}
+	if len(c.Models) != 1 || len(c.Links) != 2 {
+		t.Errorf("failed merge changed the config: models %v, links %v", c.Models, c.Links)
+	}
+}
@@ -0,0 +1,76 @@
+package drift
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// semver matches semantic versions such as 1.4.0 or v2.0.0-rc.1+build.5.
+var semver = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// ModelMetadata describes a model for the tooling around experiments; the
+// runtime ignores it.
+type ModelMetadata struct {
+	Tags        []string  `json:"tags,omitempty"`
+	Version     string    `json:"version,omitempty"` // Semantic version of the model definition
+	Author      string    `json:"author,omitempty"`
+	Description string    `json:"description,omitempty"`
+	Created     time.Time `json:"created,omitzero"`
+	Updated     time.Time `json:"updated,omitzero"`
+}
+
+// HasTag reports whether the metadata carries tag.
+func (md ModelMetadata) HasTag(tag string) bool {
+	for _, t := range md.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// GetModelMetadata returns a model's metadata and whether it has any.
+func (c *Config) GetModelMetadata(name string) (ModelMetadata, bool) {
+	md, ok := c.Metadata[name]
+	md.Tags = append([]string(nil), md.Tags...)
+	return md, ok
+}
+
+// SetModelMetadata replaces a model's metadata, stamping Updated with the
+// current time. Created keeps its previous value when md leaves it zero, and
+// is stamped too for models without one.
+func (c *Config) SetModelMetadata(name string, md ModelMetadata) error {
+	if _, ok := c.Models[name]; !ok {
+		return fmt.Errorf("model %q not found", name)
+	}
+	if md.Version != "" && !semver.MatchString(md.Version) {
+		return fmt.Errorf("model %q: %q is not a semantic version", name, md.Version)
+	}
+	now := time.Now().UTC()
+	if md.Created.IsZero() {
+		md.Created = c.Metadata[name].Created
+	}
+	if md.Created.IsZero() {
+		md.Created = now
+	}
+	md.Updated = now
+	md.Tags = append([]string(nil), md.Tags...)
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]ModelMetadata)
+	}
+	c.Metadata[name] = md
+	return nil
+}
+
+// ModelsWithTag returns the names of the models whose metadata carries tag,
+// sorted.
+func (c *Config) ModelsWithTag(tag string) []string {
+	var names []string
+	for _, name := range sortedKeys(c.Metadata) {
+		if c.Metadata[name].HasTag(tag) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
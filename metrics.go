@@ -0,0 +1,25 @@
+package drift
+
+import "time"
+
+// Metric is a single named measurement recorded during a run.
+type Metric struct {
+	Name   string            `json:"name"`
+	Value  float64           `json:"value"`
+	Step   uint64            `json:"step"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Time   time.Time         `json:"time"`
+}
+
+// MetricSink receives metrics recorded by a Runtime.
+// Sinks may buffer; Flush must persist everything recorded so far.
+type MetricSink interface {
+	Record(m Metric) error
+	Flush() error
+}
+
+// Flusher is implemented by recorders and other components holding buffered
+// data that must be persisted when a Runtime shuts down.
+type Flusher interface {
+	Flush() error
+}
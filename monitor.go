@@ -0,0 +1,115 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultRewardHackWindow is the window a RewardHackMonitor fits its trends
+// over when none is given.
+const DefaultRewardHackWindow = 50
+
+// RewardHackMonitor watches a proxy reward alongside a task metric and raises
+// EventRewardHacking when the two diverge, i.e. the reward keeps climbing while
+// the metric it is meant to stand in for (targets reached, accuracy) falls.
+type RewardHackMonitor struct {
+	Name string
+	// Window is the number of recent observations the trends are fitted
+	// over; 0 means DefaultRewardHackWindow.
+	Window int
+	// MinTrend is the normalized slope both series must exceed, in opposite
+	// directions, before the monitor fires.
+	MinTrend float64
+
+	emit    EventHandler
+	rewards []float64
+	metrics []float64
+	firing  bool
+}
+
+// NewRewardHackMonitor creates a monitor that reports divergence to emit. A
+// window of 0 or less means DefaultRewardHackWindow.
+func NewRewardHackMonitor(name string, window int, emit EventHandler) *RewardHackMonitor {
+	if window <= 0 {
+		window = DefaultRewardHackWindow
+	}
+	return &RewardHackMonitor{
+		Name:     name,
+		Window:   window,
+		MinTrend: 0.05,
+		emit:     emit,
+	}
+}
+
+// Observe records the reward and task metric for one step or window and
+// returns true if the series are currently diverging. An event is emitted
+// only when divergence begins, not on every subsequent observation.
+func (m *RewardHackMonitor) Observe(step int, reward, metric float64) bool {
+	window := m.Window
+	if window <= 0 {
+		window = DefaultRewardHackWindow
+	}
+	m.rewards = appendWindow(m.rewards, reward, window)
+	m.metrics = appendWindow(m.metrics, metric, window)
+	if len(m.rewards) < window {
+		return false
+	}
+
+	rewardTrend := normalizedSlope(m.rewards)
+	metricTrend := normalizedSlope(m.metrics)
+	diverging := rewardTrend > m.MinTrend && metricTrend < -m.MinTrend
+
+	if diverging && !m.firing && m.emit != nil {
+		m.emit(Event{
+			Kind:   EventRewardHacking,
+			Source: m.Name,
+			Step:   step,
+			Value:  rewardTrend - metricTrend,
+			Message: fmt.Sprintf("reward trending up (%.3f) while task metric trending down (%.3f)",
+				rewardTrend, metricTrend),
+			Time: time.Now(),
+		})
+	}
+	m.firing = diverging
+	return diverging
+}
+
+// appendWindow appends v to s, keeping at most n trailing values.
+func appendWindow(s []float64, v float64, n int) []float64 {
+	s = append(s, v)
+	if n > 0 && len(s) > n {
+		s = s[len(s)-n:]
+	}
+	return s
+}
+
+// normalizedSlope fits a least-squares line to s against its index and returns
+// the slope per observation divided by the series' standard deviation, so
+// trends of series with different units can be compared.
+func normalizedSlope(s []float64) float64 {
+	n := float64(len(s))
+	if n < 2 {
+		return 0
+	}
+	var meanX, meanY float64
+	for i, v := range s {
+		meanX += float64(i)
+		meanY += v
+	}
+	meanX /= n
+	meanY /= n
+
+	var cov, varX, varY float64
+	for i, v := range s {
+		dx := float64(i) - meanX
+		dy := v - meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return (cov / varX) / math.Sqrt(varY/n)
+}
@@ -0,0 +1,221 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MonolithModel is the name of the single model in a monolith config.
+const MonolithModel = "monolith"
+
+// MonolithOptions configures BuildMonolith.
+type MonolithOptions struct {
+	Activation string // Hidden-layer activation; defaults to "leaky_relu"
+}
+
+// Monolith is a single model equivalent to a linked config: its input is the
+// concatenation of every model's external input, its layers are the models'
+// layers merged side by side, and its output is the concatenation of their
+// outputs. It is the baseline for testing whether linking beats merging.
+type Monolith struct {
+	Config *Config
+
+	models  []string         // Swarm models in concatenation order
+	inputs  map[string][]int // Per model, the external input indices gathered into the monolith input
+	outputs map[string][2]int
+	inSize  int
+	outSize int
+}
+
+// BuildMonolith synthesizes the monolith of cfg. Layer i of the monolith is
+// as wide as layer i of all models together; it is recurrent when any of
+// them is and dense otherwise. Link-fed input ranges are left out, since
+// the monolith has no links.
+func BuildMonolith(cfg *Config, opts MonolithOptions) (*Monolith, error) {
+	if opts.Activation == "" {
+		opts.Activation = "leaky_relu"
+	}
+	// One step through the swarm with links disabled reveals every layer's
+	// width, whatever its type.
+	probe := *cfg
+	probe.Links = nil
+	probe.Scenario = nil
+	r, err := NewRuntime(&probe)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Step(nil); err != nil {
+		return nil, err
+	}
+
+	m := &Monolith{
+		models:  r.Models(),
+		inputs:  make(map[string][]int),
+		outputs: make(map[string][2]int),
+	}
+	var widths []int
+	var recurrent []bool
+	for _, name := range m.models {
+		linkFed := make([]bool, len(r.Input(name)))
+		for _, l := range cfg.Links {
+			if l = cfg.resolveLink(l); l.TargetModel != name {
+				continue
+			}
+			for i := l.TargetOffset; i < l.TargetOffset+l.LinkSize && i < len(linkFed); i++ {
+				linkFed[i] = true
+			}
+		}
+		for i, fed := range linkFed {
+			if !fed {
+				m.inputs[name] = append(m.inputs[name], i)
+			}
+		}
+		m.inSize += len(m.inputs[name])
+
+		spec, err := parseModel(cfg.Models[name])
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", name, err)
+		}
+		for i, def := range spec.Layers {
+			if i == len(widths) {
+				widths = append(widths, 0)
+				recurrent = append(recurrent, false)
+			}
+			if i == len(spec.Layers)-1 {
+				continue // Outputs are placed in the final layer below
+			}
+			widths[i] += len(r.LayerOutput(name, i+1))
+			recurrent[i] = recurrent[i] || def.Type == "lstm" || def.Type == "rnn"
+		}
+		out := len(r.Output(name))
+		m.outputs[name] = [2]int{m.outSize, out}
+		m.outSize += out
+	}
+	widths[len(widths)-1] = m.outSize
+
+	layers := make([]map[string]any, len(widths))
+	in := m.inSize
+	for i, w := range widths {
+		switch {
+		case i == len(widths)-1:
+			layers[i] = map[string]any{"type": "dense", "input_size": in, "output_size": w, "activation": "none"}
+		case recurrent[i]:
+			layers[i] = map[string]any{"type": "lstm", "input_size": in, "hidden_size": w, "seq_length": 1}
+		default:
+			layers[i] = map[string]any{"type": "dense", "input_size": in, "output_size": w, "activation": opts.Activation}
+		}
+		in = w
+	}
+	def, err := json.Marshal(map[string]any{
+		"batch_size": 1, "grid_rows": 1, "grid_cols": 1,
+		"layers_per_cell": len(layers),
+		"layers":          layers,
+	})
+	if err != nil {
+		return nil, err
+	}
+	m.Config = NewConfig(cfg.Name + "-monolith")
+	m.Config.Seed = cfg.Seed
+	m.Config.Models[MonolithModel] = def
+	return m, nil
+}
+
+// Join concatenates the external inputs of every model into a monolith
+// input. Missing models contribute zeros.
+func (m *Monolith) Join(inputs map[string][]float32) []float32 {
+	out := make([]float32, 0, m.inSize)
+	for _, name := range m.models {
+		in := inputs[name]
+		for _, i := range m.inputs[name] {
+			var v float32
+			if i < len(in) {
+				v = in[i]
+			}
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Split cuts a monolith output into the outputs of the models it replaces.
+func (m *Monolith) Split(out []float32) map[string][]float32 {
+	res := make(map[string][]float32, len(m.models))
+	for _, name := range m.models {
+		o := m.outputs[name]
+		if o[0]+o[1] <= len(out) {
+			res[name] = out[o[0] : o[0]+o[1]]
+		}
+	}
+	return res
+}
+
+// MonolithRuntime runs a monolith behind the per-model interface of the
+// swarm it replaces, so the same benchmark code can drive either.
+type MonolithRuntime struct {
+	*Runtime
+	Monolith *Monolith
+}
+
+// NewRuntime creates a runtime for the monolith.
+func (m *Monolith) NewRuntime() (*MonolithRuntime, error) {
+	r, err := NewRuntime(m.Config)
+	if err != nil {
+		return nil, err
+	}
+	return &MonolithRuntime{Runtime: r, Monolith: m}, nil
+}
+
+// Step joins the per-model inputs, steps the monolith and returns its output
+// split per model.
+func (r *MonolithRuntime) Step(inputs map[string][]float32) (map[string][]float32, error) {
+	out, err := r.Runtime.Step(map[string][]float32{MonolithModel: r.Monolith.Join(inputs)})
+	if err != nil {
+		return nil, err
+	}
+	return r.Monolith.Split(out[MonolithModel]), nil
+}
+
+// MonolithReport compares a linked config with its monolith.
+type MonolithReport struct {
+	SwarmScore    float64 `json:"swarm_score"`
+	MonolithScore float64 `json:"monolith_score"`
+	SwarmMACs     int64   `json:"swarm_macs"` // Per step, summed over models
+	MonolithMACs  int64   `json:"monolith_macs"`
+}
+
+// CompareMonolith benchmarks cfg's linked models against their monolith.
+// eval trains and scores whichever system it is given, higher being
+// better; both present the swarm's per-model inputs and outputs.
+func CompareMonolith(cfg *Config, opts MonolithOptions, eval func(s Stepper) (float64, error)) (*MonolithReport, error) {
+	m, err := BuildMonolith(cfg, opts)
+	if err != nil {
+		return nil, err
+	}
+	report := &MonolithReport{}
+	for _, raw := range cfg.Models {
+		macs, err := ModelMACs(raw)
+		if err != nil {
+			return nil, err
+		}
+		report.SwarmMACs += macs
+	}
+	if report.MonolithMACs, err = ModelMACs(m.Config.Models[MonolithModel]); err != nil {
+		return nil, err
+	}
+
+	swarm, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if report.SwarmScore, err = eval(swarm); err != nil {
+		return report, fmt.Errorf("swarm: %w", err)
+	}
+	mono, err := m.NewRuntime()
+	if err != nil {
+		return report, err
+	}
+	if report.MonolithScore, err = eval(mono); err != nil {
+		return report, fmt.Errorf("monolith: %w", err)
+	}
+	return report, nil
+}
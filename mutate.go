@@ -0,0 +1,223 @@
+package drift
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrModelInUse is returned by RemoveModel for a model links still refer to.
+var ErrModelInUse = errors.New("drift: model is used by links")
+
+// linkActions are the scenario actions whose Target is a link name.
+var linkActions = map[string]bool{
+	ActionEnableLink:  true,
+	ActionDisableLink: true,
+	ActionSetGain:     true,
+	ActionRampGain:    true,
+	ActionInjectNoise: true,
+}
+
+// RemoveModel removes a model along with its input segments, resource hints,
+// training assignments and probes. Links from or to the model are removed too when
+// cascade is set; otherwise RemoveModel fails with ErrModelInUse, naming
+// them.
+func (c *Config) RemoveModel(name string, cascade bool) error {
+	if _, ok := c.Models[name]; !ok {
+		return fmt.Errorf("model %q not found", name)
+	}
+	var dependents []string
+	for _, l := range c.Links {
+		if r := c.resolveLink(l); r.SourceModel == name || r.TargetModel == name {
+			dependents = append(dependents, l.Name)
+		}
+	}
+	if len(dependents) > 0 && !cascade {
+		return fmt.Errorf("model %q: %w %s", name, ErrModelInUse, strings.Join(dependents, ", "))
+	}
+	for _, link := range dependents {
+		if err := c.checkCoTrain(link); err != nil {
+			return err
+		}
+	}
+	for _, link := range dependents {
+		c.removeLink(link)
+	}
+	delete(c.Models, name)
+	delete(c.Inputs, name)
+	delete(c.Resources, name)
+	delete(c.Metadata, name)
+	for i := range c.Training {
+		c.Training[i].Train = replaceName(c.Training[i].Train, name, "")
+	}
+	probes := c.Probes[:0]
+	for _, p := range c.Probes {
+		if p.Model != name {
+			probes = append(probes, p)
+		}
+	}
+	c.Probes = probes
+	return nil
+}
+
+// RemoveLink removes a link and the scenario interventions and probes that
+// act on it.
+// It fails if a training phase co-trains over the link.
+func (c *Config) RemoveLink(name string) error {
+	if _, err := c.linkIndex(name); err != nil {
+		return err
+	}
+	if err := c.checkCoTrain(name); err != nil {
+		return err
+	}
+	c.removeLink(name)
+	return nil
+}
+
+// UpdateLink replaces the link called name with link. When link has a new
+// name, scenario interventions, training phases and probes referring to the
+// old one follow it.
+func (c *Config) UpdateLink(name string, link NeuralLinkConfig) error {
+	i, err := c.linkIndex(name)
+	if err != nil {
+		return err
+	}
+	if link.Name != name {
+		if _, err := c.linkIndex(link.Name); err == nil {
+			return fmt.Errorf("link %q already exists", link.Name)
+		}
+		for j, iv := range c.Scenario {
+			if linkActions[iv.Action] && iv.Target == name {
+				c.Scenario[j].Target = link.Name
+			}
+		}
+		for j, p := range c.Training {
+			if p.CoTrain == name {
+				c.Training[j].CoTrain = link.Name
+			}
+		}
+		for j, p := range c.Probes {
+			if p.Link == name {
+				c.Probes[j].Link = link.Name
+			}
+		}
+	}
+	old := c.Links[i]
+	if link.ID == "" {
+		link.ID = old.LinkID()
+	}
+	c.Links[i] = link
+	delete(c.linkIdx, old.Name)
+	delete(c.linkByID, old.LinkID())
+	c.indexLink(i)
+	return nil
+}
+
+// RenameModel renames a model, updating every link, input segment, resource
+// hint, training phase, scenario intervention and probe that refers to it.
+func (c *Config) RenameModel(oldName, newName string) error {
+	def, ok := c.Models[oldName]
+	if !ok {
+		return fmt.Errorf("model %q not found", oldName)
+	}
+	if _, ok := c.Models[newName]; ok {
+		return fmt.Errorf("model %q already exists", newName)
+	}
+	// Resolve link ends before the old name disappears.
+	for i, l := range c.Links {
+		r := c.resolveLink(l)
+		if r.SourceModel == oldName {
+			c.Links[i].SourceModel = newName
+		}
+		if r.TargetModel == oldName {
+			c.Links[i].TargetModel = newName
+		}
+	}
+	delete(c.Models, oldName)
+	c.Models[newName] = def
+	if segs, ok := c.Inputs[oldName]; ok {
+		delete(c.Inputs, oldName)
+		c.Inputs[newName] = segs
+	}
+	if hints, ok := c.Resources[oldName]; ok {
+		delete(c.Resources, oldName)
+		c.Resources[newName] = hints
+	}
+	if md, ok := c.Metadata[oldName]; ok {
+		delete(c.Metadata, oldName)
+		c.Metadata[newName] = md
+	}
+	for i := range c.Training {
+		c.Training[i].Train = replaceName(c.Training[i].Train, oldName, newName)
+	}
+	for i, iv := range c.Scenario {
+		if !linkActions[iv.Action] && iv.Target == oldName {
+			c.Scenario[i].Target = newName
+		}
+	}
+	for i, p := range c.Probes {
+		if p.Model == oldName {
+			c.Probes[i].Model = newName
+		}
+	}
+	return nil
+}
+
+// linkIndex returns the position of the link called name.
+func (c *Config) linkIndex(name string) (int, error) {
+	if i := c.findLink(name); i >= 0 {
+		return i, nil
+	}
+	return -1, fmt.Errorf("link %q not found", name)
+}
+
+// checkCoTrain fails if a training phase co-trains over link.
+func (c *Config) checkCoTrain(link string) error {
+	for _, p := range c.Training {
+		if p.CoTrain == link {
+			return fmt.Errorf("link %q is co-trained by phase %q", link, p.Name)
+		}
+	}
+	return nil
+}
+
+// removeLink deletes the link called name, its scenario interventions and
+// its probes.
+func (c *Config) removeLink(name string) {
+	links := c.Links[:0]
+	for _, l := range c.Links {
+		if l.Name != name {
+			links = append(links, l)
+		}
+	}
+	c.Links = links
+	scenario := c.Scenario[:0]
+	for _, iv := range c.Scenario {
+		if !linkActions[iv.Action] || iv.Target != name {
+			scenario = append(scenario, iv)
+		}
+	}
+	c.Scenario = scenario
+	probes := c.Probes[:0]
+	for _, p := range c.Probes {
+		if p.Link != name {
+			probes = append(probes, p)
+		}
+	}
+	c.Probes = probes
+}
+
+// replaceName replaces oldName in names with newName, or removes it when
+// newName is empty.
+func replaceName(names []string, oldName, newName string) []string {
+	out := names[:0]
+	for _, n := range names {
+		switch {
+		case n != oldName:
+			out = append(out, n)
+		case newName != "":
+			out = append(out, newName)
+		}
+	}
+	return out
+}
@@ -0,0 +1,101 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Normalize rewrites the config into a standard form, so that configs from
+// different tools hash and diff alike:
+//
+//   - links loaded from a file without an "enabled" field are enabled;
+//   - links get their generated IDs (see LinkID) and are sorted by name;
+//   - scenario interventions are sorted by step;
+//   - nil maps and slices become empty ones;
+//   - model definitions are compacted with sorted keys, numbers kept as
+//     written.
+//
+// It fails only on a model definition that isn't valid JSON.
+func (c *Config) Normalize() error {
+	c.SchemaVersion = CurrentSchemaVersion
+	if c.Models == nil {
+		c.Models = make(map[string]json.RawMessage)
+	}
+	for name, raw := range c.Models {
+		canon, err := canonicalRaw(raw)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", name, err)
+		}
+		c.Models[name] = canon
+	}
+
+	if c.Links == nil {
+		c.Links = []NeuralLinkConfig{}
+	}
+	for i := range c.Links {
+		l := &c.Links[i]
+		if c.enabledUnset[l.Name] {
+			l.Enabled = true
+		}
+		l.ID = l.LinkID()
+	}
+	c.enabledUnset = nil
+	sort.SliceStable(c.Links, func(i, j int) bool { return c.Links[i].Name < c.Links[j].Name })
+	c.linkIdx, c.linkByID = nil, nil
+
+	if c.Scenario == nil {
+		c.Scenario = []Intervention{}
+	}
+	sort.SliceStable(c.Scenario, func(i, j int) bool { return c.Scenario[i].AtStep < c.Scenario[j].AtStep })
+	if c.Training == nil {
+		c.Training = []TrainingPhase{}
+	}
+	if c.Probes == nil {
+		c.Probes = []Probe{}
+	}
+	if c.Inputs == nil {
+		c.Inputs = make(map[string][]InputSegment)
+	}
+	if c.Resources == nil {
+		c.Resources = make(map[string]ResourceHints)
+	}
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]ModelMetadata)
+	}
+	return nil
+}
+
+// canonicalRaw compacts a JSON value with object keys sorted.
+func canonicalRaw(raw json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+// linksWithoutEnabled returns the names of the links in a config document
+// that don't set "enabled", or nil if there are none.
+func linksWithoutEnabled(doc map[string]any) map[string]bool {
+	links, _ := doc["links"].([]any)
+	var unset map[string]bool
+	for _, l := range links {
+		link, ok := l.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, set := link["enabled"]; set {
+			continue
+		}
+		if unset == nil {
+			unset = make(map[string]bool)
+		}
+		name, _ := link["name"].(string)
+		unset[name] = true
+	}
+	return unset
+}
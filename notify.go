@@ -0,0 +1,137 @@
+package drift
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// WebhookFormat selects the payload shape a Notifier posts.
+type WebhookFormat int
+
+const (
+	// WebhookJSON posts a Notification object.
+	WebhookJSON WebhookFormat = iota
+	// WebhookSlack posts {"text": ...}, accepted by Slack, Mattermost and
+	// Discord (via its /slack endpoint) incoming webhooks.
+	WebhookSlack
+)
+
+// Notification is the payload posted in WebhookJSON format.
+type Notification struct {
+	Kind  string    `json:"kind"` // run_finished, guard or threshold
+	Title string    `json:"title"`
+	Text  string    `json:"text"`
+	Value float64   `json:"value,omitempty"`
+	Time  time.Time `json:"time"`
+}
+
+// Threshold fires a notification when a metric crosses Value: upward when
+// Above is set, downward otherwise. It fires once per crossing.
+type Threshold struct {
+	Metric string
+	Above  bool
+	Value  float64
+}
+
+// Notifier posts run milestones to a webhook: run completion, guard events
+// (as an EventHandler or GuardPolicy handler) and metric thresholds (as a
+// MetricSink), so long sweeps can run unattended.
+type Notifier struct {
+	URL        string
+	Format     WebhookFormat
+	Run        string // Prefixed to every title when set
+	Thresholds []Threshold
+	Client     *http.Client
+
+	mu    sync.Mutex
+	fired map[int]bool
+}
+
+// NewNotifier creates a notifier posting to url in the given format.
+func NewNotifier(url string, format WebhookFormat) *Notifier {
+	return &Notifier{URL: url, Format: format}
+}
+
+// Notify posts note to the webhook.
+func (n *Notifier) Notify(note Notification) error {
+	if note.Time.IsZero() {
+		note.Time = time.Now()
+	}
+	if n.Run != "" {
+		note.Title = n.Run + ": " + note.Title
+	}
+	var body any = note
+	if n.Format == WebhookSlack {
+		body = map[string]string{"text": fmt.Sprintf("*%s*\n%s", note.Title, note.Text)}
+	}
+	if err := postJSON(n.Client, http.MethodPost, n.URL, body, nil, nil); err != nil {
+		return fmt.Errorf("notify: %w", err)
+	}
+	return nil
+}
+
+// RunFinished posts a completion notice with the runtime's stats. A non-nil
+// err reports the run as failed.
+func (n *Notifier) RunFinished(stats RuntimeStats, err error) error {
+	title, text := "run finished", fmt.Sprintf("%d steps in %s", stats.Steps, stats.Uptime.Round(time.Second))
+	if err != nil {
+		title, text = "run failed", fmt.Sprintf("%s after %s", err, text)
+	}
+	return n.Notify(Notification{Kind: "run_finished", Title: title, Text: text, Value: float64(stats.Steps)})
+}
+
+// Handle posts a guard event. Its signature matches EventHandler, so it can
+// be registered with GuardPolicy.On for the actions worth a notification.
+// Delivery errors are dropped, since event handlers cannot return them.
+func (n *Notifier) Handle(e Event) {
+	n.Notify(Notification{
+		Kind:  "guard",
+		Title: fmt.Sprintf("%s from %s at step %d", e.Kind, e.Source, e.Step),
+		Text:  e.Message,
+		Value: e.Value,
+		Time:  e.Time,
+	})
+}
+
+// Record implements MetricSink, posting when a metric crosses one of the
+// configured thresholds. A threshold re-arms once the metric moves back.
+func (n *Notifier) Record(m Metric) error {
+	var notes []Notification
+	n.mu.Lock()
+	if n.fired == nil {
+		n.fired = make(map[int]bool)
+	}
+	for i, t := range n.Thresholds {
+		if t.Metric != m.Name {
+			continue
+		}
+		crossed := m.Value < t.Value
+		dir := "below"
+		if t.Above {
+			crossed, dir = m.Value > t.Value, "above"
+		}
+		if crossed && !n.fired[i] {
+			notes = append(notes, Notification{
+				Kind:  "threshold",
+				Title: fmt.Sprintf("%s %s %g", metricKey(m), dir, t.Value),
+				Text:  fmt.Sprintf("%s = %g at step %d", metricKey(m), m.Value, m.Step),
+				Value: m.Value,
+				Time:  m.Time,
+			})
+		}
+		n.fired[i] = crossed
+	}
+	n.mu.Unlock()
+
+	for _, note := range notes {
+		if err := n.Notify(note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush implements MetricSink. Notifications are sent immediately.
+func (n *Notifier) Flush() error { return nil }
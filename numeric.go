@@ -0,0 +1,193 @@
+package drift
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// ScanMode controls how much of each buffer a NumericGuard inspects.
+type ScanMode int
+
+const (
+	// ScanFull checks every element every step.
+	ScanFull ScanMode = iota
+	// ScanSampled checks every SampleStride-th element, rotating the starting
+	// element each step so the whole buffer is covered over SampleStride steps.
+	ScanSampled
+)
+
+// NumericResponse is what a NumericGuard does when it finds a NaN or Inf.
+type NumericResponse int
+
+const (
+	// RespondZero replaces non-finite values with zero and continues.
+	RespondZero NumericResponse = iota
+	// RespondRollback discards the step: model outputs and link payloads are
+	// restored to the previous step and Step returns the previous outputs.
+	// Step states cannot be copied, so every model's step state is reset,
+	// not just the offending model's: the next step starts from empty layer
+	// buffers across the whole runtime.
+	RespondRollback
+	// RespondHalt stops the runtime. Step returns a *NumericError now and on
+	// every later call, after writing a diagnostic dump to Dump.
+	RespondHalt
+)
+
+// NumericGuard scans model outputs and link payloads for NaN and Inf on every
+// step, so a single bad value does not propagate silently through links.
+type NumericGuard struct {
+	Mode         ScanMode
+	SampleStride int // Element stride in ScanSampled mode; defaults to 8
+	Response     NumericResponse
+	Dump         io.Writer               // Receives the diagnostic dump on RespondHalt
+	OnDetect     func(err *NumericError) // Called for every detection, whatever the response
+}
+
+// NumericError reports a non-finite value found by a NumericGuard.
+type NumericError struct {
+	Step   uint64  `json:"step"`
+	Source string  `json:"source"` // "model" or "link"
+	Name   string  `json:"name"`
+	Index  int     `json:"index"`
+	Value  float32 `json:"value"`
+}
+
+func (e *NumericError) Error() string {
+	return fmt.Sprintf("drift: non-finite value %v in %s %q at index %d (step %d)", e.Value, e.Source, e.Name, e.Index, e.Step)
+}
+
+// SetNumericGuard installs g on the runtime. Passing nil disables scanning.
+func (r *Runtime) SetNumericGuard(g *NumericGuard) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.guard = g
+}
+
+// scan returns the index of the first non-finite value it inspects in s, or -1.
+func (g *NumericGuard) scan(s []float32, step uint64) int {
+	start, stride := 0, 1
+	if g.Mode == ScanSampled {
+		stride = g.SampleStride
+		if stride <= 0 {
+			stride = 8
+		}
+		start = int(step % uint64(stride))
+	}
+	for i := start; i < len(s); i += stride {
+		if !isFinite(s[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isFinite(v float32) bool {
+	f := float64(v)
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// zeroNonFinite replaces every NaN and Inf in s with zero.
+func zeroNonFinite(s []float32) {
+	for i, v := range s {
+		if !isFinite(v) {
+			s[i] = 0
+		}
+	}
+}
+
+// stepSnapshot holds the buffers a rollback restores.
+type stepSnapshot struct {
+	outputs  map[string][]float32
+	payloads map[*runtimeLink][]float32
+	sums     map[*runtimeLink]uint32
+}
+
+// snapshot copies model outputs, link payloads and their checksums. The caller holds r.mu.
+func (r *Runtime) snapshot() *stepSnapshot {
+	s := &stepSnapshot{
+		outputs:  make(map[string][]float32, len(r.models)),
+		payloads: make(map[*runtimeLink][]float32, len(r.links)),
+		sums:     make(map[*runtimeLink]uint32, len(r.links)),
+	}
+	for name, m := range r.models {
+		s.outputs[name] = append([]float32(nil), m.output...)
+	}
+	for _, l := range r.links {
+		if l.payload != nil {
+			s.payloads[l] = append([]float32(nil), l.payload...)
+			s.sums[l] = l.sum
+		}
+	}
+	return s
+}
+
+// restore puts back the buffers saved by snapshot and resets every model's
+// step state, which snapshot cannot copy. The caller holds r.mu.
+func (r *Runtime) restore(s *stepSnapshot) {
+	for name, m := range r.models {
+		m.output = s.outputs[name]
+		m.state = m.net.InitStepState(len(m.input))
+	}
+	for _, l := range r.links {
+		l.payload, l.sum = s.payloads[l], s.sums[l]
+	}
+}
+
+// checkNumeric scans buf, named name, and applies the guard's response. It
+// returns a non-nil error when the step must not continue. The caller holds r.mu.
+func (r *Runtime) checkNumeric(source, name string, buf []float32, snap *stepSnapshot) error {
+	g := r.guard
+	i := g.scan(buf, r.steps)
+	if i < 0 {
+		return nil
+	}
+	nerr := &NumericError{Step: r.steps, Source: source, Name: name, Index: i, Value: buf[i]}
+	if g.OnDetect != nil {
+		g.OnDetect(nerr)
+	}
+
+	switch g.Response {
+	case RespondRollback:
+		r.restore(snap)
+		return errRolledBack
+	case RespondHalt:
+		if g.Dump != nil {
+			r.dumpNumeric(g.Dump, nerr)
+		}
+		r.halted = nerr
+		return nerr
+	default:
+		zeroNonFinite(buf)
+		if source == "link" {
+			// The zeroed payload is the one delivered; checksum it afresh.
+			r.link(name).seal()
+		}
+		return nil
+	}
+}
+
+// dumpNumeric writes the state around a detection: the offending value and a
+// per-buffer count of non-finite values. The caller holds r.mu.
+func (r *Runtime) dumpNumeric(w io.Writer, e *NumericError) {
+	fmt.Fprintf(w, "numeric halt: %v\n", e)
+	for _, name := range r.order {
+		m := r.models[name]
+		fmt.Fprintf(w, "  model %s: input non-finite=%d output non-finite=%d\n",
+			name, countNonFinite(m.input), countNonFinite(m.output))
+	}
+	for _, l := range r.links {
+		fmt.Fprintf(w, "  link %s: enabled=%v gain=%g payload non-finite=%d\n",
+			l.cfg.Name, l.cfg.Enabled, l.gain, countNonFinite(l.payload))
+	}
+}
+
+func countNonFinite(s []float32) int {
+	n := 0
+	for _, v := range s {
+		if !isFinite(v) {
+			n++
+		}
+	}
+	return n
+}
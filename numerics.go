@@ -0,0 +1,242 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Platform identifies the machine a numerics trace was recorded on.
+type Platform struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	GoVersion string `json:"go_version"`
+}
+
+// CurrentPlatform returns the platform the program runs on.
+func CurrentPlatform() Platform {
+	return Platform{OS: runtime.GOOS, Arch: runtime.GOARCH, GoVersion: runtime.Version()}
+}
+
+func (p Platform) String() string {
+	return fmt.Sprintf("%s/%s %s", p.OS, p.Arch, p.GoVersion)
+}
+
+// NumericsTrace is a reference run of a seeded config: the inputs and
+// outputs of every step, and where they were computed. Record one on a
+// trusted machine and verify other platforms against it with
+// VerifyNumerics, e.g. arm64 robots against an amd64 server.
+type NumericsTrace struct {
+	Platform   Platform     `json:"platform"`
+	ConfigHash string       `json:"config_hash"` // See Config.Hash
+	Created    time.Time    `json:"created"`
+	Steps      []StepRecord `json:"steps"`
+}
+
+// RecordNumerics runs a seeded config for steps steps on the inputs
+// produced by inputs, or on SeededInputs(cfg, cfg.Seed) when inputs is nil,
+// and returns the trace.
+func RecordNumerics(cfg *Config, steps int, inputs func(step int) map[string][]float32) (*NumericsTrace, error) {
+	hash, err := cfg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if inputs == nil {
+		inputs = SeededInputs(cfg, cfg.Seed)
+	}
+	records, err := GenerateGolden(cfg, steps, inputs)
+	if err != nil {
+		return nil, err
+	}
+	return &NumericsTrace{Platform: CurrentPlatform(), ConfigHash: hash, Created: time.Now().UTC(), Steps: records}, nil
+}
+
+// SeededInputs returns an input generator that fills every model's input
+// with uniform values in [-1, 1), reproducibly from seed on any platform.
+func SeededInputs(cfg *Config, seed int64) func(step int) map[string][]float32 {
+	sizes := make(map[string]int, len(cfg.Models))
+	for name, raw := range cfg.Models {
+		if n, err := modelInputSize(raw); err == nil {
+			sizes[name] = n
+		}
+	}
+	names := sortedKeys(sizes)
+	return func(step int) map[string][]float32 {
+		rng := seededStream(seed+int64(step), StreamEnvironment)
+		in := make(map[string][]float32, len(names))
+		for _, name := range names {
+			v := make([]float32, sizes[name])
+			for i := range v {
+				v[i] = float32(rng.Float64()*2 - 1)
+			}
+			in[name] = v
+		}
+		return in
+	}
+}
+
+// Save writes the trace to path as JSON.
+func (t *NumericsTrace) Save(path string) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0644, false)
+}
+
+// LoadNumericsTrace reads a trace written by Save.
+func LoadNumericsTrace(path string) (*NumericsTrace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t NumericsTrace
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &t, nil
+}
+
+// ModelNumerics summarizes how far one model's outputs moved from the
+// reference.
+type ModelNumerics struct {
+	MaxAbsDiff float64 `json:"max_abs_diff"`
+	MaxStep    int     `json:"max_step"`   // Step of the largest difference
+	FirstStep  int     `json:"first_step"` // First step beyond tolerance, or -1
+	Exceeded   int     `json:"exceeded"`   // Output elements beyond tolerance, over all steps
+}
+
+// NumericsReport is the outcome of VerifyNumerics.
+type NumericsReport struct {
+	Reference Platform `json:"reference"`
+	Current   Platform `json:"current"`
+	// CrossPlatform is set when the platforms differ, so that a divergence
+	// is platform-dependent rather than the result of a code change.
+	CrossPlatform bool                     `json:"cross_platform"`
+	Steps         int                      `json:"steps"`
+	Tolerance     float64                  `json:"tolerance"`
+	MaxAbsDiff    float64                  `json:"max_abs_diff"`
+	StepMaxDiff   []float64                `json:"step_max_diff"` // Largest difference per step, to see errors grow
+	Models        map[string]ModelNumerics `json:"models"`
+	First         *Divergence              `json:"first,omitempty"` // First element beyond tolerance
+}
+
+// OK reports whether every output stayed within tolerance.
+func (r *NumericsReport) OK() bool {
+	return r.First == nil
+}
+
+func (r *NumericsReport) String() string {
+	var b strings.Builder
+	verdict := "within tolerance"
+	if !r.OK() {
+		verdict = "DIVERGED"
+		if r.CrossPlatform {
+			verdict += " (platform-dependent)"
+		}
+	}
+	fmt.Fprintf(&b, "%s vs reference %s: %d steps, max diff %.3g, tolerance %g: %s\n",
+		r.Current, r.Reference, r.Steps, r.MaxAbsDiff, r.Tolerance, verdict)
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "model\tmax diff\tat step\tfirst over\texceeded")
+	for _, name := range sortedKeys(r.Models) {
+		m := r.Models[name]
+		first := "-"
+		if m.FirstStep >= 0 {
+			first = fmt.Sprint(m.FirstStep)
+		}
+		fmt.Fprintf(tw, "%s\t%.3g\t%d\t%s\t%d\n", name, m.MaxAbsDiff, m.MaxStep, first, m.Exceeded)
+	}
+	tw.Flush()
+	if r.First != nil {
+		fmt.Fprintf(&b, "first divergence: %v\n", r.First)
+	}
+	return b.String()
+}
+
+// VerifyNumerics replays the reference trace's inputs through a fresh
+// runtime for cfg on this platform and compares every step's outputs
+// against it. Unlike CheckGolden it doesn't stop at the first divergence:
+// the report shows how far each model drifted and how the difference grew.
+// cfg must be the config the trace was recorded with.
+func VerifyNumerics(cfg *Config, ref *NumericsTrace, tol float64) (*NumericsReport, error) {
+	if cfg.Seed == 0 {
+		return nil, ErrUnseeded
+	}
+	hash, err := cfg.Hash()
+	if err != nil {
+		return nil, err
+	}
+	if hash != ref.ConfigHash {
+		return nil, fmt.Errorf("config hash %s differs from the trace's %s", hash, ref.ConfigHash)
+	}
+	r, err := NewRuntime(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rep := &NumericsReport{
+		Reference: ref.Platform,
+		Current:   CurrentPlatform(),
+		Steps:     len(ref.Steps),
+		Tolerance: tol,
+		Models:    make(map[string]ModelNumerics),
+	}
+	rep.CrossPlatform = rep.Reference != rep.Current
+	for step, rec := range ref.Steps {
+		out, err := r.Step(rec.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %w", step, err)
+		}
+		if rep.First == nil {
+			rep.First = compareOutputs(step, rec.Outputs, out, tol)
+		}
+		stepMax := 0.0
+		for _, name := range sortedKeys(rec.Outputs) {
+			m, ok := rep.Models[name]
+			if !ok {
+				m.FirstStep = -1
+			}
+			want, got := rec.Outputs[name], out[name]
+			for i := range want {
+				diff := elementDiff(want, got, i)
+				if diff > m.MaxAbsDiff {
+					m.MaxAbsDiff, m.MaxStep = diff, step
+				}
+				if diff > tol {
+					m.Exceeded++
+					if m.FirstStep < 0 {
+						m.FirstStep = step
+					}
+				}
+				stepMax = math.Max(stepMax, diff)
+			}
+			rep.Models[name] = m
+		}
+		rep.StepMaxDiff = append(rep.StepMaxDiff, stepMax)
+		rep.MaxAbsDiff = math.Max(rep.MaxAbsDiff, stepMax)
+	}
+	return rep, nil
+}
+
+// elementDiff returns the absolute difference of want[i] and got[i]. A
+// missing element, a NaN against a number or infinities of opposite sign
+// count as math.MaxFloat64, which unlike Inf survives JSON encoding.
+func elementDiff(want, got []float32, i int) float64 {
+	if i >= len(got) {
+		return math.MaxFloat64
+	}
+	w, g := float64(want[i]), float64(got[i])
+	if math.IsNaN(w) && math.IsNaN(g) || w == g {
+		return 0
+	}
+	diff := math.Abs(w - g)
+	if math.IsNaN(diff) || math.IsInf(diff, 0) {
+		return math.MaxFloat64
+	}
+	return diff
+}
@@ -0,0 +1,92 @@
+package drift
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// Policy maps an observation to a discrete action.
+// Both hand-written heuristics and trained networks implement it, so either
+// can drive an agent or act as a supervision source for a trainer.
+type Policy interface {
+	Act(obs []float32) int
+}
+
+// PolicyFunc adapts an ordinary function to the Policy interface.
+type PolicyFunc func(obs []float32) int
+
+// Act calls f(obs).
+func (f PolicyFunc) Act(obs []float32) int {
+	return f(obs)
+}
+
+// NetworkPolicy acts greedily on the output of a loom network.
+type NetworkPolicy struct {
+	Net   *nn.Network
+	State *nn.StepState
+}
+
+// NewNetworkPolicy creates a NetworkPolicy with a fresh step state for inputSize inputs.
+func NewNetworkPolicy(net *nn.Network, inputSize int) *NetworkPolicy {
+	return &NetworkPolicy{Net: net, State: net.InitStepState(inputSize)}
+}
+
+// Act steps the network forward on obs and returns the index of the highest output.
+func (p *NetworkPolicy) Act(obs []float32) int {
+	p.State.SetInput(obs)
+	p.Net.StepForward(p.State)
+	return Argmax(p.State.GetOutput())
+}
+
+// oracles holds registered heuristic policies keyed by environment, then by name.
+var oracles = struct {
+	sync.RWMutex
+	m map[string]map[string]Policy
+}{m: make(map[string]map[string]Policy)}
+
+// RegisterOracle registers a heuristic policy under name for the given environment.
+// Registering the same name twice replaces the earlier policy.
+func RegisterOracle(env, name string, p Policy) {
+	oracles.Lock()
+	defer oracles.Unlock()
+	if oracles.m[env] == nil {
+		oracles.m[env] = make(map[string]Policy)
+	}
+	oracles.m[env][name] = p
+}
+
+// GetOracle returns the oracle registered under name for the given environment.
+func GetOracle(env, name string) (Policy, bool) {
+	oracles.RLock()
+	defer oracles.RUnlock()
+	p, ok := oracles.m[env][name]
+	return p, ok
+}
+
+// OracleNames returns the sorted names of all oracles registered for env.
+func OracleNames(env string) []string {
+	oracles.RLock()
+	defer oracles.RUnlock()
+	names := make([]string, 0, len(oracles.m[env]))
+	for name := range oracles.m[env] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Argmax returns the index of the largest value in s, or 0 if s is empty.
+func Argmax(s []float32) int {
+	if len(s) == 0 {
+		return 0
+	}
+	maxI, maxV := 0, s[0]
+	for i, v := range s {
+		if v > maxV {
+			maxV, maxI = v, i
+		}
+	}
+	return maxI
+}
@@ -0,0 +1,134 @@
+package drift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Memory classes, from smallest to largest.
+const (
+	MemorySmall  = "small"
+	MemoryMedium = "medium"
+	MemoryLarge  = "large"
+)
+
+var memoryRank = map[string]int{"": 0, MemorySmall: 1, MemoryMedium: 2, MemoryLarge: 3}
+
+// ResourceHints declares what a model needs from the slot that executes it.
+type ResourceHints struct {
+	MaxLatencyMs float64 `json:"max_latency_ms,omitempty"` // Largest acceptable per-step latency of the slot
+	MemoryClass  string  `json:"memory_class,omitempty"`   // Minimum memory class
+	RequiresFP32 bool    `json:"requires_fp32,omitempty"`  // Needs full single-precision arithmetic
+	DeviceClass  string  `json:"device_class,omitempty"`   // Prices the model's compute in an EnergyModel
+}
+
+// Slot is a local or remote place a model can execute.
+type Slot struct {
+	Name        string  `json:"name"`
+	Remote      bool    `json:"remote,omitempty"`
+	LatencyMs   float64 `json:"latency_ms"`   // Expected per-step latency, including transport for remote slots
+	MemoryClass string  `json:"memory_class"` // Largest memory class the slot can host
+	FP32        bool    `json:"fp32"`         // Supports full single-precision arithmetic
+	Capacity    int     `json:"capacity"`     // Models the slot can host; 0 means 1
+}
+
+// fits reports whether s satisfies h.
+func (s Slot) fits(h ResourceHints) bool {
+	if h.MaxLatencyMs > 0 && s.LatencyMs > h.MaxLatencyMs {
+		return false
+	}
+	if memoryRank[s.MemoryClass] < memoryRank[h.MemoryClass] {
+		return false
+	}
+	return !h.RequiresFP32 || s.FP32
+}
+
+// PlacementError reports models no slot assignment can satisfy.
+type PlacementError struct {
+	Models []string
+}
+
+func (e *PlacementError) Error() string {
+	return "placement: no slot assignment satisfies " + strings.Join(e.Models, ", ")
+}
+
+// Plan assigns every model of cfg to a slot honoring the models' resource
+// hints and slot capacities, preferring local and low-latency slots. Models
+// without hints may go anywhere. It returns model → slot name, or a
+// *PlacementError naming the models that can't be placed.
+func Plan(cfg *Config, slots []Slot) (map[string]string, error) {
+	for name, h := range cfg.Resources {
+		if _, ok := cfg.Models[name]; !ok {
+			return nil, fmt.Errorf("resources: unknown model %q", name)
+		}
+		if _, ok := memoryRank[h.MemoryClass]; !ok {
+			return nil, fmt.Errorf("resources: model %q: unknown memory class %q", name, h.MemoryClass)
+		}
+	}
+
+	order := make([]int, len(slots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		sa, sb := slots[order[a]], slots[order[b]]
+		if sa.Remote != sb.Remote {
+			return !sa.Remote
+		}
+		return sa.LatencyMs < sb.LatencyMs
+	})
+
+	// Place the most constrained models first.
+	models := make([]string, 0, len(cfg.Models))
+	candidates := make(map[string][]int, len(cfg.Models))
+	var unsatisfiable []string
+	for name := range cfg.Models {
+		for _, i := range order {
+			if slots[i].fits(cfg.Resources[name]) {
+				candidates[name] = append(candidates[name], i)
+			}
+		}
+		if len(candidates[name]) == 0 {
+			unsatisfiable = append(unsatisfiable, name)
+		}
+		models = append(models, name)
+	}
+	if len(unsatisfiable) > 0 {
+		sort.Strings(unsatisfiable)
+		return nil, &PlacementError{Models: unsatisfiable}
+	}
+	sort.Slice(models, func(a, b int) bool {
+		ca, cb := len(candidates[models[a]]), len(candidates[models[b]])
+		if ca != cb {
+			return ca < cb
+		}
+		return models[a] < models[b]
+	})
+
+	used := make([]int, len(slots))
+	plan := make(map[string]string, len(models))
+	var place func(k int) bool
+	place = func(k int) bool {
+		if k == len(models) {
+			return true
+		}
+		for _, i := range candidates[models[k]] {
+			if used[i] >= max(slots[i].Capacity, 1) {
+				continue
+			}
+			used[i]++
+			plan[models[k]] = slots[i].Name
+			if place(k + 1) {
+				return true
+			}
+			used[i]--
+		}
+		return false
+	}
+	if !place(0) {
+		sort.Strings(models)
+		return nil, &PlacementError{Models: models}
+	}
+	return plan, nil
+}
@@ -0,0 +1,148 @@
+package drift
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// PartnerPool holds past snapshots of one model for fictitious self-play:
+// each episode the runtime runs either the live network or a randomly chosen
+// snapshot in that model's place, so the other agents can't overfit their
+// protocol to a single co-adapting partner.
+type PartnerPool struct {
+	Model    string
+	MaxSize  int     // Snapshots kept, oldest evicted first; 0 for no limit
+	LiveProb float64 // Probability of playing against the live network
+
+	mu        sync.Mutex
+	snapshots []*nn.Network
+	live      *nn.Network // The live network while a snapshot is installed
+	rng       Rand
+}
+
+// NewPartnerPool creates a pool for model keeping up to maxSize snapshots.
+func NewPartnerPool(model string, maxSize int, liveProb float64, seed int64) *PartnerPool {
+	return &PartnerPool{Model: model, MaxSize: maxSize, LiveProb: liveProb, rng: seededStream(seed, SubStream(StreamExploration, model))}
+}
+
+// Add stores a copy of net as a snapshot.
+func (p *PartnerPool) Add(net *nn.Network) error {
+	snap, err := cloneNetwork(net)
+	if err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.snapshots = append(p.snapshots, snap)
+	if p.MaxSize > 0 && len(p.snapshots) > p.MaxSize {
+		p.snapshots = append(p.snapshots[:0], p.snapshots[len(p.snapshots)-p.MaxSize:]...)
+	}
+	return nil
+}
+
+// AddCheckpoint stores the pool's model from a checkpoint written by
+// SaveCheckpoint.
+func (p *PartnerPool) AddCheckpoint(path string) error {
+	bundle, err := readCheckpoint(path)
+	if err != nil {
+		return err
+	}
+	for _, saved := range bundle.Models {
+		if saved.ID != p.Model {
+			continue
+		}
+		net, err := nn.DeserializeModel(saved)
+		if err != nil {
+			return fmt.Errorf("model %q: %w", p.Model, err)
+		}
+		return p.Add(net)
+	}
+	return fmt.Errorf("checkpoint %s has no model %q", path, p.Model)
+}
+
+// Len returns the number of snapshots.
+func (p *PartnerPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.snapshots)
+}
+
+// AddPartnerPool registers a pool; NewEpisode samples from it.
+func (r *Runtime) AddPartnerPool(p *PartnerPool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.models[p.Model]; !ok {
+		return fmt.Errorf("model %q not found", p.Model)
+	}
+	if r.pools == nil {
+		r.pools = make(map[string]*PartnerPool)
+	}
+	r.pools[p.Model] = p
+	return nil
+}
+
+// SnapshotPartners adds the live network of every pooled model to its pool,
+// typically every few episodes.
+func (r *Runtime) SnapshotPartners() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, p := range r.pools {
+		p.mu.Lock()
+		net := p.live
+		p.mu.Unlock()
+		if net == nil {
+			net = r.models[name].net
+		}
+		if err := p.Add(net); err != nil {
+			return fmt.Errorf("model %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// NewEpisode samples a partner for every pooled model and installs it with a
+// fresh step state. It returns, per model, the index of the snapshot
+// installed, or -1 where the live network plays. Snapshots are shared with
+// the pool, so train a pooled model only after RestoreLive.
+func (r *Runtime) NewEpisode() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	picks := make(map[string]int, len(r.pools))
+	for name, p := range r.pools {
+		m := r.models[name]
+		p.mu.Lock()
+		if p.live == nil {
+			p.live = m.net
+		}
+		idx := -1
+		if len(p.snapshots) > 0 && p.rng.Float64() >= p.LiveProb {
+			idx = p.rng.Intn(len(p.snapshots))
+			m.net = p.snapshots[idx]
+		} else {
+			m.net = p.live
+		}
+		p.mu.Unlock()
+		m.state = m.net.InitStepState(len(m.input))
+		picks[name] = idx
+	}
+	return picks
+}
+
+// RestoreLive reinstalls the live network of every pooled model, e.g. before
+// training or checkpointing.
+func (r *Runtime) RestoreLive() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, p := range r.pools {
+		p.mu.Lock()
+		if p.live != nil {
+			m := r.models[name]
+			m.net = p.live
+			m.state = m.net.InitStepState(len(m.input))
+			p.live = nil
+		}
+		p.mu.Unlock()
+	}
+}
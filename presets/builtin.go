@@ -0,0 +1,208 @@
+package presets
+
+import (
+	"encoding/json"
+
+	"github.com/openfluke/drift"
+)
+
+func init() {
+	Register("multi_terrain_neural_link", MultiTerrainNeuralLink)
+	Register("heterogeneous_swarm", HeterogeneousSwarm)
+	Register("multi_scale", MultiScale)
+}
+
+// MultiTerrainNeuralLink is the terrain benchmark topology: a classifier
+// reading 8 terrain sensors and a navigator that gets the classifier's
+// hidden layer through the link "terrain_to_nav".
+//
+// The navigator's input is position (4) followed by the link payload (16).
+// Both branches of its parallel layer see the full input:
+//
+//	Input (20) = position(4) + neural_link(16)
+//	     ↓
+//	Dense(20→16) ‖ Dense(20→4)   concat → grid softmax (5x4)
+//	     ↓
+//	Dense(20→32) → LSTM(32→16) → Dense(16→4, sigmoid)
+func MultiTerrainNeuralLink() *drift.Config {
+	cfg := drift.NewConfig("MultiTerrainNeuralLink")
+	cfg.Models["classifier"] = json.RawMessage(`{
+		"batch_size": 1,
+		"grid_rows": 1,
+		"grid_cols": 1,
+		"layers_per_cell": 4,
+		"layers": [
+			{"type": "dense", "input_size": 8, "output_size": 32, "activation": "leaky_relu"},
+			{"type": "dense", "input_size": 32, "output_size": 16, "activation": "leaky_relu"},
+			{"type": "dense", "input_size": 16, "output_size": 4, "activation": "none"},
+			{"type": "softmax", "softmax_variant": "standard"}
+		]
+	}`)
+	cfg.Models["navigator"] = json.RawMessage(`{
+		"batch_size": 1,
+		"grid_rows": 1,
+		"grid_cols": 1,
+		"layers_per_cell": 5,
+		"layers": [
+			{
+				"type": "parallel",
+				"combine_mode": "concat",
+				"comment": "Both branches get full input(20)",
+				"branches": [
+					{
+						"type": "dense",
+						"input_size": 20,
+						"output_size": 16,
+						"activation": "leaky_relu",
+						"comment": "Navigation features from full input"
+					},
+					{
+						"type": "dense",
+						"input_size": 20,
+						"output_size": 4,
+						"activation": "none",
+						"comment": "Terrain logits (for softmax)"
+					}
+				]
+			},
+			{"type": "softmax", "softmax_variant": "grid", "softmax_rows": 5, "softmax_cols": 4, "temperature": 1.0, "comment": "Apply softmax to concat(16+4)=20"},
+			{"type": "dense", "input_size": 20, "output_size": 32, "activation": "leaky_relu", "comment": "Combine features"},
+			{"type": "lstm", "input_size": 32, "hidden_size": 16, "seq_length": 1, "comment": "Temporal reasoning"},
+			{"type": "dense", "input_size": 16, "output_size": 4, "activation": "sigmoid", "comment": "Action output"}
+		]
+	}`)
+	cfg.AddLink(drift.NeuralLinkConfig{
+		Name:         "terrain_to_nav",
+		SourceModel:  "classifier",
+		SourceLayer:  1,
+		TargetModel:  "navigator",
+		TargetOffset: 4, // After position (4), into neural link branch
+		LinkSize:     16,
+		Enabled:      true,
+		Description:  "Classifier hidden → Navigator parallel branch for terrain awareness",
+	})
+	return cfg
+}
+
+// HeterogeneousSwarm is a 2x2 grid of agents sharing a perception layer:
+// a scout (LSTM), an analyzer (MHA), an executor (dense ensemble) and a
+// coordinator (RNN), as the model "agent_swarm". It takes batches of 2
+// samples of 20 features.
+func HeterogeneousSwarm() *drift.Config {
+	cfg := drift.NewConfig("HeterogeneousSwarm")
+	cfg.Models["agent_swarm"] = json.RawMessage(`{
+		"batch_size": 2,
+		"grid_rows": 1,
+		"grid_cols": 2,
+		"layers_per_cell": 1,
+		"layers": [
+			{
+				"type": "dense",
+				"input_size": 20,
+				"output_size": 32,
+				"activation": "relu",
+				"comment": "Shared perception layer"
+			},
+			{
+				"type": "parallel",
+				"combine_mode": "grid_scatter",
+				"grid_output_rows": 2,
+				"grid_output_cols": 2,
+				"grid_output_layers": 1,
+				"grid_positions": [
+					{"branch_index": 0, "target_row": 0, "target_col": 0, "target_layer": 0},
+					{"branch_index": 1, "target_row": 0, "target_col": 1, "target_layer": 0},
+					{"branch_index": 2, "target_row": 1, "target_col": 0, "target_layer": 0},
+					{"branch_index": 3, "target_row": 1, "target_col": 1, "target_layer": 0}
+				],
+				"branches": [
+					{
+						"type": "lstm",
+						"input_size": 32,
+						"hidden_size": 10,
+						"seq_length": 1,
+						"comment": "Agent 0: Scout (temporal memory)"
+					},
+					{
+						"type": "mha",
+						"d_model": 32,
+						"num_heads": 4,
+						"seq_length": 1,
+						"comment": "Agent 1: Analyzer (attention-based)"
+					},
+					{
+						"type": "parallel",
+						"combine_mode": "add",
+						"branches": [
+							{"type": "dense", "input_size": 32, "output_size": 10, "activation": "relu"},
+							{"type": "dense", "input_size": 32, "output_size": 10, "activation": "gelu"},
+							{"type": "dense", "input_size": 32, "output_size": 10, "activation": "tanh"}
+						],
+						"comment": "Agent 2: Executor (ensemble decision)"
+					},
+					{
+						"type": "rnn",
+						"input_size": 32,
+						"hidden_size": 10,
+						"seq_length": 1,
+						"comment": "Agent 3: Coordinator (sequential processing)"
+					}
+				]
+			}
+		]
+	}`)
+	return cfg
+}
+
+// MultiScale stacks LayerNorm, RMSNorm and SwiGLU processors over a shared
+// dense layer, as the model "multi_scale" with 24 inputs.
+func MultiScale() *drift.Config {
+	cfg := drift.NewConfig("MultiScale")
+	cfg.Models["multi_scale"] = json.RawMessage(`{
+		"batch_size": 1,
+		"grid_rows": 1,
+		"grid_cols": 2,
+		"layers_per_cell": 1,
+		"layers": [
+			{
+				"type": "dense",
+				"input_size": 24,
+				"output_size": 24,
+				"activation": "relu"
+			},
+			{
+				"type": "parallel",
+				"combine_mode": "grid_scatter",
+				"grid_output_rows": 3,
+				"grid_output_cols": 1,
+				"grid_output_layers": 1,
+				"grid_positions": [
+					{"branch_index": 0, "target_row": 0, "target_col": 0, "target_layer": 0},
+					{"branch_index": 1, "target_row": 1, "target_col": 0, "target_layer": 0},
+					{"branch_index": 2, "target_row": 2, "target_col": 0, "target_layer": 0}
+				],
+				"branches": [
+					{
+						"type": "layer_norm",
+						"norm_size": 24,
+						"epsilon": 1e-5,
+						"comment": "Agent 0: LayerNorm processor"
+					},
+					{
+						"type": "rms_norm",
+						"norm_size": 24,
+						"epsilon": 1e-5,
+						"comment": "Agent 1: RMSNorm processor (Llama-style)"
+					},
+					{
+						"type": "swiglu",
+						"input_size": 24,
+						"output_size": 24,
+						"comment": "Agent 2: SwiGLU gated processor"
+					}
+				]
+			}
+		]
+	}`)
+	return cfg
+}
@@ -0,0 +1,59 @@
+// Package presets provides reference DRIFT topologies as ready configs, and
+// a registry for adding your own:
+//
+//	cfg := presets.MultiTerrainNeuralLink()
+//
+//	presets.Register("arm_controller", newArmController)
+//	cfg, err := presets.New("arm_controller")
+package presets
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/openfluke/drift"
+)
+
+// ErrUnknownPreset is returned when no preset is registered under a name.
+var ErrUnknownPreset = errors.New("drift: preset not registered")
+
+// registry holds preset constructors keyed by name.
+var registry = struct {
+	sync.RWMutex
+	m map[string]func() *drift.Config
+}{m: make(map[string]func() *drift.Config)}
+
+// Register registers a constructor for the preset name. It must return a
+// fresh config on every call, as callers are free to modify it. Registering
+// the same name twice replaces the earlier constructor, including a
+// built-in one.
+func Register(name string, newConfig func() *drift.Config) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.m[name] = newConfig
+}
+
+// New returns a fresh config from the preset name.
+func New(name string) (*drift.Config, error) {
+	registry.RLock()
+	newConfig, ok := registry.m[name]
+	registry.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("preset %q: %w", name, ErrUnknownPreset)
+	}
+	return newConfig(), nil
+}
+
+// Names returns the sorted names of all registered presets.
+func Names() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	names := make([]string, 0, len(registry.m))
+	for name := range registry.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
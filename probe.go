@@ -0,0 +1,142 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Reductions a Probe applies to the values it reads.
+const (
+	ReduceMean = "mean" // The default
+	ReduceMin  = "min"
+	ReduceMax  = "max"
+	ReduceStd  = "std"  // Population standard deviation
+	ReduceNorm = "norm" // L2 norm
+	ReduceNone = "none" // One metric per element, labeled with its index
+)
+
+var reductions = map[string]bool{
+	ReduceMean: true, ReduceMin: true, ReduceMax: true,
+	ReduceStd: true, ReduceNorm: true, ReduceNone: true,
+}
+
+// Probe periodically reduces a model's layer buffer or a link's payload to
+// a metric sent to the runtime's sinks (see Runtime.AddSink), for targeted
+// numeric monitoring without writing recorder code:
+//
+//	"probes": [
+//	  {"name": "nav_hidden", "model": "navigator", "layer": 3, "every": 100, "reduce": "std"},
+//	  {"name": "terrain_link", "link": "terrain_to_nav", "reduce": "norm"}
+//	]
+type Probe struct {
+	Name   string `json:"name"`             // Metric name
+	Model  string `json:"model,omitempty"`  // Model whose layer buffer is read
+	Layer  int    `json:"layer,omitempty"`  // Layer buffer, indexed like a link's SourceLayer
+	Link   string `json:"link,omitempty"`   // Link whose payload is read, instead of a model layer
+	Every  uint64 `json:"every,omitempty"`  // Steps between readings; 0 means every step
+	Reduce string `json:"reduce,omitempty"` // One of the Reduce constants; default ReduceMean
+}
+
+// check describes what is wrong with the probe's own settings, or returns "".
+func (p *Probe) check() string {
+	switch {
+	case p.Model == "" && p.Link == "":
+		return "probe reads neither a model nor a link"
+	case p.Model != "" && p.Link != "":
+		return "probe reads both a model and a link"
+	case p.Layer < 0:
+		return fmt.Sprintf("negative layer %d", p.Layer)
+	case p.Reduce != "" && !reductions[p.Reduce]:
+		return fmt.Sprintf("unknown reduction %q", p.Reduce)
+	}
+	return ""
+}
+
+// labels returns the labels of the probe's metrics.
+func (p *Probe) labels() map[string]string {
+	reduce := p.Reduce
+	if reduce == "" {
+		reduce = ReduceMean
+	}
+	if p.Link != "" {
+		return map[string]string{"link": p.Link, "reduce": reduce}
+	}
+	return map[string]string{"model": p.Model, "layer": strconv.Itoa(p.Layer), "reduce": reduce}
+}
+
+// probe reads the probes due at the current step. The caller holds r.mu.
+func (r *Runtime) probe() []Metric {
+	var out []Metric
+	now := time.Now()
+	for i := range r.probes {
+		p := &r.probes[i]
+		if p.Every > 1 && r.steps%p.Every != 0 {
+			continue
+		}
+		var values []float32
+		if p.Link != "" {
+			if l := r.link(p.Link); l != nil {
+				values = l.payload
+			}
+		} else if m := r.models[p.Model]; m != nil {
+			values = m.state.GetLayerOutput(p.Layer)
+		}
+		if len(values) == 0 {
+			continue
+		}
+		if p.Reduce == ReduceNone {
+			for j, v := range values {
+				labels := p.labels()
+				labels["index"] = strconv.Itoa(j)
+				out = append(out, Metric{Name: p.Name, Value: float64(v), Step: r.steps, Labels: labels, Time: now})
+			}
+			continue
+		}
+		out = append(out, Metric{Name: p.Name, Value: reduce(p.Reduce, values), Step: r.steps, Labels: p.labels(), Time: now})
+	}
+	return out
+}
+
+// sendProbes records probe metrics to sinks. Errors are kept for Shutdown
+// to report, so a failing sink doesn't fail steps.
+func (r *Runtime) sendProbes(sinks []MetricSink, metrics []Metric) {
+	for _, s := range sinks {
+		for _, m := range metrics {
+			if err := s.Record(m); err != nil {
+				r.mu.Lock()
+				if r.probeErr == nil {
+					r.probeErr = fmt.Errorf("probe %q: %w", m.Name, err)
+				}
+				r.mu.Unlock()
+				break
+			}
+		}
+	}
+}
+
+// reduce reduces values to one number by the named reduction.
+func reduce(how string, values []float32) float64 {
+	var sum, sq float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		x := float64(v)
+		sum += x
+		sq += x * x
+		lo, hi = math.Min(lo, x), math.Max(hi, x)
+	}
+	n := float64(len(values))
+	switch how {
+	case ReduceMin:
+		return lo
+	case ReduceMax:
+		return hi
+	case ReduceStd:
+		mean := sum / n
+		return math.Sqrt(math.Max(sq/n-mean*mean, 0))
+	case ReduceNorm:
+		return math.Sqrt(sq)
+	}
+	return sum / n
+}
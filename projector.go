@@ -0,0 +1,300 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+)
+
+// Reducer reduces high-dimensional points to dims dimensions.
+type Reducer interface {
+	Reduce(points [][]float32, dims int) ([][]float64, error)
+}
+
+// PCA projects points onto their top principal components, found by power
+// iteration with deflation.
+type PCA struct {
+	Iterations int // Power iterations per component; defaults to 100
+}
+
+// Reduce implements Reducer.
+func (p PCA) Reduce(points [][]float32, dims int) ([][]float64, error) {
+	if len(points) == 0 {
+		return nil, nil
+	}
+	n := len(points[0])
+	mean := make([]float64, n)
+	for _, pt := range points {
+		if len(pt) != n {
+			return nil, fmt.Errorf("pca: points have mixed lengths %d and %d", n, len(pt))
+		}
+		for i, v := range pt {
+			mean[i] += float64(v)
+		}
+	}
+	for i := range mean {
+		mean[i] /= float64(len(points))
+	}
+	centered := make([][]float64, len(points))
+	for k, pt := range points {
+		centered[k] = make([]float64, n)
+		for i, v := range pt {
+			centered[k][i] = float64(v) - mean[i]
+		}
+	}
+
+	cov := make([][]float64, n)
+	for i := range cov {
+		cov[i] = make([]float64, n)
+	}
+	for _, c := range centered {
+		for i := range c {
+			for j := i; j < n; j++ {
+				cov[i][j] += c[i] * c[j]
+			}
+		}
+	}
+	for i := range cov {
+		for j := i; j < n; j++ {
+			cov[i][j] /= float64(len(points))
+			cov[j][i] = cov[i][j]
+		}
+	}
+
+	iters := p.Iterations
+	if iters <= 0 {
+		iters = 100
+	}
+	rng := seededStream(1, StreamTraining)
+	var comps [][]float64
+	for d := 0; d < dims && d < n; d++ {
+		v := make([]float64, n)
+		for i := range v {
+			v[i] = rng.Float64() - 0.5
+		}
+		var lambda float64
+		for it := 0; it < iters; it++ {
+			next := make([]float64, n)
+			for i := range cov {
+				for j, c := range cov[i] {
+					next[i] += c * v[j]
+				}
+			}
+			lambda = norm64(next)
+			if lambda == 0 {
+				break
+			}
+			for i := range next {
+				next[i] /= lambda
+			}
+			v = next
+		}
+		comps = append(comps, v)
+		for i := range cov {
+			for j := range cov[i] {
+				cov[i][j] -= lambda * v[i] * v[j]
+			}
+		}
+	}
+
+	out := make([][]float64, len(centered))
+	for k, c := range centered {
+		out[k] = make([]float64, dims)
+		for d, comp := range comps {
+			for i, v := range c {
+				out[k][d] += v * comp[i]
+			}
+		}
+	}
+	return out, nil
+}
+
+func norm64(v []float64) float64 {
+	var s float64
+	for _, x := range v {
+		s += x * x
+	}
+	return math.Sqrt(s)
+}
+
+// ExternalReducer runs an external program, such as a UMAP script, as the
+// reducer. The points are written to its stdin as CSV, one row per point, and
+// it must print one CSV row of dims coordinates per point to stdout. The
+// target dimension is passed in the DRIFT_DIMS environment variable.
+type ExternalReducer struct {
+	Command string
+	Args    []string
+}
+
+// Reduce implements Reducer.
+func (e ExternalReducer) Reduce(points [][]float32, dims int) ([][]float64, error) {
+	var in bytes.Buffer
+	cw := csv.NewWriter(&in)
+	for _, pt := range points {
+		row := make([]string, len(pt))
+		for i, v := range pt {
+			row[i] = strconv.FormatFloat(float64(v), 'g', -1, 32)
+		}
+		cw.Write(row)
+	}
+	cw.Flush()
+
+	cmd := exec.Command(e.Command, e.Args...)
+	cmd.Stdin = &in
+	cmd.Env = append(cmd.Environ(), "DRIFT_DIMS="+strconv.Itoa(dims))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("reducer %s: %w", e.Command, err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(out)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reducer %s: %w", e.Command, err)
+	}
+	if len(rows) != len(points) {
+		return nil, fmt.Errorf("reducer %s: got %d rows for %d points", e.Command, len(rows), len(points))
+	}
+	coords := make([][]float64, len(rows))
+	for k, row := range rows {
+		if len(row) < dims {
+			return nil, fmt.Errorf("reducer %s: row %d has %d columns, want %d", e.Command, k, len(row), dims)
+		}
+		coords[k] = make([]float64, dims)
+		for d := range coords[k] {
+			if coords[k][d], err = strconv.ParseFloat(row[d], 64); err != nil {
+				return nil, fmt.Errorf("reducer %s: row %d: %w", e.Command, k, err)
+			}
+		}
+	}
+	return coords, nil
+}
+
+// ProjectionOptions configures ProjectLink.
+type ProjectionOptions struct {
+	Dims    int                     // 2 or 3; defaults to 2
+	Label   func(StepRecord) string // Colors points by environment state, e.g. terrain
+	Reducer Reducer                 // Defaults to PCA
+}
+
+// ProjectedPoint is one link payload after reduction.
+type ProjectedPoint struct {
+	Step   uint64    `json:"step"`
+	Label  string    `json:"label,omitempty"`
+	Coords []float64 `json:"coords"`
+}
+
+// Projection holds the reduced payloads of one link.
+type Projection struct {
+	Link   string           `json:"link"`
+	Dims   int              `json:"dims"`
+	Points []ProjectedPoint `json:"points"`
+}
+
+// ProjectLink reduces the payloads of link recorded in steps to 2D or 3D.
+// Steps where the link carried no payload are skipped.
+func ProjectLink(steps []StepRecord, link string, opts ProjectionOptions) (*Projection, error) {
+	dims := opts.Dims
+	if dims == 0 {
+		dims = 2
+	}
+	if dims != 2 && dims != 3 {
+		return nil, fmt.Errorf("projection: dims must be 2 or 3, got %d", dims)
+	}
+	reducer := opts.Reducer
+	if reducer == nil {
+		reducer = PCA{}
+	}
+
+	var points [][]float32
+	var used []StepRecord
+	for _, s := range steps {
+		if p := s.Links[link]; len(p) > 0 {
+			points = append(points, p)
+			used = append(used, s)
+		}
+	}
+	if len(points) == 0 {
+		return nil, fmt.Errorf("link %q not found in recording", link)
+	}
+	coords, err := reducer.Reduce(points, dims)
+	if err != nil {
+		return nil, err
+	}
+
+	proj := &Projection{Link: link, Dims: dims, Points: make([]ProjectedPoint, len(used))}
+	for i, s := range used {
+		proj.Points[i] = ProjectedPoint{Step: s.Step, Coords: coords[i]}
+		if opts.Label != nil {
+			proj.Points[i].Label = opts.Label(s)
+		}
+	}
+	return proj, nil
+}
+
+// WriteProjectorHTML writes a self-contained interactive scatter plot of p,
+// colored by label. 3D projections can be rotated by dragging.
+func WriteProjectorHTML(w io.Writer, title string, p *Projection) error {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return projectorTemplate.Execute(w, struct {
+		Title string
+		Data  template.JS
+	}{title, template.JS(data)})
+}
+
+var projectorTemplate = template.Must(template.New("projector").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:sans-serif;margin:1em}
+canvas{border:1px solid #ccc;cursor:grab}
+#legend span{display:inline-block;margin-right:1em}
+#legend i{display:inline-block;width:10px;height:10px;margin-right:4px}
+</style></head>
+<body><h1>{{.Title}}</h1><div id="legend"></div><canvas id="c" width="800" height="800"></canvas><div id="info"></div>
+<script>
+const proj = {{.Data}};
+const c = document.getElementById("c"), ctx = c.getContext("2d");
+const labels = [...new Set(proj.points.map(p => p.label || ""))];
+const colorOf = l => "hsl(" + Math.round(360 * labels.indexOf(l) / labels.length) + ",70%,45%)";
+document.getElementById("legend").innerHTML = labels.map(l => "<span><i style='background:" + colorOf(l) + "'></i>" + (l || "(none)") + "</span>").join("");
+const dim = proj.dims, ext = [];
+for (let d = 0; d < dim; d++) {
+  const vs = proj.points.map(p => p.coords[d]);
+  const lo = Math.min(...vs), hi = Math.max(...vs);
+  ext.push([(lo + hi) / 2, (hi - lo) / 2 || 1]);
+}
+let yaw = 0.6, pitch = 0.4, drag = null, screen = [];
+function draw() {
+  ctx.clearRect(0, 0, c.width, c.height);
+  screen = proj.points.map(p => {
+    const v = p.coords.map((x, d) => (x - ext[d][0]) / ext[d][1]);
+    let x = v[0], y = v[1], z = dim == 3 ? v[2] : 0;
+    if (dim == 3) {
+      [x, z] = [x * Math.cos(yaw) - z * Math.sin(yaw), x * Math.sin(yaw) + z * Math.cos(yaw)];
+      [y, z] = [y * Math.cos(pitch) - z * Math.sin(pitch), y * Math.sin(pitch) + z * Math.cos(pitch)];
+    }
+    return {p, x: c.width / 2 + x * c.width * 0.42, y: c.height / 2 - y * c.height * 0.42, z};
+  });
+  screen.slice().sort((a, b) => a.z - b.z).forEach(s => {
+    ctx.fillStyle = colorOf(s.p.label || ""); ctx.globalAlpha = 0.75;
+    ctx.beginPath(); ctx.arc(s.x, s.y, 3, 0, 2 * Math.PI); ctx.fill();
+  });
+}
+c.onmousedown = e => { if (dim == 3) drag = [e.clientX, e.clientY]; };
+window.onmouseup = () => { drag = null; };
+c.onmousemove = e => {
+  if (drag) { yaw += (e.clientX - drag[0]) / 200; pitch += (e.clientY - drag[1]) / 200; drag = [e.clientX, e.clientY]; draw(); return; }
+  const b = c.getBoundingClientRect(), mx = e.clientX - b.left, my = e.clientY - b.top;
+  const hit = screen.find(s => Math.hypot(s.x - mx, s.y - my) < 5);
+  document.getElementById("info").textContent = hit ? "step " + hit.p.step + (hit.p.label ? " (" + hit.p.label + ")" : "") : "";
+};
+draw();
+</script></body></html>
+`))
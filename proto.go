@@ -0,0 +1,397 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/openfluke/drift/driftpb"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Protobuf configs follow the schema in driftpb/drift.proto, so that tools
+// in other languages can generate types from it and produce configs DRIFT
+// loads. Encoding is deterministic; see the schema for the rules.
+
+// ToProto converts the config to its protobuf message. Model definitions
+// and intervention params are stored as compact JSON.
+func (c *Config) ToProto() (*driftpb.Config, error) {
+	m := &driftpb.Config{
+		SchemaVersion: int64(c.SchemaVersion),
+		Name:          c.Name,
+		Seed:          c.Seed,
+		Dtype:         string(c.DType),
+	}
+	if len(c.Models) > 0 {
+		m.Models = make(map[string][]byte, len(c.Models))
+		for name, raw := range c.Models {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, raw); err != nil {
+				return nil, fmt.Errorf("model %q: %w", name, err)
+			}
+			m.Models[name] = buf.Bytes()
+		}
+	}
+	if len(c.Inputs) > 0 {
+		m.Inputs = make(map[string]*driftpb.InputSegments, len(c.Inputs))
+		for model, segs := range c.Inputs {
+			ps := &driftpb.InputSegments{}
+			for _, s := range segs {
+				ps.Segments = append(ps.Segments, &driftpb.InputSegment{
+					Name:      s.Name,
+					Offset:    int64(s.Offset),
+					Size:      int64(s.Size),
+					Fill:      string(s.Fill),
+					FillValue: s.FillValue,
+					Embedding: s.Embedding,
+				})
+			}
+			m.Inputs[model] = ps
+		}
+	}
+	if len(c.Resources) > 0 {
+		m.Resources = make(map[string]*driftpb.ResourceHints, len(c.Resources))
+		for model, h := range c.Resources {
+			m.Resources[model] = &driftpb.ResourceHints{
+				MaxLatencyMs: h.MaxLatencyMs,
+				MemoryClass:  h.MemoryClass,
+				RequiresFp32: h.RequiresFP32,
+				DeviceClass:  h.DeviceClass,
+			}
+		}
+	}
+	if len(c.Metadata) > 0 {
+		m.Metadata = make(map[string]*driftpb.ModelMetadata, len(c.Metadata))
+		for model, md := range c.Metadata {
+			m.Metadata[model] = &driftpb.ModelMetadata{
+				Tags:        md.Tags,
+				Version:     md.Version,
+				Author:      md.Author,
+				Description: md.Description,
+				Created:     timestampProto(md.Created),
+				Updated:     timestampProto(md.Updated),
+			}
+		}
+	}
+	for _, l := range c.Links {
+		m.Links = append(m.Links, l.toProto())
+	}
+	for _, iv := range c.Scenario {
+		var params []byte
+		if len(iv.Params) > 0 {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, iv.Params); err != nil {
+				return nil, fmt.Errorf("intervention %q at step %d: %w", iv.Action, iv.AtStep, err)
+			}
+			params = buf.Bytes()
+		}
+		m.Scenario = append(m.Scenario, &driftpb.Intervention{
+			AtStep: iv.AtStep,
+			Action: iv.Action,
+			Target: iv.Target,
+			Value:  iv.Value,
+			Params: params,
+		})
+	}
+	for _, p := range c.Training {
+		m.Training = append(m.Training, &driftpb.TrainingPhase{
+			Name:         p.Name,
+			Train:        p.Train,
+			Steps:        p.Steps,
+			Duration:     p.Duration,
+			Lr:           p.LR,
+			CoTrain:      p.CoTrain,
+			SourceCredit: p.SourceCredit,
+			TargetCredit: p.TargetCredit,
+		})
+	}
+	for _, p := range c.Probes {
+		m.Probes = append(m.Probes, &driftpb.Probe{
+			Name:   p.Name,
+			Model:  p.Model,
+			Layer:  int64(p.Layer),
+			Link:   p.Link,
+			Every:  p.Every,
+			Reduce: p.Reduce,
+		})
+	}
+	return m, nil
+}
+
+func (l NeuralLinkConfig) toProto() *driftpb.NeuralLinkConfig {
+	pl := &driftpb.NeuralLinkConfig{
+		Name:          l.Name,
+		Id:            l.ID,
+		SourceModel:   l.SourceModel,
+		SourceLayer:   int64(l.SourceLayer),
+		TargetModel:   l.TargetModel,
+		TargetOffset:  int64(l.TargetOffset),
+		LinkSize:      int64(l.LinkSize),
+		Enabled:       l.Enabled,
+		Description:   l.Description,
+		Group:         l.Group,
+		Tags:          l.Tags,
+		Delivery:      l.Delivery,
+		LearnDelivery: l.LearnDelivery,
+		Checksum:      l.Checksum,
+		Gain:          l.Gain,
+	}
+	for _, w := range l.Active {
+		pl.Active = append(pl.Active, &driftpb.ActiveWindow{From: w.From, Until: w.Until, Every: w.Every, For: w.For})
+	}
+	for _, t := range l.Transforms {
+		pl.Transforms = append(pl.Transforms, &driftpb.TransformSpec{Type: t.Type, Min: t.Min, Max: t.Max, Factor: t.Factor, Offset: t.Offset})
+	}
+	if h := l.Homeostasis; h != nil {
+		pl.Homeostasis = &driftpb.Homeostasis{
+			MinStd:  h.MinStd,
+			MaxStd:  h.MaxStd,
+			MaxMean: h.MaxMean,
+			Rate:    h.Rate,
+			MinGain: h.MinGain,
+			MaxGain: h.MaxGain,
+		}
+	}
+	if ra := l.Range; ra != nil {
+		pl.Range = &driftpb.RangeAdapter{
+			Transfer: ra.Transfer,
+			Target:   &driftpb.Range{Min: ra.Target.Min, Max: ra.Target.Max},
+		}
+		if ra.Source != nil {
+			pl.Range.Source = &driftpb.Range{Min: ra.Source.Min, Max: ra.Source.Max}
+		}
+	}
+	return pl
+}
+
+// FromProto converts a protobuf message to a config. Configs with an older
+// schema version are migrated through the JSON form, as when loading JSON.
+func FromProto(m *driftpb.Config) (*Config, error) {
+	c := Config{
+		SchemaVersion: int(m.GetSchemaVersion()),
+		Name:          m.GetName(),
+		Seed:          m.GetSeed(),
+		DType:         DType(m.GetDtype()),
+		Models:        make(map[string]json.RawMessage, len(m.GetModels())),
+	}
+	for name, raw := range m.GetModels() {
+		if !json.Valid(raw) {
+			return nil, fmt.Errorf("model %q is not valid JSON", name)
+		}
+		c.Models[name] = raw
+	}
+	if len(m.GetInputs()) > 0 {
+		c.Inputs = make(map[string][]InputSegment, len(m.GetInputs()))
+		for model, ps := range m.GetInputs() {
+			segs := []InputSegment{}
+			for _, s := range ps.GetSegments() {
+				segs = append(segs, InputSegment{
+					Name:      s.GetName(),
+					Offset:    int(s.GetOffset()),
+					Size:      int(s.GetSize()),
+					Fill:      FillMode(s.GetFill()),
+					FillValue: s.GetFillValue(),
+					Embedding: s.GetEmbedding(),
+				})
+			}
+			c.Inputs[model] = segs
+		}
+	}
+	if len(m.GetResources()) > 0 {
+		c.Resources = make(map[string]ResourceHints, len(m.GetResources()))
+		for model, h := range m.GetResources() {
+			c.Resources[model] = ResourceHints{
+				MaxLatencyMs: h.GetMaxLatencyMs(),
+				MemoryClass:  h.GetMemoryClass(),
+				RequiresFP32: h.GetRequiresFp32(),
+				DeviceClass:  h.GetDeviceClass(),
+			}
+		}
+	}
+	if len(m.GetMetadata()) > 0 {
+		c.Metadata = make(map[string]ModelMetadata, len(m.GetMetadata()))
+		for model, md := range m.GetMetadata() {
+			c.Metadata[model] = ModelMetadata{
+				Tags:        md.GetTags(),
+				Version:     md.GetVersion(),
+				Author:      md.GetAuthor(),
+				Description: md.GetDescription(),
+				Created:     timestampFromProto(md.GetCreated()),
+				Updated:     timestampFromProto(md.GetUpdated()),
+			}
+		}
+	}
+	for _, pl := range m.GetLinks() {
+		c.Links = append(c.Links, linkFromProto(pl))
+	}
+	for _, iv := range m.GetScenario() {
+		params := iv.GetParams()
+		if len(params) > 0 && !json.Valid(params) {
+			return nil, errors.New("intervention params are not valid JSON")
+		}
+		c.Scenario = append(c.Scenario, Intervention{
+			AtStep: iv.GetAtStep(),
+			Action: iv.GetAction(),
+			Target: iv.GetTarget(),
+			Value:  iv.GetValue(),
+			Params: params,
+		})
+	}
+	for _, p := range m.GetTraining() {
+		c.Training = append(c.Training, TrainingPhase{
+			Name:         p.GetName(),
+			Train:        p.GetTrain(),
+			Steps:        p.GetSteps(),
+			Duration:     p.GetDuration(),
+			LR:           p.GetLr(),
+			CoTrain:      p.GetCoTrain(),
+			SourceCredit: p.GetSourceCredit(),
+			TargetCredit: p.GetTargetCredit(),
+		})
+	}
+	for _, p := range m.GetProbes() {
+		c.Probes = append(c.Probes, Probe{
+			Name:   p.GetName(),
+			Model:  p.GetModel(),
+			Layer:  int(p.GetLayer()),
+			Link:   p.GetLink(),
+			Every:  p.GetEvery(),
+			Reduce: p.GetReduce(),
+		})
+	}
+
+	if c.SchemaVersion < CurrentSchemaVersion {
+		data, err := json.Marshal(&c)
+		if err != nil {
+			return nil, err
+		}
+		return decodeConfig(data, nil)
+	}
+	if c.SchemaVersion > CurrentSchemaVersion {
+		return nil, fmt.Errorf("protobuf config has schema version %d, newer than %d", c.SchemaVersion, CurrentSchemaVersion)
+	}
+	return &c, nil
+}
+
+func linkFromProto(pl *driftpb.NeuralLinkConfig) NeuralLinkConfig {
+	l := NeuralLinkConfig{
+		Name:          pl.GetName(),
+		ID:            pl.GetId(),
+		SourceModel:   pl.GetSourceModel(),
+		SourceLayer:   int(pl.GetSourceLayer()),
+		TargetModel:   pl.GetTargetModel(),
+		TargetOffset:  int(pl.GetTargetOffset()),
+		LinkSize:      int(pl.GetLinkSize()),
+		Enabled:       pl.GetEnabled(),
+		Description:   pl.GetDescription(),
+		Group:         pl.GetGroup(),
+		Tags:          pl.GetTags(),
+		Delivery:      pl.GetDelivery(),
+		LearnDelivery: pl.GetLearnDelivery(),
+		Checksum:      pl.GetChecksum(),
+	}
+	if pl.Gain != nil {
+		g := pl.GetGain()
+		l.Gain = &g
+	}
+	for _, w := range pl.GetActive() {
+		l.Active = append(l.Active, ActiveWindow{From: w.GetFrom(), Until: w.GetUntil(), Every: w.GetEvery(), For: w.GetFor()})
+	}
+	for _, t := range pl.GetTransforms() {
+		l.Transforms = append(l.Transforms, TransformSpec{Type: t.GetType(), Min: t.GetMin(), Max: t.GetMax(), Factor: t.GetFactor(), Offset: t.GetOffset()})
+	}
+	if h := pl.GetHomeostasis(); h != nil {
+		l.Homeostasis = &Homeostasis{
+			MinStd:  h.GetMinStd(),
+			MaxStd:  h.GetMaxStd(),
+			MaxMean: h.GetMaxMean(),
+			Rate:    h.GetRate(),
+			MinGain: h.GetMinGain(),
+			MaxGain: h.GetMaxGain(),
+		}
+	}
+	if ra := pl.GetRange(); ra != nil {
+		l.Range = &RangeAdapter{
+			Transfer: ra.GetTransfer(),
+			Target:   Range{Min: ra.GetTarget().GetMin(), Max: ra.GetTarget().GetMax()},
+		}
+		if src := ra.GetSource(); src != nil {
+			l.Range.Source = &Range{Min: src.GetMin(), Max: src.GetMax()}
+		}
+	}
+	return l
+}
+
+// MarshalProto serializes the config to its protobuf wire form,
+// deterministically, so equal configs encode to equal bytes.
+func (c *Config) MarshalProto() ([]byte, error) {
+	m, err := c.ToProto()
+	if err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(m)
+}
+
+// UnmarshalProto deserializes a config from its protobuf wire form.
+func UnmarshalProto(data []byte) (*Config, error) {
+	var m driftpb.Config
+	if err := proto.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("protobuf config: %w", err)
+	}
+	return FromProto(&m)
+}
+
+// SaveToProto saves the config to a protobuf file, atomically and under the
+// same advisory lock as SaveToFile.
+func (c *Config) SaveToProto(path string) error {
+	data, err := c.MarshalProto()
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, data, 0644, false)
+	})
+}
+
+// LoadFromProto loads a config from a protobuf file, holding a shared
+// advisory lock while reading. Like LoadFromFile, it applies DRIFT_
+// environment overrides.
+func LoadFromProto(path string) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := UnmarshalProto(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if _, err := c.ApplyEnvOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// timestampProto converts t, leaving the zero time unset.
+func timestampProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+// timestampFromProto converts ts to UTC, an unset one to the zero time.
+func timestampFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
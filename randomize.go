@@ -0,0 +1,146 @@
+package drift
+
+import "fmt"
+
+// DomainRandomization declares what an environment resamples at the start of
+// every episode, so that policies and link protocols trained in simulation
+// don't overfit its exact physics and transfer better to hardware. It is
+// data in an experiment phase; environments apply it through a Domain.
+type DomainRandomization struct {
+	Seed int64 `json:"seed,omitempty"`
+	// Params are environment parameters, e.g. the friction of a terrain,
+	// drawn uniformly from their bounds every episode.
+	Params []ParamBound `json:"params,omitempty"`
+	// SensorGain, per model, is the range of a gain drawn for every input
+	// channel every episode, modeling miscalibrated sensors.
+	SensorGain map[string][2]float64 `json:"sensor_gain,omitempty"`
+	// SensorNoise, per model, is the standard deviation of Gaussian noise
+	// added to every input channel every step.
+	SensorNoise map[string]float64 `json:"sensor_noise,omitempty"`
+	// ActionNoise is the standard deviation of Gaussian noise added to every
+	// actuation command every step.
+	ActionNoise float64 `json:"action_noise,omitempty"`
+}
+
+// check describes what is wrong with the randomization, or returns "".
+func (d *DomainRandomization) check() string {
+	for _, p := range d.Params {
+		if p.Max < p.Min {
+			return fmt.Sprintf("param %q: max %g below min %g", p.Name, p.Max, p.Min)
+		}
+	}
+	for _, model := range sortedKeys(d.SensorGain) {
+		if g := d.SensorGain[model]; g[1] < g[0] {
+			return fmt.Sprintf("sensor gain of %q: max %g below min %g", model, g[1], g[0])
+		}
+	}
+	for _, model := range sortedKeys(d.SensorNoise) {
+		if d.SensorNoise[model] < 0 {
+			return fmt.Sprintf("sensor noise of %q is negative", model)
+		}
+	}
+	if d.ActionNoise < 0 {
+		return "action noise is negative"
+	}
+	return ""
+}
+
+// Domain applies a phase's DomainRandomization inside an environment:
+//
+//	d, err := phase.Domain()
+//	...
+//	d.Episode() // at the start of every episode
+//	friction := d.Param("friction", 0.8)
+//	sensors = d.Sense("classifier", sensors)
+//	cmd = d.Act(cmd)
+//
+// Sampling is seeded, so every benchmark mode of a phase meets the same
+// sequence of episodes. Without randomization a Domain returns the phase's
+// fixed Params and leaves inputs and actions alone.
+type Domain struct {
+	spec    DomainRandomization
+	fixed   map[string]float64
+	rng     Rand
+	params  map[string]float64
+	gains   map[string][]float32
+	episode int
+}
+
+// Domain returns the phase's randomization domain. Call Episode to draw the
+// first episode.
+func (p ExperimentPhase) Domain() (*Domain, error) {
+	d := &Domain{fixed: p.Params, gains: make(map[string][]float32), episode: -1}
+	if p.Randomize != nil {
+		if msg := p.Randomize.check(); msg != "" {
+			return nil, fmt.Errorf("randomize: %s", msg)
+		}
+		d.spec = *p.Randomize
+	}
+	d.rng = seededStream(d.spec.Seed, StreamEnvironment)
+	return d, nil
+}
+
+// Episode resamples the parameters and sensor gains for a new episode and
+// returns its number, counting from 0.
+func (d *Domain) Episode() int {
+	d.episode++
+	d.params = make(map[string]float64, len(d.spec.Params))
+	for _, p := range d.spec.Params {
+		d.params[p.Name] = p.Min + d.rng.Float64()*(p.Max-p.Min)
+	}
+	d.gains = make(map[string][]float32, len(d.spec.SensorGain))
+	return d.episode
+}
+
+// Params returns the parameters drawn for the current episode.
+func (d *Domain) Params() map[string]float64 {
+	out := make(map[string]float64, len(d.params))
+	for k, v := range d.params {
+		out[k] = v
+	}
+	return out
+}
+
+// Param returns the value of an environment parameter for the current
+// episode: the randomized one, else the phase's fixed one, else def.
+func (d *Domain) Param(name string, def float64) float64 {
+	if v, ok := d.params[name]; ok {
+		return v
+	}
+	if v, ok := d.fixed[name]; ok {
+		return v
+	}
+	return def
+}
+
+// Sense applies the episode's sensor gains and the step's sensor noise for
+// model to input, in place, and returns it.
+func (d *Domain) Sense(model string, input []float32) []float32 {
+	if g, ok := d.spec.SensorGain[model]; ok {
+		gains := d.gains[model]
+		for len(gains) < len(input) {
+			gains = append(gains, float32(g[0]+d.rng.Float64()*(g[1]-g[0])))
+		}
+		d.gains[model] = gains
+		for i := range input {
+			input[i] *= gains[i]
+		}
+	}
+	addNoise(d.rng, input, d.spec.SensorNoise[model])
+	return input
+}
+
+// Act applies the step's actuation noise to action, in place, and returns it.
+func (d *Domain) Act(action []float32) []float32 {
+	addNoise(d.rng, action, d.spec.ActionNoise)
+	return action
+}
+
+func addNoise(rng Rand, s []float32, stddev float64) {
+	if stddev <= 0 {
+		return
+	}
+	for i := range s {
+		s[i] += float32(rng.NormFloat64() * stddev)
+	}
+}
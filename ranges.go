@@ -0,0 +1,162 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Range is a closed interval of activation values.
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// RangeAdapter maps a link's payload from the range its source produces to
+// the range its target expects, so that, say, an unbounded ReLU source
+// doesn't blow out an input region trained on [0, 1].
+//
+// The source range is the declared Source, else the range of the source
+// layer's activation when it is bounded (sigmoid, tanh, softmax), else one
+// measured while running: the mean plus or minus two standard deviations of
+// recent payloads. Values are normalized against it and passed through the
+// Transfer function onto Target.
+type RangeAdapter struct {
+	Transfer string `json:"transfer,omitempty"` // Registered transfer function; default TransferLinear
+	Source   *Range `json:"source,omitempty"`   // Declared source range
+	Target   Range  `json:"target"`
+}
+
+// Built-in transfer functions.
+const (
+	TransferLinear  = "linear"  // Linear, clipped to the target range
+	TransferSigmoid = "sigmoid" // Smooth squashing that never quite reaches the bounds
+	TransferLog     = "log"     // Logarithmic compression of large values, for ReLU-like sources
+)
+
+// TransferFunc maps a value normalized against the source range, nominally
+// in [0, 1] but unbounded, to [0, 1].
+type TransferFunc func(z float64) float64
+
+var transferFuncs = struct {
+	sync.RWMutex
+	m map[string]TransferFunc
+}{m: map[string]TransferFunc{
+	TransferLinear:  func(z float64) float64 { return math.Max(0, math.Min(1, z)) },
+	TransferSigmoid: func(z float64) float64 { return 1 / (1 + math.Exp(-6*(z-0.5))) },
+	TransferLog: func(z float64) float64 {
+		return math.Min(1, math.Log1p(math.Max(0, z)*(math.E-1)))
+	},
+}}
+
+// RegisterTransferFunc registers a transfer function for RangeAdapter,
+// replacing any earlier one of the same name.
+func RegisterTransferFunc(name string, fn TransferFunc) {
+	transferFuncs.Lock()
+	defer transferFuncs.Unlock()
+	transferFuncs.m[name] = fn
+}
+
+func transferFunc(name string) (TransferFunc, bool) {
+	if name == "" {
+		name = TransferLinear
+	}
+	transferFuncs.RLock()
+	defer transferFuncs.RUnlock()
+	fn, ok := transferFuncs.m[name]
+	return fn, ok
+}
+
+// check describes what is wrong with the adapter, or returns "".
+func (a *RangeAdapter) check() string {
+	if _, ok := transferFunc(a.Transfer); !ok {
+		return fmt.Sprintf("unknown transfer function %q", a.Transfer)
+	}
+	if a.Target.Max <= a.Target.Min {
+		return "empty target range"
+	}
+	if a.Source != nil && a.Source.Max <= a.Source.Min {
+		return "empty source range"
+	}
+	return ""
+}
+
+// activationRanges are the output ranges of bounded loom activations.
+var activationRanges = map[string]Range{
+	"sigmoid": {0, 1},
+	"tanh":    {-1, 1},
+}
+
+// sourceRange returns the bounded output range of a model's layer buffer,
+// indexed like a link's SourceLayer, if it has one.
+func sourceRange(raw []byte, layer int) (Range, bool) {
+	spec, err := parseModel(raw)
+	if err != nil || layer < 1 || layer > len(spec.Layers) {
+		return Range{}, false
+	}
+	def := spec.Layers[layer-1]
+	if def.Type == "softmax" {
+		return Range{0, 1}, true
+	}
+	r, ok := activationRanges[def.Activation]
+	return r, ok && def.Type != "parallel"
+}
+
+// rangeAdapter is the live form of a RangeAdapter.
+type rangeAdapter struct {
+	cfg      RangeAdapter
+	fn       TransferFunc
+	source   *Range        // Fixed source range, or nil to measure
+	measured *payloadStats // Running statistics when measuring
+}
+
+func newRangeAdapter(a RangeAdapter, raw []byte, layer int) *rangeAdapter {
+	ra := &rangeAdapter{cfg: a, source: a.Source}
+	ra.fn, _ = transferFunc(a.Transfer)
+	if ra.source == nil {
+		if r, ok := sourceRange(raw, layer); ok {
+			ra.source = &r
+		}
+	}
+	return ra
+}
+
+// apply maps values in place.
+func (ra *rangeAdapter) apply(values []float32) {
+	if len(values) == 0 {
+		return
+	}
+	src := ra.source
+	if src == nil {
+		src = ra.measure(values)
+	}
+	width := src.Max - src.Min
+	if width <= 0 {
+		width = 1
+	}
+	t := ra.cfg.Target
+	for i, v := range values {
+		z := (float64(v) - src.Min) / width
+		values[i] = float32(t.Min + (t.Max-t.Min)*ra.fn(z))
+	}
+}
+
+// measure updates the running statistics with values and returns the
+// source range they imply.
+func (ra *rangeAdapter) measure(values []float32) *Range {
+	var sum, sq float64
+	for _, v := range values {
+		sum += float64(v)
+		sq += float64(v) * float64(v)
+	}
+	n := float64(len(values))
+	if ra.measured == nil {
+		ra.measured = &payloadStats{mean: sum / n, sq: sq / n}
+	} else {
+		ra.measured.mean += 0.01 * (sum/n - ra.measured.mean)
+		ra.measured.sq += 0.01 * (sq/n - ra.measured.sq)
+	}
+	m := ra.measured.mean
+	std := math.Sqrt(math.Max(0, ra.measured.sq-m*m))
+	return &Range{Min: m - 2*std, Max: m + 2*std}
+}
@@ -0,0 +1,100 @@
+package drift
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// RecalibrationMode selects what a Recalibrator does when triggered.
+type RecalibrationMode int
+
+const (
+	// RecalibrateRefit rebuilds the drift detector's reference statistics
+	// from the next RefitSamples live inputs (500 when unset).
+	RecalibrateRefit RecalibrationMode = iota
+	// RecalibrateFineTune runs the FineTune routine, typically a short
+	// supervised session against an oracle.
+	RecalibrateFineTune
+	// RecalibrateReview hands the event to the Review handler for a human to look at.
+	RecalibrateReview
+)
+
+// Recalibrator responds to input drift. Register its Handle method on a
+// GuardPolicy for GuardRecalibrate so the policy decides when it runs:
+//
+//	policy.SetRule(drift.EventInputDrift, drift.GuardRecalibrate)
+//	policy.On(drift.GuardRecalibrate, recal.Handle)
+type Recalibrator struct {
+	Mode         RecalibrationMode
+	Detector     *InputDriftDetector
+	RefitSamples int
+	FineTune     func(Event) error
+	Review       EventHandler
+	// Cooldown is the minimum time between two recalibrations.
+	Cooldown time.Duration
+	// OnError receives failures from the FineTune routine.
+	OnError func(error)
+
+	mu   sync.Mutex
+	last time.Time
+	runs int
+}
+
+// Handle runs the configured routine for e unless the cooldown is active.
+func (r *Recalibrator) Handle(e Event) {
+	r.mu.Lock()
+	if !r.last.IsZero() && time.Since(r.last) < r.Cooldown {
+		r.mu.Unlock()
+		return
+	}
+	r.last = time.Now()
+	r.runs++
+	r.mu.Unlock()
+
+	switch r.Mode {
+	case RecalibrateRefit:
+		if r.Detector == nil {
+			return
+		}
+		samples := r.RefitSamples
+		if samples <= 0 {
+			samples = 500
+		}
+		r.Detector.Refit(samples)
+	case RecalibrateFineTune:
+		if r.FineTune == nil {
+			return
+		}
+		if err := r.FineTune(e); err != nil && r.OnError != nil {
+			r.OnError(fmt.Errorf("recalibration after %s: %w", e.Kind, err))
+		}
+	case RecalibrateReview:
+		if r.Review != nil {
+			r.Review(e)
+		}
+	}
+}
+
+// Runs returns how many times the recalibration routine has been triggered.
+func (r *Recalibrator) Runs() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.runs
+}
+
+// OracleFineTune returns a FineTune routine that trains net for steps samples,
+// drawing observations from sample and labels from oracle.
+func OracleFineTune(net *nn.Network, oracle Policy, sample func() []float32, steps, numActions int, lr float32) func(Event) error {
+	return func(Event) error {
+		tween := nn.NewTweenState(net, nil)
+		tween.Config.UseChainRule = true
+		for i := 0; i < steps; i++ {
+			obs := sample()
+			tween.TweenStep(net, obs, oracle.Act(obs), numActions, lr)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,125 @@
+package drift
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// StepRecord captures what flowed through a Runtime during one step.
+type StepRecord struct {
+	Step    uint64                 `json:"step"`
+	Inputs  map[string][]float32   `json:"inputs"`
+	Outputs map[string][]float32   `json:"outputs,omitempty"`
+	Links   map[string][]float32   `json:"links,omitempty"`
+	Layers  map[string][][]float32 `json:"layers,omitempty"` // Per-model layer outputs, when the recorder captures them
+}
+
+// Recorder collects StepRecords from a Runtime. Records are kept in memory,
+// up to Limit most recent ones when Limit is positive, and are also streamed
+// to Log when one is set. With Layers set, every layer output of every model
+// is recorded too, which is much larger but feeds per-layer heatmaps.
+type Recorder struct {
+	Limit  int
+	Log    *ResultLog
+	Layers bool
+
+	mu    sync.Mutex
+	steps []StepRecord
+	err   error
+}
+
+// NewRecorder creates a recorder that streams to log (which may be nil) and
+// keeps at most limit records in memory (0 for no limit).
+func NewRecorder(log *ResultLog, limit int) *Recorder {
+	return &Recorder{Log: log, Limit: limit}
+}
+
+// Add stores a record.
+func (rec *Recorder) Add(s StepRecord) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.steps = append(rec.steps, s)
+	if rec.Limit > 0 && len(rec.steps) > rec.Limit {
+		rec.steps = append(rec.steps[:0], rec.steps[len(rec.steps)-rec.Limit:]...)
+	}
+	if rec.Log != nil && rec.err == nil {
+		rec.err = rec.Log.Append(s)
+	}
+}
+
+// Steps returns the records held in memory.
+func (rec *Recorder) Steps() []StepRecord {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return append([]StepRecord(nil), rec.steps...)
+}
+
+// Flush syncs the log, reporting the first error encountered while streaming.
+func (rec *Recorder) Flush() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.err != nil {
+		return rec.err
+	}
+	if rec.Log != nil {
+		return rec.Log.Flush()
+	}
+	return nil
+}
+
+// LoadRecording reads StepRecords from a JSON Lines file written by a Recorder.
+func LoadRecording(path string) ([]StepRecord, error) {
+	var steps []StepRecord
+	err := ReadResultLog(path, func(line json.RawMessage) error {
+		var s StepRecord
+		if err := json.Unmarshal(line, &s); err != nil {
+			return err
+		}
+		steps = append(steps, s)
+		return nil
+	})
+	return steps, err
+}
+
+// SetRecorder attaches rec to the runtime; every subsequent step is recorded.
+// The recorder is flushed on Shutdown. Passing nil detaches the current recorder.
+func (r *Runtime) SetRecorder(rec *Recorder) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recorder = rec
+}
+
+// record builds the StepRecord for the step just completed. The caller holds r.mu.
+func (r *Runtime) record(inputs map[string][]float32) {
+	if r.recorder == nil {
+		return
+	}
+	s := StepRecord{
+		Step:    r.steps,
+		Inputs:  make(map[string][]float32, len(inputs)),
+		Outputs: make(map[string][]float32, len(r.models)),
+		Links:   make(map[string][]float32, len(r.links)),
+	}
+	for name, in := range inputs {
+		s.Inputs[name] = append([]float32(nil), in...)
+	}
+	for name, m := range r.models {
+		s.Outputs[name] = append([]float32(nil), m.output...)
+	}
+	for _, l := range r.links {
+		if l.payload != nil {
+			s.Links[l.cfg.Name] = append([]float32(nil), l.payload...)
+		}
+	}
+	if r.recorder.Layers {
+		s.Layers = make(map[string][][]float32, len(r.models))
+		for name, m := range r.models {
+			layers := make([][]float32, m.net.TotalLayers())
+			for i := range layers {
+				layers[i] = append([]float32(nil), m.state.GetLayerOutput(i+1)...)
+			}
+			s.Layers[name] = layers
+		}
+	}
+	r.recorder.Add(s)
+}
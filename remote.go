@@ -0,0 +1,335 @@
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// RemoteCheckpoint describes the latest weights of one model held by a
+// CheckpointServer.
+type RemoteCheckpoint struct {
+	Model   string    `json:"model"`
+	Version int       `json:"version"`         // Incremented by the server on every push
+	SHA256  string    `json:"sha256"`          // Of the saved model's JSON
+	Node    string    `json:"node,omitempty"`  // Node that pushed it
+	Steps   uint64    `json:"steps,omitempty"` // Steps the pushing runtime had taken
+	Pushed  time.Time `json:"pushed"`
+}
+
+// remotePush is the body of a push, and of a pulled checkpoint.
+type remotePush struct {
+	Checkpoint RemoteCheckpoint `json:"checkpoint"`
+	Model      json.RawMessage  `json:"model"` // A loom saved model
+}
+
+// CheckpointServer is a central store of model weights for a fleet. Nodes
+// push their trained models with CheckpointClient.Push and pull the latest
+// ones with CheckpointClient.Pull. As an http.Handler it serves:
+//
+//	GET /checkpoints           the RemoteCheckpoint of every model
+//	GET /checkpoints/{model}   the latest weights of a model
+//	PUT /checkpoints/{model}   push new weights for a model
+//	POST /federated/{model}    contribute to federated averaging; see CheckpointClient.Contribute
+//
+// Mount it under a prefix with http.StripPrefix.
+type CheckpointServer struct {
+	Token  string // When set, requests must carry it as a bearer token
+	Quorum int    // Contributions averaged per federated round; 0 means DefaultQuorum
+
+	dir    string
+	mu     sync.RWMutex
+	models map[string]remotePush
+	rounds map[string]*fedRound
+}
+
+// NewCheckpointServer creates a checkpoint server. With a non-empty dir,
+// pushed checkpoints are written there as <model>.json and the ones already
+// there are served; otherwise they are kept in memory only.
+func NewCheckpointServer(dir string) (*CheckpointServer, error) {
+	s := &CheckpointServer{dir: dir, models: make(map[string]remotePush), rounds: make(map[string]*fedRound)}
+	if dir == "" {
+		return s, nil
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var p remotePush
+		if err := json.Unmarshal(data, &p); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		s.models[p.Checkpoint.Model] = p
+	}
+	return s, nil
+}
+
+// Checkpoints returns the RemoteCheckpoint of every model, sorted by model.
+func (s *CheckpointServer) Checkpoints() []RemoteCheckpoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]RemoteCheckpoint, 0, len(s.models))
+	for _, name := range sortedKeys(s.models) {
+		out = append(out, s.models[name].Checkpoint)
+	}
+	return out
+}
+
+// push stores new weights for a model as its next version.
+func (s *CheckpointServer) push(model string, p remotePush) (RemoteCheckpoint, error) {
+	var saved nn.SavedModel
+	if err := json.Unmarshal(p.Model, &saved); err != nil {
+		return RemoteCheckpoint{}, fmt.Errorf("model %q: %w", model, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.store(model, p)
+}
+
+// store saves p as the model's next version. The caller holds s.mu.
+func (s *CheckpointServer) store(model string, p remotePush) (RemoteCheckpoint, error) {
+	sum := sha256.Sum256(p.Model)
+	p.Checkpoint = RemoteCheckpoint{
+		Model:   model,
+		Version: s.models[model].Checkpoint.Version + 1,
+		SHA256:  hex.EncodeToString(sum[:]),
+		Node:    p.Checkpoint.Node,
+		Steps:   p.Checkpoint.Steps,
+		Pushed:  time.Now().UTC(),
+	}
+	if s.dir != "" {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return RemoteCheckpoint{}, err
+		}
+		if err := writeFileAtomic(filepath.Join(s.dir, model+".json"), data, 0644, false); err != nil {
+			return RemoteCheckpoint{}, err
+		}
+	}
+	s.models[model] = p
+	return p.Checkpoint, nil
+}
+
+// ServeHTTP implements http.Handler.
+func (s *CheckpointServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.Token != "" && req.Header.Get("Authorization") != "Bearer "+s.Token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if req.URL.Path == "/checkpoints" {
+		if req.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, s.Checkpoints())
+		return
+	}
+	if model, ok := strings.CutPrefix(req.URL.Path, "/federated/"); ok && validModelPath(model) {
+		s.serveFederated(w, req, model)
+		return
+	}
+	model, ok := strings.CutPrefix(req.URL.Path, "/checkpoints/")
+	if !ok || !validModelPath(model) {
+		http.NotFound(w, req)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		s.mu.RLock()
+		p, ok := s.models[model]
+		s.mu.RUnlock()
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		// Not indented: that would reformat the model, failing its checksum.
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	case http.MethodPut:
+		var p remotePush
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<30)).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cp, err := s.push(model, p)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, cp)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// validModelPath reports whether a model name from a URL is safe to use as a
+// file name.
+func validModelPath(model string) bool {
+	return model != "" && !strings.ContainsAny(model, `/\`) && model[0] != '.'
+}
+
+// CheckpointClient pushes a runtime's weights to a CheckpointServer and
+// pulls the fleet's latest weights into it. It remembers the version of each
+// model it last pushed or pulled, so Pull only fetches newer ones.
+type CheckpointClient struct {
+	URL    string // Server base URL, e.g. http://collector:7070
+	Node   string // Reported with pushes; defaults to the host name
+	Token  string // Optional bearer token
+	Client *http.Client
+
+	mu       sync.Mutex
+	versions map[string]int
+	bases    map[string]nn.SavedModel // Weights as of versions; see Contribute
+}
+
+// NewCheckpointClient creates a client of the server at url.
+func NewCheckpointClient(url, node string) *CheckpointClient {
+	if node == "" {
+		node, _ = os.Hostname()
+	}
+	return &CheckpointClient{URL: strings.TrimRight(url, "/"), Node: node, versions: make(map[string]int), bases: make(map[string]nn.SavedModel)}
+}
+
+func (c *CheckpointClient) auth(req *http.Request) {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+}
+
+func (c *CheckpointClient) get(path string, out any) error {
+	req, err := http.NewRequest(http.MethodGet, c.URL+path, nil)
+	if err != nil {
+		return err
+	}
+	c.auth(req)
+	return doRequest(c.Client, req, out)
+}
+
+// Checkpoints lists the server's checkpoints.
+func (c *CheckpointClient) Checkpoints() ([]RemoteCheckpoint, error) {
+	var out []RemoteCheckpoint
+	if err := c.get("/checkpoints", &out); err != nil {
+		return nil, fmt.Errorf("checkpoints: %w", err)
+	}
+	return out, nil
+}
+
+// Push uploads the current weights of the named models, or of every model
+// when none are named, and returns the versions the server assigned.
+func (c *CheckpointClient) Push(r *Runtime, models ...string) ([]RemoteCheckpoint, error) {
+	if len(models) == 0 {
+		models = r.Models()
+	}
+	steps := r.Steps()
+	saved, err := r.saveModels(models)
+	if err != nil {
+		return nil, err
+	}
+	var pushed []RemoteCheckpoint
+	for i, name := range models {
+		data, err := json.Marshal(saved[i])
+		if err != nil {
+			return pushed, fmt.Errorf("model %q: %w", name, err)
+		}
+		p := remotePush{Checkpoint: RemoteCheckpoint{Node: c.Node, Steps: steps}, Model: data}
+		var cp RemoteCheckpoint
+		if err := postJSON(c.Client, http.MethodPut, c.URL+"/checkpoints/"+url.PathEscape(name), p, &cp, c.auth); err != nil {
+			return pushed, fmt.Errorf("push %q: %w", name, err)
+		}
+		c.mu.Lock()
+		c.versions[name], c.bases[name] = cp.Version, saved[i]
+		c.mu.Unlock()
+		pushed = append(pushed, cp)
+	}
+	return pushed, nil
+}
+
+// Pull fetches the server's weights for the named models, or for every
+// model of the runtime the server has, that are newer than the versions this
+// client last pushed or pulled, and hot-swaps them into r. The swap is safe
+// to make while r is stepping from other goroutines: it happens between
+// steps, and only after every fetched model has passed its checksum and
+// matched its model's architecture, so either all the updates are applied or
+// none are. Pull returns the checkpoints applied.
+func (c *CheckpointClient) Pull(r *Runtime, models ...string) ([]RemoteCheckpoint, error) {
+	all, err := c.Checkpoints()
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]RemoteCheckpoint, len(all))
+	for _, cp := range all {
+		available[cp.Model] = cp
+	}
+	if len(models) == 0 {
+		for _, name := range r.Models() {
+			if _, ok := available[name]; ok {
+				models = append(models, name)
+			}
+		}
+	}
+	c.mu.Lock()
+	known := make(map[string]int, len(models))
+	for _, name := range models {
+		known[name] = c.versions[name]
+	}
+	c.mu.Unlock()
+
+	bundle := &nn.ModelBundle{Type: "modelhost/bundle", Version: loomBundleVersion}
+	var applied []RemoteCheckpoint
+	for _, name := range models {
+		if r.Network(name) == nil {
+			return nil, fmt.Errorf("model %q not found", name)
+		}
+		if _, ok := available[name]; !ok {
+			return nil, fmt.Errorf("model %q: no checkpoint on the server", name)
+		}
+		if available[name].Version <= known[name] {
+			continue
+		}
+		var p remotePush
+		if err := c.get("/checkpoints/"+url.PathEscape(name), &p); err != nil {
+			return nil, fmt.Errorf("pull %q: %w", name, err)
+		}
+		sum := sha256.Sum256(p.Model)
+		if hex.EncodeToString(sum[:]) != p.Checkpoint.SHA256 {
+			return nil, fmt.Errorf("pull %q: checksum mismatch", name)
+		}
+		var saved nn.SavedModel
+		if err := json.Unmarshal(p.Model, &saved); err != nil {
+			return nil, fmt.Errorf("pull %q: %w", name, err)
+		}
+		saved.ID = name
+		bundle.Models = append(bundle.Models, saved)
+		applied = append(applied, p.Checkpoint)
+	}
+	if len(applied) == 0 {
+		return nil, nil
+	}
+	if err := r.restoreBundle(bundle); err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	for i, cp := range applied {
+		c.versions[cp.Model], c.bases[cp.Model] = cp.Version, bundle.Models[i]
+	}
+	c.mu.Unlock()
+	return applied, nil
+}
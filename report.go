@@ -0,0 +1,392 @@
+package drift
+
+import (
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxReportCharts bounds how many metric series a report draws.
+const maxReportCharts = 24
+
+// RunReport gathers what is known about one run into a single
+// self-contained HTML or Markdown document: a manifest, the config graph,
+// benchmark results, metric tables with charts, and a summary of what each
+// link carried. Every field but Config is optional.
+type RunReport struct {
+	Title   string
+	Config  *Config
+	Stats   *RuntimeStats
+	Results []ExperimentResult
+	Metrics []Metric     // Summarized per series and charted
+	Steps   []StepRecord // Recorded steps, analyzed per link
+}
+
+// MetricSummary condenses one metric series, identified by name and labels.
+type MetricSummary struct {
+	Series string  `json:"series"`
+	Count  int     `json:"count"`
+	Last   float64 `json:"last"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+}
+
+// LinkSummary describes the protocol a link carried over a recording.
+type LinkSummary struct {
+	Link       string  `json:"link"`
+	Steps      int     `json:"steps"`       // Steps with a payload
+	Dims       int     `json:"dims"`        // Payload width
+	ActiveDims int     `json:"active_dims"` // Dimensions whose stddev exceeds 1% of the largest
+	MeanAbs    float64 `json:"mean_abs"`    // Mean absolute value over all elements
+	MeanNorm   float64 `json:"mean_norm"`   // Mean L2 norm of a payload
+	Sparsity   float64 `json:"sparsity"`    // Fraction of elements below 1e-3 in magnitude
+}
+
+// SummarizeMetrics returns one summary per metric series, sorted by series.
+func SummarizeMetrics(metrics []Metric) []MetricSummary {
+	byKey := make(map[string]*MetricSummary)
+	for _, m := range metrics {
+		key := metricKey(m)
+		s := byKey[key]
+		if s == nil {
+			s = &MetricSummary{Series: key, Min: m.Value, Max: m.Value}
+			byKey[key] = s
+		}
+		s.Count++
+		s.Last = m.Value
+		s.Min = math.Min(s.Min, m.Value)
+		s.Max = math.Max(s.Max, m.Value)
+		s.Mean += (m.Value - s.Mean) / float64(s.Count)
+	}
+	out := make([]MetricSummary, 0, len(byKey))
+	for _, key := range sortedKeys(byKey) {
+		out = append(out, *byKey[key])
+	}
+	return out
+}
+
+// AnalyzeLinks summarizes the payloads of every link in a recording.
+func AnalyzeLinks(steps []StepRecord) []LinkSummary {
+	payloads := make(map[string][][]float32)
+	for _, s := range steps {
+		for name, p := range s.Links {
+			if len(p) > 0 {
+				payloads[name] = append(payloads[name], p)
+			}
+		}
+	}
+	var out []LinkSummary
+	for _, name := range sortedKeys(payloads) {
+		ps := payloads[name]
+		ls := LinkSummary{Link: name, Steps: len(ps), Dims: len(ps[0])}
+		sum := make([]float64, ls.Dims)
+		sumSq := make([]float64, ls.Dims)
+		var elems, small int
+		for _, p := range ps {
+			var sq float64
+			for i, v := range p {
+				x := float64(v)
+				if i < ls.Dims {
+					sum[i] += x
+					sumSq[i] += x * x
+				}
+				ls.MeanAbs += math.Abs(x)
+				sq += x * x
+				if math.Abs(x) < 1e-3 {
+					small++
+				}
+				elems++
+			}
+			ls.MeanNorm += math.Sqrt(sq)
+		}
+		n := float64(len(ps))
+		ls.MeanNorm /= n
+		if elems > 0 {
+			ls.MeanAbs /= float64(elems)
+			ls.Sparsity = float64(small) / float64(elems)
+		}
+		std := make([]float64, ls.Dims)
+		var maxStd float64
+		for i := range std {
+			mean := sum[i] / n
+			std[i] = math.Sqrt(math.Max(sumSq[i]/n-mean*mean, 0))
+			maxStd = math.Max(maxStd, std[i])
+		}
+		for _, s := range std {
+			if maxStd > 0 && s > 0.01*maxStd {
+				ls.ActiveDims++
+			}
+		}
+		out = append(out, ls)
+	}
+	return out
+}
+
+// reportSeries is the data points of one metric series, in arrival order.
+type reportSeries struct {
+	Name   string
+	Values []float64
+}
+
+// series groups the metrics into chartable series of at least two points,
+// up to maxReportCharts, and counts the ones left out.
+func (r *RunReport) series() (charts []reportSeries, truncated int) {
+	byKey := make(map[string]*reportSeries)
+	var order []string
+	for _, m := range r.Metrics {
+		key := metricKey(m)
+		s := byKey[key]
+		if s == nil {
+			s = &reportSeries{Name: key}
+			byKey[key] = s
+			order = append(order, key)
+		}
+		s.Values = append(s.Values, m.Value)
+	}
+	sort.Strings(order)
+	for _, key := range order {
+		if len(byKey[key].Values) < 2 {
+			continue
+		}
+		if len(charts) == maxReportCharts {
+			truncated++
+			continue
+		}
+		charts = append(charts, *byKey[key])
+	}
+	return charts, truncated
+}
+
+// chartSVG draws a series as a small line chart.
+func chartSVG(s reportSeries) string {
+	const w, h, pad = 320.0, 120.0, 6.0
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range s.Values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+	}
+	if math.IsInf(lo, 0) {
+		lo, hi = 0, 1
+	}
+	if hi == lo {
+		hi = lo + 1
+	}
+	var pts strings.Builder
+	for i, v := range s.Values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		x := pad + (w-2*pad)*float64(i)/float64(len(s.Values)-1)
+		y := h - pad - (h-2*pad-14)*(v-lo)/(hi-lo)
+		fmt.Fprintf(&pts, "%.1f,%.1f ", x, y)
+	}
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`+
+		`<rect width="100%%" height="100%%" fill="#fff" stroke="#ccc"/>`+
+		`<text x="%g" y="12" font-size="10" font-family="sans-serif">%s [%.4g, %.4g]</text>`+
+		`<polyline fill="none" stroke="#2563eb" stroke-width="1.5" points="%s"/></svg>`,
+		w, h, w, h, pad, template.HTMLEscapeString(s.Name), lo, hi, strings.TrimSpace(pts.String()))
+}
+
+// graphSVG draws the config's models on a circle with an arrow per link.
+func graphSVG(c *Config) string {
+	const size, radius = 420.0, 150.0
+	names := sortedKeys(c.Models)
+	pos := make(map[string][2]float64, len(names))
+	for i, name := range names {
+		a := 2*math.Pi*float64(i)/float64(len(names)) - math.Pi/2
+		if len(names) == 1 {
+			a = 0
+		}
+		pos[name] = [2]float64{size/2 + radius*math.Cos(a), size/2 + radius*math.Sin(a)}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g" font-family="sans-serif">`, size, size, size, size)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto"><path d="M0,0L10,5L0,10z" fill="#555"/></marker></defs>`)
+	for _, l := range c.Links {
+		from, okFrom := pos[l.SourceModel]
+		to, okTo := pos[l.TargetModel]
+		if !okFrom || !okTo {
+			continue
+		}
+		// Shorten the arrow so it ends at the target's box.
+		dx, dy := to[0]-from[0], to[1]-from[1]
+		d := math.Max(math.Hypot(dx, dy), 1)
+		x2, y2 := to[0]-dx/d*40, to[1]-dy/d*20
+		stroke := `stroke="#555"`
+		if !l.Enabled {
+			stroke = `stroke="#bbb" stroke-dasharray="4 3"`
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" %s marker-end="url(#arrow)"/>`, from[0], from[1], x2, y2, stroke)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="11" text-anchor="middle" fill="#333">%s (%d)</text>`,
+			(from[0]+to[0])/2, (from[1]+to[1])/2-4, template.HTMLEscapeString(l.Name), l.LinkSize)
+	}
+	for _, name := range names {
+		p := pos[name]
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="80" height="28" rx="4" fill="#eef2ff" stroke="#4f46e5"/>`, p[0]-40, p[1]-14)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" font-size="12" text-anchor="middle">%s</text>`, p[0], p[1]+4, template.HTMLEscapeString(name))
+	}
+	b.WriteString(`</svg>`)
+	return b.String()
+}
+
+// manifest returns the key facts about the run as label/value pairs.
+func (r *RunReport) manifest() [][2]string {
+	c := r.Config
+	hash, err := c.Hash()
+	if err != nil {
+		hash = "unavailable: " + err.Error()
+	}
+	rows := [][2]string{
+		{"Config", c.Name},
+		{"Config hash", hash},
+		{"Schema version", fmt.Sprint(c.SchemaVersion)},
+		{"Seed", fmt.Sprint(c.Seed)},
+		{"DType", string(c.EffectiveDType())},
+		{"Models", fmt.Sprint(len(c.Models))},
+		{"Links", fmt.Sprint(len(c.Links))},
+	}
+	if r.Stats != nil {
+		rows = append(rows,
+			[2]string{"Steps", fmt.Sprint(r.Stats.Steps)},
+			[2]string{"Uptime", r.Stats.Uptime.Round(time.Millisecond).String()})
+	}
+	for _, o := range c.Overrides() {
+		rows = append(rows, [2]string{"Override", o.Var + "=" + o.Value})
+	}
+	for _, w := range c.Warnings() {
+		rows = append(rows, [2]string{"Warning", w})
+	}
+	return rows
+}
+
+func (r *RunReport) title() string {
+	if r.Title != "" {
+		return r.Title
+	}
+	return "DRIFT run: " + r.Config.Name
+}
+
+// WriteHTML writes the report as a single HTML page with inline SVG charts
+// and no external resources.
+func (r *RunReport) WriteHTML(w io.Writer) error {
+	charts, truncated := r.series()
+	svgs := make([]template.HTML, len(charts))
+	for i, s := range charts {
+		svgs[i] = template.HTML(chartSVG(s))
+	}
+	return reportTemplate.Execute(w, struct {
+		Title     string
+		Manifest  [][2]string
+		Graph     template.HTML
+		Links     []NeuralLinkConfig
+		Results   []ExperimentResult
+		Metrics   []MetricSummary
+		Charts    []template.HTML
+		Truncated int
+		Protocol  []LinkSummary
+	}{r.title(), r.manifest(), template.HTML(graphSVG(r.Config)), r.Config.Links, r.Results,
+		SummarizeMetrics(r.Metrics), svgs, truncated, AnalyzeLinks(r.Steps)})
+}
+
+// WriteMarkdown writes the report as Markdown. The config graph is a
+// Mermaid diagram and charts are embedded as SVG data URIs.
+func (r *RunReport) WriteMarkdown(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n## Manifest\n\n| | |\n|---|---|\n", r.title())
+	for _, row := range r.manifest() {
+		fmt.Fprintf(&b, "| %s | %s |\n", row[0], mdCell(row[1]))
+	}
+
+	b.WriteString("\n## Config graph\n\n```mermaid\ngraph LR\n")
+	for _, name := range sortedKeys(r.Config.Models) {
+		fmt.Fprintf(&b, "  %s[%q]\n", envName(name), name)
+	}
+	for _, l := range r.Config.Links {
+		arrow := "-->"
+		if !l.Enabled {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&b, "  %s %s|%q| %s\n", envName(l.SourceModel), arrow, fmt.Sprintf("%s (%d)", l.Name, l.LinkSize), envName(l.TargetModel))
+	}
+	b.WriteString("```\n\n| Link | Source | Target | Size | Enabled |\n|---|---|---|---|---|\n")
+	for _, l := range r.Config.Links {
+		fmt.Fprintf(&b, "| %s | %s[%d] | %s@%d | %d | %t |\n", mdCell(l.Name), mdCell(l.SourceModel), l.SourceLayer, mdCell(l.TargetModel), l.TargetOffset, l.LinkSize, l.Enabled)
+	}
+
+	if len(r.Results) > 0 {
+		b.WriteString("\n## Results\n\n| Mode | Targets | Steps | Accuracy % |\n|---|---|---|---|\n")
+		for _, res := range r.Results {
+			fmt.Fprintf(&b, "| %s | %d | %d | %.2f |\n", mdCell(res.Mode), res.TotalTargets, res.TotalSteps, res.FinalAccuracy)
+		}
+	}
+
+	if len(r.Metrics) > 0 {
+		b.WriteString("\n## Metrics\n\n| Series | Count | Last | Min | Max | Mean |\n|---|---|---|---|---|---|\n")
+		for _, m := range SummarizeMetrics(r.Metrics) {
+			fmt.Fprintf(&b, "| %s | %d | %.4g | %.4g | %.4g | %.4g |\n", mdCell(m.Series), m.Count, m.Last, m.Min, m.Max, m.Mean)
+		}
+		charts, truncated := r.series()
+		b.WriteString("\n")
+		for _, s := range charts {
+			fmt.Fprintf(&b, "![%s](data:image/svg+xml;base64,%s)\n", mdCell(s.Name), base64.StdEncoding.EncodeToString([]byte(chartSVG(s))))
+		}
+		if truncated > 0 {
+			fmt.Fprintf(&b, "\n%d more series not charted.\n", truncated)
+		}
+	}
+
+	if protocol := AnalyzeLinks(r.Steps); len(protocol) > 0 {
+		b.WriteString("\n## Link protocols\n\n| Link | Steps | Dims | Active dims | Mean abs | Mean norm | Sparsity |\n|---|---|---|---|---|---|---|\n")
+		for _, p := range protocol {
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %.4g | %.4g | %.3f |\n", mdCell(p.Link), p.Steps, p.Dims, p.ActiveDims, p.MeanAbs, p.MeanNorm, p.Sparsity)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mdCell escapes a value for a Markdown table cell.
+func mdCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+var reportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title>
+<style>
+body{font-family:sans-serif;margin:1em 2em;max-width:1100px}
+table{border-collapse:collapse;margin:.5em 0}
+td,th{border:1px solid #ddd;padding:2px 8px;text-align:left;font-size:13px}
+th{background:#f5f5f5}
+.charts svg{margin:4px}
+</style></head>
+<body><h1>{{.Title}}</h1>
+<h2>Manifest</h2>
+<table>{{range .Manifest}}<tr><th>{{index . 0}}</th><td>{{index . 1}}</td></tr>{{end}}</table>
+<h2>Config graph</h2>
+{{.Graph}}
+<table><tr><th>Link</th><th>Source</th><th>Target</th><th>Size</th><th>Enabled</th></tr>
+{{range .Links}}<tr><td>{{.Name}}</td><td>{{.SourceModel}}[{{.SourceLayer}}]</td><td>{{.TargetModel}}@{{.TargetOffset}}</td><td>{{.LinkSize}}</td><td>{{.Enabled}}</td></tr>
+{{end}}</table>
+{{if .Results}}<h2>Results</h2>
+<table><tr><th>Mode</th><th>Targets</th><th>Steps</th><th>Accuracy %</th></tr>
+{{range .Results}}<tr><td>{{.Mode}}</td><td>{{.TotalTargets}}</td><td>{{.TotalSteps}}</td><td>{{printf "%.2f" .FinalAccuracy}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Metrics}}<h2>Metrics</h2>
+<table><tr><th>Series</th><th>Count</th><th>Last</th><th>Min</th><th>Max</th><th>Mean</th></tr>
+{{range .Metrics}}<tr><td>{{.Series}}</td><td>{{.Count}}</td><td>{{printf "%.4g" .Last}}</td><td>{{printf "%.4g" .Min}}</td><td>{{printf "%.4g" .Max}}</td><td>{{printf "%.4g" .Mean}}</td></tr>
+{{end}}</table>
+<div class="charts">{{range .Charts}}{{.}}{{end}}</div>
+{{if .Truncated}}<p>{{.Truncated}} more series not charted.</p>{{end}}{{end}}
+{{if .Protocol}}<h2>Link protocols</h2>
+<table><tr><th>Link</th><th>Steps</th><th>Dims</th><th>Active dims</th><th>Mean abs</th><th>Mean norm</th><th>Sparsity</th></tr>
+{{range .Protocol}}<tr><td>{{.Link}}</td><td>{{.Steps}}</td><td>{{.Dims}}</td><td>{{.ActiveDims}}</td><td>{{printf "%.4g" .MeanAbs}}</td><td>{{printf "%.4g" .MeanNorm}}</td><td>{{printf "%.3f" .Sparsity}}</td></tr>
+{{end}}</table>{{end}}
+</body></html>
+`))
@@ -0,0 +1,106 @@
+package drift
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ResultLog appends results to a JSON Lines file as they are produced, so a
+// crash late in a long benchmark loses at most the record being written.
+// It also implements MetricSink, writing one line per metric.
+type ResultLog struct {
+	// Sync forces an fsync after every record. It is off by default; Flush
+	// syncs whatever has been written.
+	Sync bool
+
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// OpenResultLog opens path for appending, creating it if needed.
+func OpenResultLog(path string) (*ResultLog, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &ResultLog{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Append writes v as a single line.
+func (l *ResultLog) Append(v interface{}) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.enc.Encode(v); err != nil {
+		return err
+	}
+	if l.Sync {
+		return l.f.Sync()
+	}
+	return nil
+}
+
+// Record writes m as a single line.
+func (l *ResultLog) Record(m Metric) error {
+	return l.Append(m)
+}
+
+// Flush syncs the file to stable storage.
+func (l *ResultLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.f.Sync()
+}
+
+// Close syncs and closes the file.
+func (l *ResultLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.f.Sync(); err != nil {
+		l.f.Close()
+		return err
+	}
+	return l.f.Close()
+}
+
+// ReadResultLog calls fn with every complete line of the log at path.
+// A trailing partial line, left behind by a crash mid-write, is ignored.
+func ReadResultLog(path string, fn func(line json.RawMessage) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readResultLines(f, path, fn)
+}
+
+// readResultLines implements ReadResultLog for any reader; name identifies it
+// in errors.
+func readResultLines(r io.Reader, name string, fn func(line json.RawMessage) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for sc.Scan() {
+		lineNum++
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if !json.Valid(line) {
+			// Only the final line can be torn; anything else is real corruption.
+			if sc.Scan() {
+				return fmt.Errorf("%s: line %d is not valid JSON", name, lineNum)
+			}
+			return nil
+		}
+		if err := fn(append(json.RawMessage(nil), line...)); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
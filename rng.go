@@ -0,0 +1,126 @@
+package drift
+
+import (
+	"hash/fnv"
+	"math/rand"
+	"sync"
+)
+
+// Random streams used by the package. Components draw from their own
+// stream, or from a substream per model or link (see SubStream), so that,
+// say, changing how much exploration randomness is drawn doesn't shift the
+// environment's layouts between runs.
+const (
+	StreamWeights     = "weights"     // Initial weights; substream per model
+	StreamEnvironment = "environment" // For environments, through Runtime.Rand; domain randomization, fuzz cases
+	StreamExploration = "exploration" // Stochastic link delivery, substream per link; policy sampling; self-play partners, substream per model
+	StreamLinkNoise   = "link_noise"  // Link noise; substream per link
+	StreamTraining    = "training"    // Sample order and initial weights of offline fits, e.g. AlignLink
+)
+
+// Rand is a stream of random numbers. *rand.Rand implements it.
+type Rand interface {
+	Int63() int64
+	Intn(n int) int
+	Float32() float32
+	Float64() float64
+	NormFloat64() float64
+}
+
+// RandSource provides independent named streams of random numbers. Every
+// call with the same name must return the same stream.
+type RandSource interface {
+	Stream(name string) Rand
+}
+
+// SubStream names the part of a stream belonging to one model or link.
+func SubStream(stream, part string) string {
+	return stream + "/" + part
+}
+
+// seededStream returns the stream called name of a SeededSource rooted at
+// seed, for components seeded on their own rather than by a runtime.
+func seededStream(seed int64, name string) Rand {
+	return NewSeededSource(seed).Stream(name)
+}
+
+// perm returns a random permutation of [0, n) drawn from rng.
+func perm(rng Rand, n int) []int {
+	p := make([]int, n)
+	for i := range p {
+		j := rng.Intn(i + 1)
+		p[i], p[j] = p[j], i
+	}
+	return p
+}
+
+// SeededSource derives every stream from one root seed and the stream's
+// name, so a run is reproducible from the seed and streams never influence
+// each other. Streams are safe for concurrent use.
+type SeededSource struct {
+	seed    int64
+	mu      sync.Mutex
+	streams map[string]*lockedRand
+}
+
+// NewSeededSource creates a source with root seed seed.
+func NewSeededSource(seed int64) *SeededSource {
+	return &SeededSource{seed: seed, streams: make(map[string]*lockedRand)}
+}
+
+// Stream returns the stream called name, seeded from the root seed and an
+// FNV-1a hash of name.
+func (s *SeededSource) Stream(name string) Rand {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.streams[name]
+	if !ok {
+		h := fnv.New64a()
+		h.Write([]byte(name))
+		r = &lockedRand{r: rand.New(rand.NewSource(s.seed ^ int64(h.Sum64())))}
+		s.streams[name] = r
+	}
+	return r
+}
+
+// lockedRand serializes access to a rand.Rand.
+type lockedRand struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+func (l *lockedRand) Int63() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Int63()
+}
+
+func (l *lockedRand) Intn(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Intn(n)
+}
+
+func (l *lockedRand) Float32() float32 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float32()
+}
+
+func (l *lockedRand) Float64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.Float64()
+}
+
+func (l *lockedRand) NormFloat64() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.r.NormFloat64()
+}
+
+// Rand returns the runtime's random stream called name, e.g.
+// StreamEnvironment for an environment's layouts.
+func (r *Runtime) Rand(name string) Rand {
+	return r.rand.Stream(name)
+}
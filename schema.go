@@ -0,0 +1,173 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// CurrentSchemaVersion is the config schema this package reads and writes.
+// Files with an older SchemaVersion, or none, are upgraded when loaded.
+const CurrentSchemaVersion = 1
+
+// ErrSchemaTooNew is returned for configs written by a newer version of the
+// package.
+var ErrSchemaTooNew = errors.New("drift: config schema is newer than supported")
+
+// Migration upgrades a decoded config document by one schema version, in
+// place, returning warnings about deprecated fields it rewrote or dropped.
+// Numbers in doc are json.Number, so large integers keep their precision.
+type Migration func(doc map[string]any) (warnings []string, err error)
+
+// migrations holds the registered migrations keyed by the version they
+// upgrade from.
+var migrations = struct {
+	sync.RWMutex
+	m map[int]Migration
+}{m: map[int]Migration{
+	// Version 0 predates the schema_version field; its layout is that of
+	// version 1.
+	0: func(map[string]any) ([]string, error) { return nil, nil },
+}}
+
+// RegisterMigration registers the migration from schema version from to
+// from+1, replacing any earlier one.
+func RegisterMigration(from int, m Migration) {
+	migrations.Lock()
+	defer migrations.Unlock()
+	migrations.m[from] = m
+}
+
+// Warnings returns the warnings produced while loading the config, about
+// deprecated or unknown fields and environment overrides that matched
+// nothing, or nil if there were none.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// decodeConfig decodes a JSON config, migrating it to CurrentSchemaVersion
+// and expanding ${var} references with vars and the document's variables
+// section first. Every loader goes through it. Documents left unchanged are
+// decoded as they are, so model definitions keep their original bytes.
+func decodeConfig(data []byte, vars map[string]any) (*Config, error) {
+	doc, err := unmarshalDoc(data)
+	if err != nil {
+		return nil, err
+	}
+	warnings, err := migrate(doc, CurrentSchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	changed := false
+	if schemaVersion(doc) != CurrentSchemaVersion {
+		orig, _ := unmarshalDoc(data)
+		changed = !reflect.DeepEqual(orig, doc)
+	}
+	expanded, err := expandVariables(doc, vars)
+	if err != nil {
+		return nil, err
+	}
+	if changed || expanded {
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	var c Config
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	c.SchemaVersion = CurrentSchemaVersion
+	c.warnings = append(warnings, unknownFields(doc)...)
+	c.enabledUnset = linksWithoutEnabled(doc)
+	return &c, nil
+}
+
+// unmarshalDoc decodes a JSON object with numbers as json.Number, so integers
+// above 2^53 survive being encoded again.
+func unmarshalDoc(data []byte) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("invalid data after top-level value")
+	}
+	return doc, nil
+}
+
+// schemaVersion returns the version recorded in doc; 0 when absent.
+func schemaVersion(doc map[string]any) int {
+	v, _ := doc["schema_version"].(json.Number)
+	n, _ := v.Int64()
+	return int(n)
+}
+
+// migrate upgrades doc to version target by applying registered migrations
+// in sequence. The version recorded in doc is left for the caller to check.
+func migrate(doc map[string]any, target int) ([]string, error) {
+	version := schemaVersion(doc)
+	if version > target {
+		return nil, fmt.Errorf("schema version %d: %w", version, ErrSchemaTooNew)
+	}
+	migrations.RLock()
+	defer migrations.RUnlock()
+	var warnings []string
+	for ; version < target; version++ {
+		m, ok := migrations.m[version]
+		if !ok {
+			return warnings, fmt.Errorf("no migration from schema version %d", version)
+		}
+		w, err := m(doc)
+		if err != nil {
+			return warnings, fmt.Errorf("migrating schema version %d: %w", version, err)
+		}
+		warnings = append(warnings, w...)
+	}
+	return warnings, nil
+}
+
+// unknownFields warns about keys of the config and its links that no field
+// reads, which are usually left over from an older schema.
+func unknownFields(doc map[string]any) []string {
+	var warnings []string
+	for _, k := range sortedKeys(doc) {
+		if !hasJSONField(reflect.TypeOf(Config{}), k) {
+			warnings = append(warnings, fmt.Sprintf("unknown field %q ignored", k))
+		}
+	}
+	links, _ := doc["links"].([]any)
+	for i, l := range links {
+		m, _ := l.(map[string]any)
+		for _, k := range sortedKeys(m) {
+			if !hasJSONField(reflect.TypeOf(NeuralLinkConfig{}), k) {
+				warnings = append(warnings, fmt.Sprintf("links[%d]: unknown field %q ignored", i, k))
+			}
+		}
+	}
+	return warnings
+}
+
+// hasJSONField reports whether struct type t has a field encoded as key.
+func hasJSONField(t reflect.Type, key string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		if name != "-" && strings.EqualFold(name, key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,168 @@
+package drift
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Built-in objectives, scored for every benchmark result.
+const (
+	ObjectivePerformance   = "performance"   // Final accuracy, percent
+	ObjectiveCommunication = "communication" // Link values delivered per step
+	ObjectiveEnergy        = "energy"        // Joules per step; needs an EnergyModel
+	ObjectiveAdaptation    = "adaptation"    // Mean windows to recover after a terrain change; see Transition
+)
+
+// Objective is one axis of multi-objective scoring.
+type Objective struct {
+	Name     string `json:"name"`
+	Maximize bool   `json:"maximize,omitempty"` // Higher is better; otherwise lower is
+}
+
+// DefaultObjectives trades task performance against communication, energy
+// and adaptation speed.
+var DefaultObjectives = []Objective{
+	{Name: ObjectivePerformance, Maximize: true},
+	{Name: ObjectiveCommunication},
+	{Name: ObjectiveEnergy},
+	{Name: ObjectiveAdaptation},
+}
+
+// Score fills in the built-in objectives of res that aren't already set,
+// from the runtime the benchmark ran on, along with its Transitions. Energy
+// is scored only with em, and adaptation only when the windows cross
+// terrains. Environments may set scores of their own beforehand.
+func (res *ExperimentResult) Score(r *Runtime, em *EnergyModel) error {
+	if res.Scores == nil {
+		res.Scores = make(map[string]float64)
+	}
+	set := func(name string, v float64) {
+		if _, ok := res.Scores[name]; !ok {
+			res.Scores[name] = v
+		}
+	}
+	set(ObjectivePerformance, res.FinalAccuracy)
+
+	if steps := r.Steps(); steps > 0 {
+		var values uint64
+		for _, l := range r.Links() {
+			link, _ := r.cfg.GetLink(l.Name)
+			values += l.Transfers * uint64(link.LinkSize)
+		}
+		set(ObjectiveCommunication, float64(values)/float64(steps))
+	}
+
+	if em != nil {
+		m, err := NewEnergyMeter(r.cfg, *em)
+		if err != nil {
+			return err
+		}
+		joules := 0.0
+		for name, macs := range m.macs {
+			joules += float64(macs) * m.jpm[name]
+		}
+		set(ObjectiveEnergy, joules)
+	}
+
+	if res.Transitions == nil {
+		res.Transitions = AdaptationSpeed(res.Windows, DefaultRecoveryTolerance)
+	}
+	if len(res.Transitions) > 0 {
+		total := 0
+		for _, t := range res.Transitions {
+			total += t.Windows
+		}
+		set(ObjectiveAdaptation, float64(total)/float64(len(res.Transitions)))
+	}
+	return nil
+}
+
+// Dominates reports whether a is at least as good as b on every objective
+// and better on one. Objectives either result lacks a score for are ignored.
+func Dominates(a, b ExperimentResult, objectives []Objective) bool {
+	better := false
+	for _, o := range objectives {
+		va, okA := a.Scores[o.Name]
+		vb, okB := b.Scores[o.Name]
+		if !okA || !okB {
+			continue
+		}
+		if !o.Maximize {
+			va, vb = -va, -vb
+		}
+		if va < vb {
+			return false
+		}
+		if va > vb {
+			better = true
+		}
+	}
+	return better
+}
+
+// ParetoFront returns the results no other result dominates, in their
+// original order. Results may come from different modes or configs.
+func ParetoFront(results []ExperimentResult, objectives []Objective) []ExperimentResult {
+	var front []ExperimentResult
+	for i, ok := range paretoMask(results, objectives) {
+		if ok {
+			front = append(front, results[i])
+		}
+	}
+	return front
+}
+
+// paretoMask reports for each result whether it is on the Pareto front.
+func paretoMask(results []ExperimentResult, objectives []Objective) []bool {
+	mask := make([]bool, len(results))
+	for i, a := range results {
+		mask[i] = true
+		for j, b := range results {
+			if i != j && Dominates(b, a, objectives) {
+				mask[i] = false
+				break
+			}
+		}
+	}
+	return mask
+}
+
+// WriteParetoTable writes one row per result with its scores, marking the
+// results on the Pareto front with an asterisk. Front results come first.
+func WriteParetoTable(w io.Writer, results []ExperimentResult, objectives []Objective) error {
+	onFront := paretoMask(results, objectives)
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return onFront[order[i]] && !onFront[order[j]] })
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "\tmode")
+	for _, o := range objectives {
+		dir := "↓"
+		if o.Maximize {
+			dir = "↑"
+		}
+		fmt.Fprintf(tw, "\t%s %s", o.Name, dir)
+	}
+	fmt.Fprintln(tw)
+	for _, i := range order {
+		mark := ""
+		if onFront[i] {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s", mark, results[i].Mode)
+		for _, o := range objectives {
+			if v, ok := results[i].Scores[o.Name]; ok {
+				fmt.Fprintf(tw, "\t%.4g", v)
+			} else {
+				fmt.Fprint(tw, "\t-")
+			}
+		}
+		fmt.Fprintln(tw)
+	}
+	return tw.Flush()
+}
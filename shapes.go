@@ -0,0 +1,140 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// parseModel decodes a model definition into loom's network configuration.
+func parseModel(raw json.RawMessage) (nn.NetworkConfig, error) {
+	var spec nn.NetworkConfig
+	if err := json.Unmarshal(raw, &spec); err != nil {
+		return spec, err
+	}
+	if spec.BatchSize == 0 {
+		spec.BatchSize = 1
+	}
+	return spec, nil
+}
+
+// modelInputSize infers the length of a model's input vector from its first layer.
+func modelInputSize(raw json.RawMessage) (int, error) {
+	spec, err := parseModel(raw)
+	if err != nil {
+		return 0, err
+	}
+	if len(spec.Layers) == 0 {
+		return 0, fmt.Errorf("model has no layers")
+	}
+	size := layerInputSize(spec.Layers[0], spec.BatchSize)
+	if size <= 0 {
+		return 0, fmt.Errorf("cannot infer input size from first layer of type %q", spec.Layers[0].Type)
+	}
+	return size, nil
+}
+
+// layerInputSize returns the number of input values a layer consumes, or 0 when
+// the layer type adopts whatever size it is given (softmax, residual).
+func layerInputSize(def nn.LayerDefinition, batch int) int {
+	seq := def.SeqLength
+	if seq <= 0 {
+		seq = 1
+	}
+	switch def.Type {
+	case "dense":
+		return batch * firstPositive(def.Width, def.InputSize, def.InputHeight)
+	case "swiglu":
+		return batch * firstPositive(def.InputSize, def.InputHeight)
+	case "rnn", "lstm":
+		return batch * seq * def.InputSize
+	case "mha", "multi_head_attention":
+		return batch * seq * def.DModel
+	case "conv2d":
+		return batch * def.InputChannels * def.InputHeight * def.InputWidth
+	case "layer_norm", "layernorm", "rms_norm", "rmsnorm":
+		return batch * def.NormSize
+	case "parallel":
+		// Every branch receives the full input.
+		for _, b := range def.Branches {
+			if size := layerInputSize(b, batch); size > 0 {
+				return size
+			}
+		}
+	}
+	return 0
+}
+
+func firstPositive(vals ...int) int {
+	for _, v := range vals {
+		if v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// layerSizes returns the length of every step-state buffer of a model,
+// indexed like a link's SourceLayer: 0 is the input and i the output of the
+// i-th layer. Sizes that can't be inferred are 0.
+func layerSizes(raw json.RawMessage) ([]int, error) {
+	spec, err := parseModel(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(spec.Layers) == 0 {
+		return nil, fmt.Errorf("model has no layers")
+	}
+	sizes := make([]int, len(spec.Layers)+1)
+	sizes[0] = layerInputSize(spec.Layers[0], spec.BatchSize)
+	for i, def := range spec.Layers {
+		sizes[i+1] = layerOutputSize(def, spec.BatchSize, sizes[i])
+	}
+	return sizes, nil
+}
+
+// layerOutputSize returns the number of values a layer produces from in
+// input values, or 0 when it can't be inferred.
+func layerOutputSize(def nn.LayerDefinition, batch, in int) int {
+	seq := max(def.SeqLength, 1)
+	switch def.Type {
+	case "dense":
+		return batch * firstPositive(def.OutputSize, def.Height)
+	case "swiglu":
+		// Projects back to its input width.
+		return in
+	case "rnn", "lstm":
+		return batch * seq * def.HiddenSize
+	case "mha", "multi_head_attention":
+		return batch * seq * def.DModel
+	case "conv2d":
+		stride := max(def.Stride, 1)
+		outH, outW := def.OutputHeight, def.OutputWidth
+		if outH == 0 {
+			outH = (def.InputHeight+2*def.Padding-def.KernelSize)/stride + 1
+		}
+		if outW == 0 {
+			outW = (def.InputWidth+2*def.Padding-def.KernelSize)/stride + 1
+		}
+		return batch * def.Filters * max(outH, 0) * max(outW, 0)
+	case "softmax", "residual", "layer_norm", "layernorm", "rms_norm", "rmsnorm":
+		return in
+	case "parallel":
+		total, widest := 0, 0
+		for _, b := range def.Branches {
+			size := layerOutputSize(b, batch, in)
+			if size == 0 {
+				return 0
+			}
+			total += size
+			widest = max(widest, size)
+		}
+		switch def.CombineMode {
+		case "add", "avg", "average":
+			return widest
+		}
+		return total
+	}
+	return 0
+}
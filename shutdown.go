@@ -0,0 +1,139 @@
+package drift
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RuntimeStats aggregates counters over the lifetime of a Runtime.
+type RuntimeStats struct {
+	Steps         uint64            `json:"steps"`
+	Uptime        time.Duration     `json:"uptime"`
+	LinkTransfers map[string]uint64 `json:"link_transfers"`
+	LinkCorrupted map[string]uint64 `json:"link_corrupted,omitempty"` // Links with payloads that failed their checksum
+}
+
+// Stats returns the runtime's aggregate counters.
+func (r *Runtime) Stats() RuntimeStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats()
+}
+
+// stats builds RuntimeStats. The caller holds r.mu.
+func (r *Runtime) stats() RuntimeStats {
+	s := RuntimeStats{
+		Steps:         r.steps,
+		Uptime:        time.Since(r.started),
+		LinkTransfers: make(map[string]uint64, len(r.links)),
+	}
+	for _, l := range r.links {
+		s.LinkTransfers[l.cfg.Name] = l.transfers
+		if l.corrupted > 0 {
+			if s.LinkCorrupted == nil {
+				s.LinkCorrupted = make(map[string]uint64)
+			}
+			s.LinkCorrupted[l.cfg.Name] = l.corrupted
+		}
+	}
+	return s
+}
+
+// AddSink registers a metric sink. Sinks are flushed on Shutdown.
+func (r *Runtime) AddSink(s MetricSink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks = append(r.sinks, s)
+}
+
+// AddFlusher registers a recorder or other buffered component to flush on Shutdown.
+func (r *Runtime) AddFlusher(f Flusher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushers = append(r.flushers, f)
+}
+
+// SetCheckpointPath sets where Shutdown writes the final checkpoint.
+// An empty path disables the final checkpoint.
+func (r *Runtime) SetCheckpointPath(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checkpointPath = path
+}
+
+// Record sends a metric stamped with the current step to every sink.
+func (r *Runtime) Record(name string, value float64, labels map[string]string) error {
+	r.mu.Lock()
+	sinks := append([]MetricSink(nil), r.sinks...)
+	m := Metric{Name: name, Value: value, Step: r.steps, Labels: labels, Time: time.Now()}
+	r.mu.Unlock()
+
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Record(m); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown ends the runtime's lifecycle. It waits for an in-flight step, stops
+// accepting new ones, flushes registered recorders and metric sinks, writes a
+// final checkpoint if a checkpoint path is set, and returns aggregate stats.
+// If ctx ends first, Shutdown returns ctx.Err() while the remaining work
+// continues in the background.
+func (r *Runtime) Shutdown(ctx context.Context) (RuntimeStats, error) {
+	type result struct {
+		stats RuntimeStats
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			done <- result{err: ErrRuntimeClosed}
+			return
+		}
+		r.closed = true
+		stats := r.stats()
+		flushers := append([]Flusher(nil), r.flushers...)
+		if r.recorder != nil {
+			flushers = append(flushers, r.recorder)
+		}
+		if r.tracer != nil {
+			flushers = append(flushers, r.tracer)
+		}
+		for _, s := range r.sinks {
+			flushers = append(flushers, s)
+		}
+		path := r.checkpointPath
+		var errs []error
+		if r.probeErr != nil {
+			errs = append(errs, r.probeErr)
+		}
+		r.mu.Unlock()
+
+		for _, f := range flushers {
+			if err := f.Flush(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if path != "" {
+			if err := r.SaveCheckpoint(path); err != nil {
+				errs = append(errs, fmt.Errorf("final checkpoint: %w", err))
+			}
+		}
+		done <- result{stats: stats, err: errors.Join(errs...)}
+	}()
+
+	select {
+	case res := <-done:
+		return res.stats, res.err
+	case <-ctx.Done():
+		return RuntimeStats{}, ctx.Err()
+	}
+}
@@ -0,0 +1,90 @@
+package drift
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// SignalOptions configures HandleSignals.
+type SignalOptions struct {
+	// ShutdownTimeout bounds the graceful shutdown (default 30 seconds).
+	ShutdownTimeout time.Duration
+	// OnShutdown receives the result of the shutdown triggered by SIGINT/SIGTERM.
+	OnShutdown func(RuntimeStats, error)
+	// Dump writes the current state on SIGUSR1. By default the runtime's
+	// stats are written to Output as JSON.
+	Dump func(w io.Writer)
+	// Output is where dumps are written (default os.Stderr).
+	Output io.Writer
+}
+
+// HandleSignals is an opt-in helper for unattended runs: SIGINT and SIGTERM
+// trigger a graceful r.Shutdown, and SIGUSR1 (on platforms that have it)
+// dumps state without stopping. The returned function stops signal handling;
+// calling it more than once is harmless.
+func HandleSignals(r *Runtime, opts SignalOptions) (stop func()) {
+	if opts.ShutdownTimeout <= 0 {
+		opts.ShutdownTimeout = 30 * time.Second
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stderr
+	}
+	if opts.Dump == nil {
+		opts.Dump = func(w io.Writer) {
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(r.Stats())
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, append([]os.Signal{os.Interrupt, syscall.SIGTERM}, dumpSignals()...)...)
+	quit := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-quit:
+				return
+			case sig := <-sigs:
+				if isDumpSignal(sig) {
+					opts.Dump(opts.Output)
+					continue
+				}
+				// Restore the default handling, so a second signal kills a
+				// shutdown that hangs.
+				signal.Stop(sigs)
+				ctx, cancel := context.WithTimeout(context.Background(), opts.ShutdownTimeout)
+				stats, err := r.Shutdown(ctx)
+				cancel()
+				if opts.OnShutdown != nil {
+					opts.OnShutdown(stats, err)
+				}
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(sigs)
+			close(quit)
+		})
+	}
+}
+
+func isDumpSignal(sig os.Signal) bool {
+	for _, s := range dumpSignals() {
+		if s == sig {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package drift
+
+import "os"
+
+// dumpSignals returns the signals that request a state dump. SIGUSR1 is not
+// available on this platform.
+func dumpSignals() []os.Signal {
+	return nil
+}
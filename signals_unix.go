@@ -0,0 +1,13 @@
+//go:build unix
+
+package drift
+
+import (
+	"os"
+	"syscall"
+)
+
+// dumpSignals returns the signals that request a state dump.
+func dumpSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR1}
+}
@@ -0,0 +1,120 @@
+package drift
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const signedConfigType = "drift/signed-config"
+
+// SignatureError is returned by LoadFromFileVerified when a config's
+// signature is missing or does not verify against the given key.
+type SignatureError struct {
+	Path   string
+	Reason string
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("%s: config signature mismatch: %s", e.Path, e.Reason)
+}
+
+// signedConfig is the envelope written by SaveToFileSigned. The signature is
+// an ed25519 signature of the config in compact JSON, so reindenting the
+// file keeps it valid while any other edit breaks it.
+type signedConfig struct {
+	Type      string          `json:"type"`
+	Algorithm string          `json:"algorithm"`
+	KeyID     string          `json:"key_id"` // Identifies the public key; see KeyID
+	Signature []byte          `json:"signature"`
+	Config    json.RawMessage `json:"config"`
+}
+
+// KeyID returns a short fingerprint of an ed25519 public key, as recorded in
+// signed configs.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// SaveToFileSigned saves the config to path wrapped in an envelope carrying
+// an ed25519 signature made with key. Load it with LoadFromFileVerified.
+func (c *Config) SaveToFileSigned(path string, key ed25519.PrivateKey) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("bad ed25519 private key length %d", len(key))
+	}
+	data, err := c.canonicalJSON()
+	if err != nil {
+		return err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, data); err != nil {
+		return err
+	}
+	env := signedConfig{
+		Type:      signedConfigType,
+		Algorithm: "ed25519",
+		KeyID:     KeyID(key.Public().(ed25519.PublicKey)),
+		Signature: ed25519.Sign(key, compact.Bytes()),
+		Config:    data,
+	}
+	out, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, append(out, '\n'), 0644, false)
+	})
+}
+
+// LoadFromFileVerified loads a config written by SaveToFileSigned, returning
+// a *SignatureError unless its signature verifies against key. The verified
+// config is then loaded like LoadFromFile, with ${var} expansion and DRIFT_
+// environment overrides; signed configs carry no includes.
+func LoadFromFileVerified(path string, key ed25519.PublicKey) (*Config, error) {
+	if len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("bad ed25519 public key length %d", len(key))
+	}
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var env signedConfig
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, err
+	}
+	if env.Type != signedConfigType || len(env.Config) == 0 {
+		return nil, &SignatureError{Path: path, Reason: "file is not a signed config"}
+	}
+	if env.Algorithm != "ed25519" {
+		return nil, &SignatureError{Path: path, Reason: fmt.Sprintf("unsupported algorithm %q", env.Algorithm)}
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, env.Config); err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(key, compact.Bytes(), env.Signature) {
+		reason := "signature does not match content"
+		if id := KeyID(key); env.KeyID != id {
+			reason = fmt.Sprintf("signed with key %s, verifying with %s", env.KeyID, id)
+		}
+		return nil, &SignatureError{Path: path, Reason: reason}
+	}
+	c, err := decodeConfig(env.Config, nil)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.ApplyEnvOverrides(os.Environ()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,147 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RuntimeSnapshot is a serializable view of a runtime between steps, for
+// dumping on error or inspecting in a debugger.
+type RuntimeSnapshot struct {
+	Step    uint64                   `json:"step"` // Completed steps
+	Time    time.Time                `json:"time"`
+	Order   []string                 `json:"order"`            // Model execution order
+	Halted  string                   `json:"halted,omitempty"` // Why a NumericGuard halted the runtime
+	Closed  bool                     `json:"closed,omitempty"`
+	Models  map[string]ModelSnapshot `json:"models"`
+	Links   []LinkSnapshot           `json:"links"`             // In config order
+	Pending []Intervention           `json:"pending,omitempty"` // Scenario interventions still to fire
+}
+
+// ModelSnapshot is the state of one model after the last step.
+type ModelSnapshot struct {
+	Input  Values `json:"input"`
+	Output Values `json:"output"`
+}
+
+// LinkSnapshot is the state of one link after the last step.
+type LinkSnapshot struct {
+	LinkStatus
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Payload  Values `json:"payload,omitempty"`  // Last captured payload
+	Code     Values `json:"code,omitempty"`     // Bottleneck values, for links with a codec
+	Injected Values `json:"injected,omitempty"` // Payload queued by InjectPayload
+	// Pending is set when a payload waits to be delivered on the next step:
+	// one that was injected, or carried by a link whose source runs after its
+	// target.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// Snapshot returns a deep copy of the runtime's current state.
+func (r *Runtime) Snapshot() *RuntimeSnapshot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.state()
+}
+
+// state builds a RuntimeSnapshot. The caller holds r.mu.
+func (r *Runtime) state() *RuntimeSnapshot {
+	s := &RuntimeSnapshot{
+		Step:   r.steps,
+		Time:   time.Now(),
+		Order:  append([]string(nil), r.order...),
+		Closed: r.closed,
+		Models: make(map[string]ModelSnapshot, len(r.models)),
+		Links:  make([]LinkSnapshot, len(r.links)),
+	}
+	if r.halted != nil {
+		s.Halted = r.halted.Error()
+	}
+	position := make(map[string]int, len(r.order))
+	for i, name := range r.order {
+		position[name] = i
+	}
+	for name, m := range r.models {
+		s.Models[name] = ModelSnapshot{
+			Input:  append([]float32(nil), m.input...),
+			Output: append([]float32(nil), m.output...),
+		}
+	}
+	for i, l := range r.links {
+		carried := l.cfg.Enabled && l.delivered && l.payload != nil &&
+			position[l.cfg.SourceModel] >= position[l.cfg.TargetModel]
+		s.Links[i] = LinkSnapshot{
+			LinkStatus: l.status(),
+			Source:     l.cfg.SourceModel,
+			Target:     l.cfg.TargetModel,
+			Payload:    append([]float32(nil), l.payload...),
+			Code:       append([]float32(nil), l.code...),
+			Injected:   append([]float32(nil), l.injected...),
+			Pending:    l.injected != nil || carried,
+		}
+	}
+	s.Pending = append(s.Pending, r.scenario[r.nextAction:]...)
+	return s
+}
+
+// Values is a float32 slice that survives JSON encoding when it holds NaN or
+// Inf, which are written as the strings "NaN", "+Inf" and "-Inf". A snapshot
+// taken when a NumericGuard trips is the one most worth keeping.
+type Values []float32
+
+// MarshalJSON implements json.Marshaler.
+func (v Values) MarshalJSON() ([]byte, error) {
+	if v == nil {
+		return []byte("null"), nil
+	}
+	b := []byte{'['}
+	for i, x := range v {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		switch f := float64(x); {
+		case math.IsNaN(f):
+			b = append(b, `"NaN"`...)
+		case math.IsInf(f, 1):
+			b = append(b, `"+Inf"`...)
+		case math.IsInf(f, -1):
+			b = append(b, `"-Inf"`...)
+		default:
+			b = strconv.AppendFloat(b, f, 'g', -1, 32)
+		}
+	}
+	return append(b, ']'), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (v *Values) UnmarshalJSON(data []byte) error {
+	var raw []any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if raw == nil {
+		*v = nil
+		return nil
+	}
+	out := make(Values, len(raw))
+	for i, x := range raw {
+		switch x := x.(type) {
+		case float64:
+			out[i] = float32(x)
+		case string:
+			f, err := strconv.ParseFloat(x, 32)
+			if err != nil || !(math.IsNaN(f) || math.IsInf(f, 0)) {
+				return fmt.Errorf("invalid value %q", x)
+			}
+			out[i] = float32(f)
+		default:
+			return fmt.Errorf("invalid value %v", x)
+		}
+	}
+	*v = out
+	return nil
+}
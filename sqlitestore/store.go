@@ -0,0 +1,325 @@
+// Package sqlitestore keeps drift metrics in a SQLite file, so long
+// multi-run studies can be queried without loading dozens of JSON files:
+//
+//	store, err := sqlitestore.Open("study.db", "linked-seed-3")
+//	...
+//	rt.AddSink(store)
+//	store.RecordResult(result)
+//	...
+//	rows, err := store.Query("window_accuracy_pct", sqlitestore.Filter{
+//		Labels: map[string]string{"mode": "linked"},
+//	})
+//
+// It is a separate package so that programs not using it don't link SQLite.
+package sqlitestore
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfluke/drift"
+	_ "modernc.org/sqlite"
+)
+
+// batchSize is the number of metrics written per transaction between
+// flushes.
+const batchSize = 1000
+
+const schema = `
+CREATE TABLE IF NOT EXISTS metrics (
+	run    TEXT    NOT NULL,
+	name   TEXT    NOT NULL,
+	step   INTEGER NOT NULL,
+	value  REAL,
+	labels TEXT    NOT NULL DEFAULT '{}',
+	time   TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS metrics_name ON metrics (name, run, step);
+`
+
+// Store is a drift.MetricSink writing every metric, tagged with a run name,
+// to a SQLite database. Metrics are written in batches; Flush commits the
+// current one. It is safe for concurrent use.
+type Store struct {
+	mu      sync.Mutex
+	db      *sql.DB
+	run     string
+	tx      *sql.Tx
+	insert  *sql.Stmt
+	pending int
+}
+
+// Open opens or creates the database at path and records into it under
+// run. Several runs, or several processes one after another, can share a
+// database.
+func Open(path, run string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// Writes go through one connection; SQLite serializes them anyway.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Store{db: db, run: run}, nil
+}
+
+// Run returns the name metrics are recorded under.
+func (s *Store) Run() string {
+	return s.run
+}
+
+// Record implements drift.MetricSink.
+func (s *Store) Record(m drift.Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.record(m)
+}
+
+// record writes m in the current batch. The caller holds s.mu.
+func (s *Store) record(m drift.Metric) error {
+	if s.tx == nil {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return err
+		}
+		insert, err := tx.Prepare(`INSERT INTO metrics (run, name, step, value, labels, time) VALUES (?, ?, ?, ?, ?, ?)`)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		s.tx, s.insert = tx, insert
+	}
+	labels := []byte("{}")
+	if len(m.Labels) > 0 {
+		var err error
+		if labels, err = json.Marshal(m.Labels); err != nil {
+			return err
+		}
+	}
+	if m.Time.IsZero() {
+		m.Time = time.Now()
+	}
+	// Steps are unsigned; SQLite integers are signed 64-bit.
+	if _, err := s.insert.Exec(s.run, m.Name, int64(m.Step), m.Value, string(labels), m.Time.UTC().Format(time.RFC3339Nano)); err != nil {
+		return err
+	}
+	if s.pending++; s.pending >= batchSize {
+		return s.commit()
+	}
+	return nil
+}
+
+// commit commits the current batch. The caller holds s.mu.
+func (s *Store) commit() error {
+	if s.tx == nil {
+		return nil
+	}
+	s.insert.Close()
+	err := s.tx.Commit()
+	s.tx, s.insert, s.pending = nil, nil, 0
+	return err
+}
+
+// Flush implements drift.MetricSink, committing everything recorded so far.
+func (s *Store) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.commit()
+}
+
+// Close flushes and closes the database.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.commit()
+	if cerr := s.db.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// recordAll writes metrics in one batch.
+func (s *Store) recordAll(ms []drift.Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for _, m := range ms {
+		if m.Time.IsZero() {
+			m.Time = now
+		}
+		if err := s.record(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordResult records every window of a benchmark result, as the metrics
+// of drift.WindowMetrics.Metrics, and its final accuracy as
+// final_accuracy_pct labeled with the mode.
+func (s *Store) RecordResult(res drift.ExperimentResult) error {
+	var ms []drift.Metric
+	for _, w := range res.Windows {
+		ms = append(ms, w.Metrics(res.Mode)...)
+	}
+	ms = append(ms, drift.Metric{Name: "final_accuracy_pct", Value: res.FinalAccuracy, Labels: map[string]string{"mode": res.Mode}})
+	return s.recordAll(ms)
+}
+
+// RecordEpisode records values measured over one episode, e.g. reward or
+// success, each as a metric stamped with the episode number and labeled
+// with labels.
+func (s *Store) RecordEpisode(episode int, values map[string]float64, labels map[string]string) error {
+	ms := make([]drift.Metric, 0, len(values))
+	for name, v := range values {
+		ms = append(ms, drift.Metric{Name: name, Value: v, Step: uint64(episode), Labels: labels})
+	}
+	return s.recordAll(ms)
+}
+
+// RecordLinks records the state of every link at step, as link_transfers,
+// link_dropped, link_corrupted, link_gain and link_delivery labeled with the
+// link, e.g. from Runtime.Links.
+func (s *Store) RecordLinks(step uint64, links []drift.LinkStatus) error {
+	var ms []drift.Metric
+	for _, l := range links {
+		labels := map[string]string{"link": l.Name}
+		ms = append(ms,
+			drift.Metric{Name: "link_transfers", Value: float64(l.Transfers), Step: step, Labels: labels},
+			drift.Metric{Name: "link_dropped", Value: float64(l.Dropped), Step: step, Labels: labels},
+			drift.Metric{Name: "link_corrupted", Value: float64(l.Corrupted), Step: step, Labels: labels},
+			drift.Metric{Name: "link_gain", Value: float64(l.Gain), Step: step, Labels: labels},
+		)
+		if l.Delivery > 0 {
+			ms = append(ms, drift.Metric{Name: "link_delivery", Value: l.Delivery, Step: step, Labels: labels})
+		}
+	}
+	return s.recordAll(ms)
+}
+
+// Filter narrows a Query.
+type Filter struct {
+	Runs     []string          // Only these runs; all when empty
+	Labels   map[string]string // Labels the metric must carry, e.g. {"mode": "linked"}
+	FromStep uint64            // First step, inclusive
+	ToStep   uint64            // Last step, inclusive; 0 for no bound
+}
+
+// Row is a metric as stored, with the run it belongs to.
+type Row struct {
+	Run string `json:"run"`
+	drift.Metric
+}
+
+// Query returns the metrics called name that match f, ordered by run, step
+// and insertion. Unflushed metrics of this store are included.
+func (s *Store) Query(name string, f Filter) ([]Row, error) {
+	where := []string{"name = ?"}
+	args := []any{name}
+	if len(f.Runs) > 0 {
+		where = append(where, "run IN (?"+strings.Repeat(", ?", len(f.Runs)-1)+")")
+		for _, r := range f.Runs {
+			args = append(args, r)
+		}
+	}
+	for _, k := range sortedKeys(f.Labels) {
+		where = append(where, "json_extract(labels, ?) = ?")
+		args = append(args, `$."`+strings.ReplaceAll(k, `"`, `\"`)+`"`, f.Labels[k])
+	}
+	if f.FromStep > 0 {
+		where = append(where, "step >= ?")
+		args = append(args, int64(f.FromStep))
+	}
+	if f.ToStep > 0 {
+		where = append(where, "step <= ?")
+		args = append(args, int64(f.ToStep))
+	}
+	q := "SELECT run, name, step, value, labels, time FROM metrics WHERE " + strings.Join(where, " AND ") + " ORDER BY run, step, rowid"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows *sql.Rows
+	var err error
+	if s.tx != nil {
+		rows, err = s.tx.Query(q, args...)
+	} else {
+		rows, err = s.db.Query(q, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Row
+	for rows.Next() {
+		var r Row
+		var step int64
+		var value sql.NullFloat64
+		var labels, ts string
+		if err := rows.Scan(&r.Run, &r.Name, &step, &value, &labels, &ts); err != nil {
+			return nil, err
+		}
+		r.Step, r.Value = uint64(step), value.Float64
+		if labels != "{}" {
+			if err := json.Unmarshal([]byte(labels), &r.Labels); err != nil {
+				return nil, fmt.Errorf("labels of %q: %w", r.Name, err)
+			}
+		}
+		if r.Time, err = time.Parse(time.RFC3339Nano, ts); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// Runs returns the sorted names of the runs in the database.
+func (s *Store) Runs() ([]string, error) {
+	return s.strings("SELECT DISTINCT run FROM metrics ORDER BY run")
+}
+
+// Names returns the sorted names of the metrics in the database.
+func (s *Store) Names() ([]string, error) {
+	return s.strings("SELECT DISTINCT name FROM metrics ORDER BY name")
+}
+
+func (s *Store) strings(q string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var rows *sql.Rows
+	var err error
+	if s.tx != nil {
+		rows, err = s.tx.Query(q)
+	} else {
+		rows, err = s.db.Query(q)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package drift
+
+import (
+	"math"
+)
+
+// maxGate bounds delivery logits, keeping learned probabilities within
+// about 0.25% of 0 and 1 so a gate can always recover.
+const maxGate = 6
+
+// Stochastic links model unreliable channels, such as lossy radio or noisy
+// synapses, as part of the system rather than as injected faults. A link
+// with a Delivery probability delivers each payload with that probability;
+// a dropped payload leaves the target region zero, as if the link were off.
+// With LearnDelivery set, the probability becomes a gate trained by
+// RewardDelivery.
+
+// deliveryLogit converts a configured delivery probability to a gate logit.
+func deliveryLogit(p float64) float64 {
+	if p == 0 {
+		p = 1
+	}
+	return clampGate(math.Log(p / (1 - p)))
+}
+
+func clampGate(g float64) float64 {
+	return math.Max(-maxGate, math.Min(maxGate, g))
+}
+
+// stochastic reports whether deliveries on the link are random.
+func (l *runtimeLink) stochastic() bool {
+	return l.cfg.LearnDelivery || (l.cfg.Delivery > 0 && l.cfg.Delivery < 1)
+}
+
+// deliveryProb returns the link's current delivery probability.
+func (l *runtimeLink) deliveryProb() float64 {
+	if !l.stochastic() {
+		return 1
+	}
+	return 1 / (1 + math.Exp(-l.gate))
+}
+
+// deliver decides whether the payload just captured reaches the target,
+// accumulating the gate's gradient for learned links.
+func (l *runtimeLink) deliver() bool {
+	l.delivered = true
+	if !l.stochastic() {
+		return true
+	}
+	p := l.deliveryProb()
+	l.delivered = l.dropRand.Float64() < p
+	if l.cfg.LearnDelivery {
+		d := 0.0
+		if l.delivered {
+			d = 1
+		}
+		l.trace += d - p
+	}
+	return l.delivered
+}
+
+// RewardDelivery trains the delivery gates of links with LearnDelivery on
+// reward, the outcome of the steps since the previous call, by a REINFORCE
+// update with learning rate lr against a running mean of past rewards:
+// deliveries are made more likely when they preceded better than usual
+// outcomes, and less likely otherwise.
+func (r *Runtime) RewardDelivery(reward, lr float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.rewarded {
+		r.rewardBase, r.rewarded = reward, true
+	}
+	adv := reward - r.rewardBase
+	r.rewardBase += 0.1 * adv
+	for _, l := range r.links {
+		if l.cfg.LearnDelivery {
+			l.gate = clampGate(l.gate + lr*adv*l.trace)
+			l.trace = 0
+		}
+	}
+}
+
+// LinkDelivery returns the current delivery probability of a link, 1 for
+// links that always deliver, or 0 if the link doesn't exist.
+func (r *Runtime) LinkDelivery(name string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if l := r.link(name); l != nil {
+		return l.deliveryProb()
+	}
+	return 0
+}
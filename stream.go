@@ -0,0 +1,116 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Encoding is a serialization format for configs.
+type Encoding string
+
+const (
+	EncodingJSON  Encoding = "json"
+	EncodingYAML  Encoding = "yaml"
+	EncodingTOML  Encoding = "toml"
+	EncodingCBOR  Encoding = "cbor"  // The binary form; see ToBinary
+	EncodingProto Encoding = "proto" // Protobuf; see MarshalProto
+)
+
+// EncodingFor returns the encoding a file name's extension implies, JSON
+// for anything but .yaml, .yml, .toml, .cbor and .pb. A ".gz" suffix is ignored.
+func EncodingFor(name string) Encoding {
+	name = strings.ToLower(name)
+	if isGzipPath(name) {
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml":
+		return EncodingYAML
+	case ".toml":
+		return EncodingTOML
+	case ".cbor":
+		return EncodingCBOR
+	case ".pb":
+		return EncodingProto
+	}
+	return EncodingJSON
+}
+
+// Load reads a JSON config from r, e.g. an HTTP body or an object storage
+// download, migrating it from an older schema version if needed. Unlike
+// LoadFromFile it applies no DRIFT_ environment overrides, and a config
+// with includes is rejected, as there is no file to resolve them against.
+func Load(r io.Reader) (*Config, error) {
+	return Decode(r, EncodingJSON)
+}
+
+// Decode reads a config in encoding enc from r, like Load.
+func Decode(r io.Reader, enc Encoding) (*Config, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch enc {
+	case EncodingJSON, "":
+		if isEncryptedConfig(data) {
+			return nil, ErrConfigEncrypted
+		}
+	case EncodingYAML:
+		data, err = yamlToJSON(data)
+	case EncodingTOML:
+		data, err = tomlToJSON(data)
+	case EncodingCBOR:
+		return FromBinary(data)
+	case EncodingProto:
+		return UnmarshalProto(data)
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", enc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var doc struct {
+		Includes json.RawMessage `json:"includes"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Includes != nil {
+		return nil, fmt.Errorf("config includes other files; load it with LoadFromFile")
+	}
+	return decodeConfig(data, nil)
+}
+
+// Write writes the config to w as indented JSON, exactly as SaveToFile
+// writes it to a file.
+func (c *Config) Write(w io.Writer) error {
+	return c.Encode(w, EncodingJSON)
+}
+
+// Encode writes the config to w in encoding enc.
+func (c *Config) Encode(w io.Writer, enc Encoding) error {
+	var data []byte
+	var err error
+	switch enc {
+	case EncodingJSON, "":
+		data, err = json.MarshalIndent(c, "", "  ")
+	case EncodingYAML:
+		data, err = c.yaml()
+	case EncodingTOML:
+		data, err = c.toml()
+	case EncodingCBOR:
+		data, err = c.ToBinary()
+	case EncodingProto:
+		data, err = c.MarshalProto()
+	default:
+		return fmt.Errorf("unknown encoding %q", enc)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,215 @@
+package drift
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// Table is a flat, column-oriented view of recorded data in the shape
+// dataframe libraries load directly: CSV via pandas.read_csv or
+// polars.read_csv, NDJSON via polars.read_ndjson.
+type Table struct {
+	Columns []string
+	Rows    [][]any
+}
+
+// ReadMetrics loads every Metric from a ResultLog used as a metric sink.
+// Lines that are not metrics, such as window records, are skipped.
+func ReadMetrics(path string) ([]Metric, error) {
+	var metrics []Metric
+	err := ReadResultLog(path, func(line json.RawMessage) error {
+		var m Metric
+		if err := json.Unmarshal(line, &m); err != nil {
+			return err
+		}
+		if m.Name != "" {
+			metrics = append(metrics, m)
+		}
+		return nil
+	})
+	return metrics, err
+}
+
+// MetricsTable has one row per metric with columns step, time, name, value
+// and one label_<key> column per label key seen.
+func MetricsTable(metrics []Metric) *Table {
+	keys := map[string]bool{}
+	for _, m := range metrics {
+		for k := range m.Labels {
+			keys[k] = true
+		}
+	}
+	labels := sortedKeys(keys)
+
+	t := &Table{Columns: []string{"step", "time", "name", "value"}}
+	for _, k := range labels {
+		t.Columns = append(t.Columns, "label_"+k)
+	}
+	for _, m := range metrics {
+		row := []any{m.Step, m.Time.Format(time.RFC3339Nano), m.Name, m.Value}
+		for _, k := range labels {
+			row = append(row, m.Labels[k])
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// TrajectoryTable flattens a recording into long format with one row per
+// value and columns step, kind (input, output, link or layer), name, layer,
+// index and value. Layer is 0 except for layer outputs.
+func TrajectoryTable(steps []StepRecord) *Table {
+	t := &Table{Columns: []string{"step", "kind", "name", "layer", "index", "value"}}
+	add := func(step uint64, kind string, values map[string][]float32) {
+		for _, name := range sortedKeys(values) {
+			for i, v := range values[name] {
+				t.Rows = append(t.Rows, []any{step, kind, name, 0, i, v})
+			}
+		}
+	}
+	for _, s := range steps {
+		add(s.Step, "input", s.Inputs)
+		add(s.Step, "output", s.Outputs)
+		add(s.Step, "link", s.Links)
+		for _, name := range sortedKeys(s.Layers) {
+			for l, out := range s.Layers[name] {
+				for i, v := range out {
+					t.Rows = append(t.Rows, []any{s.Step, "layer", name, l + 1, i, v})
+				}
+			}
+		}
+	}
+	return t
+}
+
+// LinkTable has one row per step carrying a payload on link, with columns
+// step and v0..vN, ready for clustering or embedding.
+func LinkTable(steps []StepRecord, link string) *Table {
+	width := 0
+	for _, s := range steps {
+		if n := len(s.Links[link]); n > width {
+			width = n
+		}
+	}
+	t := &Table{Columns: []string{"step"}}
+	for i := 0; i < width; i++ {
+		t.Columns = append(t.Columns, "v"+strconv.Itoa(i))
+	}
+	for _, s := range steps {
+		p := s.Links[link]
+		if len(p) == 0 {
+			continue
+		}
+		row := make([]any, 1+width)
+		row[0] = s.Step
+		for i := 0; i < width; i++ {
+			if i < len(p) {
+				row[1+i] = p[i]
+			} else {
+				row[1+i] = float32(0)
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// WriteCSV writes the table with a header row.
+func (t *Table) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(t.Columns); err != nil {
+		return err
+	}
+	rec := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i := range rec {
+			rec[i] = ""
+			if i < len(row) {
+				rec[i] = formatCell(row[i])
+			}
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteNDJSON writes one JSON object per row, keyed by column name.
+func (t *Table) WriteNDJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	obj := make(map[string]any, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, c := range t.Columns {
+			obj[c] = nil
+			if i < len(row) {
+				obj[c] = row[i]
+			}
+		}
+		if err := enc.Encode(obj); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportRun writes a recording and its metrics as CSV files in dir:
+// trajectory.csv, links/<name>.csv and, when metrics is non-empty,
+// metrics.csv. A notebook then loads any of them with a single read_csv.
+func ExportRun(dir string, steps []StepRecord, metrics []Metric) error {
+	if err := os.MkdirAll(filepath.Join(dir, "links"), 0755); err != nil {
+		return err
+	}
+	if err := writeTableFile(filepath.Join(dir, "trajectory.csv"), TrajectoryTable(steps)); err != nil {
+		return err
+	}
+	links := map[string]bool{}
+	for _, s := range steps {
+		for name := range s.Links {
+			links[name] = true
+		}
+	}
+	for _, name := range sortedKeys(links) {
+		if err := writeTableFile(filepath.Join(dir, "links", name+".csv"), LinkTable(steps, name)); err != nil {
+			return err
+		}
+	}
+	if len(metrics) > 0 {
+		return writeTableFile(filepath.Join(dir, "metrics.csv"), MetricsTable(metrics))
+	}
+	return nil
+}
+
+func writeTableFile(path string, t *Table) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := t.WriteCSV(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func formatCell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float32:
+		return strconv.FormatFloat(float64(x), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'g', -1, 64)
+	default:
+		return fmt.Sprint(x)
+	}
+}
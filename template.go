@@ -0,0 +1,83 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// varRef matches a ${name} reference in a config string.
+var varRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_.]*)\}`)
+
+// expandVariables substitutes ${name} references throughout doc and removes
+// its "variables" section, reporting whether doc changed. A string that is
+// exactly one reference takes the variable's value with its type, so
+// "link_size": "${size}" becomes a number; references inside longer strings
+// are interpolated as text. Values in vars override the document's own.
+// Referencing an undefined variable is an error.
+func expandVariables(doc map[string]any, vars map[string]any) (bool, error) {
+	section, hasSection := doc["variables"]
+	defined, ok := section.(map[string]any)
+	if hasSection && !ok && section != nil {
+		return false, fmt.Errorf("variables: must be an object")
+	}
+	merged := make(map[string]any, len(defined)+len(vars))
+	for k, v := range defined {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+	delete(doc, "variables")
+
+	undefined := make(map[string]bool)
+	changed := hasSection
+	var expand func(v any) any
+	expand = func(v any) any {
+		switch t := v.(type) {
+		case map[string]any:
+			for k, e := range t {
+				t[k] = expand(e)
+			}
+		case []any:
+			for i, e := range t {
+				t[i] = expand(e)
+			}
+		case string:
+			if !strings.Contains(t, "${") {
+				return t
+			}
+			if m := varRef.FindStringSubmatch(t); m != nil && m[0] == t {
+				val, ok := merged[m[1]]
+				if !ok {
+					undefined[m[1]] = true
+					return t
+				}
+				changed = true
+				return val
+			}
+			return varRef.ReplaceAllStringFunc(t, func(ref string) string {
+				name := ref[2 : len(ref)-1]
+				val, ok := merged[name]
+				if !ok {
+					undefined[name] = true
+					return ref
+				}
+				changed = true
+				if s, ok := val.(string); ok {
+					return s
+				}
+				data, _ := json.Marshal(val)
+				return string(data)
+			})
+		}
+		return v
+	}
+	expand(doc)
+
+	if len(undefined) > 0 {
+		return false, fmt.Errorf("undefined variables %s", strings.Join(sortedKeys(undefined), ", "))
+	}
+	return changed, nil
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/openfluke/drift"
+	"github.com/openfluke/drift/presets"
 	"github.com/openfluke/loom/nn"
 )
 
@@ -64,26 +65,6 @@ type Environment struct {
 	IceVelY    float32
 }
 
-// WindowMetrics tracks performance in 500ms windows
-type WindowMetrics struct {
-	WindowNum      int     `json:"window"`
-	Terrain        string  `json:"terrain"`
-	TargetsReached int     `json:"targets"`
-	TotalSteps     int     `json:"steps"`
-	EffectiveMoves int     `json:"effective_moves"`
-	Accuracy       float64 `json:"accuracy_pct"`
-}
-
-// ExperimentResult holds results for one training mode
-type ExperimentResult struct {
-	Mode           string          `json:"mode"`
-	Windows        []WindowMetrics `json:"windows"`
-	TotalTargets   int             `json:"total_targets"`
-	TotalSteps     int             `json:"total_steps"`
-	FinalAccuracy  float64         `json:"final_accuracy_pct"`
-	TerrainResults map[string]int  `json:"targets_by_terrain"`
-}
-
 func main() {
 	rand.Seed(time.Now().UnixNano())
 
@@ -96,7 +77,7 @@ func main() {
 	// ========================================
 	// Create DRIFT Configuration
 	// ========================================
-	cfg := createDriftConfig()
+	cfg := presets.MultiTerrainNeuralLink()
 
 	// Save and reload config
 	cfg.SaveToFile("drift_config.json")
@@ -142,6 +123,7 @@ func main() {
 	// ========================================
 	fmt.Println()
 	fmt.Println("═══ PHASE 2: Training Navigators (ROAD ONLY) ═══")
+	drift.RegisterOracle("multi_terrain", "greedy", drift.PolicyFunc(greedyAction))
 	for i, nav := range navigators {
 		trainNavigatorRoadOnly(nav, linkConfig.LinkSize, 3*time.Second)
 		fmt.Printf("  Navigator %d: trained (road only)\n", i+1)
@@ -163,14 +145,30 @@ func main() {
 		"LSTM + Neural Link + RL",
 	}
 
-	results := make([]ExperimentResult, 4)
+	results := make([]drift.ExperimentResult, 4)
 	testDuration := 14 * time.Second // 2 seconds per terrain
 
+	// Windows are appended as they complete so a crash doesn't lose the run.
+	// The log keeps earlier runs, so every record is stamped with this one.
+	windowLog, err := drift.OpenResultLog("benchmark_windows.jsonl")
+	if err != nil {
+		log.Fatalf("Failed to open window log: %v", err)
+	}
+	defer windowLog.Close()
+	started := time.Now().UTC()
+	run := fmt.Sprintf("%s-%d", started.Format("20060102T150405"), os.Getpid())
+	logWindow := func(mode string, w drift.WindowMetrics) {
+		rec := drift.WindowRecord{Run: run, Started: started, Mode: mode, WindowMetrics: w}
+		if err := windowLog.Append(rec); err != nil {
+			log.Printf("Failed to append window: %v", err)
+		}
+	}
+
 	for i, mode := range modes {
 		fmt.Printf("Running Mode %d: %s...\n", i+1, mode)
 		useRL := (i == 1 || i == 3)
 		useLink := (i == 2 || i == 3)
-		results[i] = runBenchmark(classifier, navigators[i], linkConfig, mode, useLink, useRL, testDuration)
+		results[i] = runBenchmark(classifier, navigators[i], linkConfig, mode, useLink, useRL, testDuration, logWindow)
 		fmt.Printf("  → %d targets, %.1f%% accuracy\n", results[i].TotalTargets, results[i].FinalAccuracy)
 	}
 
@@ -186,101 +184,6 @@ func main() {
 	os.Remove("drift_config.json")
 }
 
-// ============================================================================
-// DRIFT Configuration
-// ============================================================================
-
-func createDriftConfig() *drift.Config {
-	cfg := drift.NewConfig("MultiTerrainNeuralLink")
-
-	// Classifier: 8 sensors → 4 terrain classes
-	// Hidden layer (index 1) outputs 16 neurons as neural link
-	// FIXED: Use softmax for multi-class classification (not sigmoid!)
-	classifierDef := json.RawMessage(`{
-		"batch_size": 1,
-		"grid_rows": 1,
-		"grid_cols": 1,
-		"layers_per_cell": 4,
-		"layers": [
-			{"type": "dense", "input_size": 8, "output_size": 32, "activation": "leaky_relu"},
-			{"type": "dense", "input_size": 32, "output_size": 16, "activation": "leaky_relu"},
-			{"type": "dense", "input_size": 16, "output_size": 4, "activation": "none"},
-			{"type": "softmax", "softmax_variant": "standard"}
-		]
-	}`)
-
-	// Navigator with CORRECT parallel architecture:
-	// IMPORTANT: Both branches receive the SAME FULL input (20 features)
-	//
-	// Input (20) = position(4) + neural_link(16)
-	//      ↓
-	// ┌─────────────────────────────────────────┐
-	// │          PARALLEL LAYER                 │
-	// │  Branch 1:          Branch 2:           │
-	// │  Dense(20→16)       Dense(20→4)         │
-	// │  (navigation)       + Softmax           │
-	// │      ↓                  ↓               │
-	// └─────────────────────────────────────────┘
-	//              concat(16+4=20)
-	//                    ↓
-	//              Dense(20→32)
-	//                    ↓
-	//               LSTM(32→16)
-	//                    ↓
-	//              Dense(16→4)
-	//
-	navigatorDef := json.RawMessage(`{
-		"batch_size": 1,
-		"grid_rows": 1,
-		"grid_cols": 1,
-		"layers_per_cell": 5,
-		"layers": [
-			{
-				"type": "parallel",
-				"combine_mode": "concat",
-				"comment": "Both branches get full input(20)",
-				"branches": [
-					{
-						"type": "dense",
-						"input_size": 20,
-						"output_size": 16,
-						"activation": "leaky_relu",
-						"comment": "Navigation features from full input"
-					},
-					{
-						"type": "dense",
-						"input_size": 20,
-						"output_size": 4,
-						"activation": "none",
-						"comment": "Terrain logits (for softmax)"
-					}
-				]
-			},
-			{"type": "softmax", "softmax_variant": "grid", "softmax_rows": 5, "softmax_cols": 4, "temperature": 1.0, "comment": "Apply softmax to concat(16+4)=20"},
-			{"type": "dense", "input_size": 20, "output_size": 32, "activation": "leaky_relu", "comment": "Combine features"},
-			{"type": "lstm", "input_size": 32, "hidden_size": 16, "seq_length": 1, "comment": "Temporal reasoning"},
-			{"type": "dense", "input_size": 16, "output_size": 4, "activation": "sigmoid", "comment": "Action output"}
-		]
-	}`)
-
-	cfg.Models["classifier"] = classifierDef
-	cfg.Models["navigator"] = navigatorDef
-
-	// Neural link configuration - now targets the second parallel branch
-	cfg.AddLink(drift.NeuralLinkConfig{
-		Name:         "terrain_to_nav",
-		SourceModel:  "classifier",
-		SourceLayer:  1,
-		TargetModel:  "navigator",
-		TargetOffset: 4, // After position (4), into neural link branch
-		LinkSize:     16,
-		Enabled:      true,
-		Description:  "Classifier hidden → Navigator parallel branch for terrain awareness",
-	})
-
-	return cfg
-}
-
 // ============================================================================
 // Training
 // ============================================================================
@@ -373,6 +276,8 @@ func trainNavigatorRoadOnly(net *nn.Network, linkSize int, duration time.Duratio
 	tween := nn.NewTweenState(net, nil)
 	tween.Config.UseChainRule = true
 
+	oracle, _ := drift.GetOracle("multi_terrain", "greedy")
+
 	lr := float32(0.02)
 	start := time.Now()
 
@@ -389,7 +294,7 @@ func trainNavigatorRoadOnly(net *nn.Network, linkSize int, duration time.Duratio
 		output := state.GetOutput()
 
 		predicted := argmax(output)
-		optimal := getOptimalAction(env)
+		optimal := oracle.Act(navInput)
 		tween.TweenStep(net, navInput, optimal, NumActions, lr)
 
 		executeAction(env, predicted)
@@ -404,7 +309,7 @@ func trainNavigatorRoadOnly(net *nn.Network, linkSize int, duration time.Duratio
 // ============================================================================
 
 func runBenchmark(classifier, navigator *nn.Network, linkConfig drift.NeuralLinkConfig,
-	modeName string, useLink, useRL bool, duration time.Duration) ExperimentResult {
+	modeName string, useLink, useRL bool, duration time.Duration, logWindow func(string, drift.WindowMetrics)) drift.ExperimentResult {
 
 	inputSize := 4 + linkConfig.LinkSize
 	classifierState := classifier.InitStepState(8)
@@ -423,25 +328,15 @@ func runBenchmark(classifier, navigator *nn.Network, linkConfig drift.NeuralLink
 		LastAction: -1,
 	}
 
-	windowDuration := 500 * time.Millisecond
 	terrainDuration := duration / time.Duration(len(terrainSequence))
 
-	result := ExperimentResult{
-		Mode:           modeName,
-		Windows:        []WindowMetrics{},
-		TerrainResults: make(map[string]int),
-	}
+	tracker := drift.NewWindowTracker(modeName, drift.DefaultWindow)
+	tracker.OnWindow = func(w drift.WindowMetrics) { logWindow(modeName, w) }
 
 	start := time.Now()
-	lastWindow := start
-	windowNum := 0
 	currentTerrainIdx := 0
 	lastTerrainChange := start
 
-	windowTargets := 0
-	windowSteps := 0
-	windowEffective := 0
-
 	lr := float32(0.01)
 
 	for time.Since(start) < duration {
@@ -475,13 +370,7 @@ func runBenchmark(classifier, navigator *nn.Network, linkConfig drift.NeuralLink
 		prevDist := distanceToTarget(env)
 		executeActionWithPhysics(env, action)
 		newDist := distanceToTarget(env)
-		windowSteps++
-		result.TotalSteps++
-
 		gotCloser := newDist < prevDist-0.001
-		if gotCloser {
-			windowEffective++
-		}
 
 		// RL update if enabled
 		if useRL && tween != nil {
@@ -494,11 +383,9 @@ func runBenchmark(classifier, navigator *nn.Network, linkConfig drift.NeuralLink
 		}
 
 		// Check if reached target
-		if newDist < 0.1 {
-			windowTargets++
-			result.TotalTargets++
-			result.TerrainResults[terrainNames[env.Terrain]]++
-
+		reached := newDist < 0.1
+		tracker.Observe(terrainNames[env.Terrain], gotCloser, reached)
+		if reached {
 			// Reset to new position
 			env.AgentPos = [2]float32{rand.Float32() * 0.3, rand.Float32() * 0.3}
 			env.TargetPos = [2]float32{0.7 + rand.Float32()*0.3, 0.7 + rand.Float32()*0.3}
@@ -507,43 +394,9 @@ func runBenchmark(classifier, navigator *nn.Network, linkConfig drift.NeuralLink
 			env.IceVelX = 0
 			env.IceVelY = 0
 		}
-
-		// Record window metrics every 500ms
-		if time.Since(lastWindow) >= windowDuration {
-			accuracy := 0.0
-			if windowSteps > 0 {
-				accuracy = float64(windowEffective) / float64(windowSteps) * 100
-			}
-
-			result.Windows = append(result.Windows, WindowMetrics{
-				WindowNum:      windowNum,
-				Terrain:        terrainNames[env.Terrain],
-				TargetsReached: windowTargets,
-				TotalSteps:     windowSteps,
-				EffectiveMoves: windowEffective,
-				Accuracy:       accuracy,
-			})
-
-			windowNum++
-			windowTargets = 0
-			windowSteps = 0
-			windowEffective = 0
-			lastWindow = time.Now()
-		}
 	}
 
-	// Final accuracy
-	totalEffective := 0
-	totalSteps := 0
-	for _, w := range result.Windows {
-		totalEffective += w.EffectiveMoves
-		totalSteps += w.TotalSteps
-	}
-	if totalSteps > 0 {
-		result.FinalAccuracy = float64(totalEffective) / float64(totalSteps) * 100
-	}
-
-	return result
+	return tracker.Result()
 }
 
 // ============================================================================
@@ -615,9 +468,10 @@ func getHiddenActivations(state *nn.StepState, layerIdx, linkSize int) []float32
 // Actions & Physics
 // ============================================================================
 
-func getOptimalAction(env *Environment) int {
-	dx := env.TargetPos[0] - env.AgentPos[0]
-	dy := env.TargetPos[1] - env.AgentPos[1]
+// greedyAction is the oracle heuristic: step along the dominant axis of the
+// normalized target direction stored in the first two observation values.
+func greedyAction(obs []float32) int {
+	dx, dy := obs[0], obs[1]
 	if abs(dx) > abs(dy) {
 		if dx > 0 {
 			return ActionRight
@@ -713,7 +567,7 @@ func distanceToTarget(env *Environment) float32 {
 // Results Display
 // ============================================================================
 
-func printResults(results []ExperimentResult) {
+func printResults(results []drift.ExperimentResult) {
 	fmt.Println("╔══════════════════════════════════════════════════════════════════════════════════════════════════╗")
 	fmt.Println("║                               MULTI-TERRAIN BENCHMARK RESULTS                                   ║")
 	fmt.Println("╠══════════════════════════════════════════════════════════════════════════════════════════════════╣")
@@ -752,9 +606,15 @@ func printResults(results []ExperimentResult) {
 		}
 	}
 	fmt.Println("└────────┴─────────┴─────────┴──────────┴────────────┘")
+
+	fmt.Println()
+	fmt.Printf("Recovery after terrain changes (%s, within %d%%):\n", best.Mode, drift.DefaultRecoveryTolerance)
+	for _, t := range drift.AdaptationSpeed(best.Windows, drift.DefaultRecoveryTolerance) {
+		fmt.Println("  " + t.String())
+	}
 }
 
-func saveResultsJSON(results []ExperimentResult) {
+func saveResultsJSON(results []drift.ExperimentResult) {
 	data := map[string]interface{}{
 		"experiment":       "multi_terrain_neural_link",
 		"terrain_sequence": []string{"Road", "Sand", "Road", "Grass", "Road", "Ice", "Road"},
@@ -0,0 +1,150 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// ToTOML serializes the config to a TOML string. Links become an array of
+// [[links]] tables and model definitions nested tables under [models], so
+// loom layers are written natively rather than as embedded JSON. TOML has no
+// null, so null JSON fields are left out, decoding back to the same zero
+// values, and nulls in arrays are written as empty tables, which keeps the
+// other elements in place and decodes back to null.
+func (c *Config) ToTOML() (string, error) {
+	data, err := c.toml()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FromTOML deserializes a TOML string into a Config.
+func FromTOML(data string) (*Config, error) {
+	return parseTOML([]byte(data))
+}
+
+// SaveToTOML saves the config to a TOML file, atomically and under the same
+// advisory lock as SaveToFile.
+func (c *Config) SaveToTOML(path string) error {
+	data, err := c.toml()
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, data, 0644, false)
+	})
+}
+
+// LoadFromTOML loads a config from a TOML file, holding a shared advisory
+// lock while reading.
+func LoadFromTOML(path string) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseTOML(data)
+}
+
+// toml renders c through its JSON form, like yaml.
+func (c *Config) toml() ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := toml.NewEncoder(&buf)
+	enc.SetIndentTables(true)
+	if err := enc.Encode(tomlValue(doc)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseTOML decodes a TOML document through the JSON form.
+func parseTOML(data []byte) (*Config, error) {
+	raw, err := tomlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(raw, nil)
+}
+
+// tomlToJSON converts a TOML document to JSON.
+func tomlToJSON(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(jsonValue(doc))
+	if err != nil {
+		return nil, fmt.Errorf("toml: %w", err)
+	}
+	return raw, nil
+}
+
+// tomlValue converts a decoded JSON value to one TOML can encode: numbers
+// become int64 or float64, nulls are dropped from objects and become empty
+// tables in arrays.
+func tomlValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			if e == nil {
+				delete(t, k)
+				continue
+			}
+			t[k] = tomlValue(e)
+		}
+	case []any:
+		for i, e := range t {
+			if e == nil {
+				t[i] = map[string]any{}
+				continue
+			}
+			t[i] = tomlValue(e)
+		}
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
+
+// jsonValue undoes tomlValue on a decoded TOML value, turning empty tables in
+// arrays back into nulls.
+func jsonValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, e := range t {
+			t[k] = jsonValue(e)
+		}
+	case []any:
+		for i, e := range t {
+			if m, ok := e.(map[string]any); ok && len(m) == 0 {
+				t[i] = nil
+				continue
+			}
+			t[i] = jsonValue(e)
+		}
+	}
+	return v
+}
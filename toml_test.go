@@ -0,0 +1,57 @@
+package drift
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestTOMLRoundTrip(t *testing.T) {
+	cfg, err := LoadFromFile("tests/test01/drift_config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := cfg.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := cfg.ToTOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := FromTOML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := back.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TOML sorts the keys of model definitions, so compare values.
+	var gotDoc, wantDoc any
+	if err := json.Unmarshal([]byte(got), &gotDoc); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(want), &wantDoc); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(gotDoc, wantDoc) {
+		t.Errorf("JSON → TOML → JSON changed the config:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestTOMLKeepsNullsInArrays(t *testing.T) {
+	cfg := NewConfig("nulls")
+	cfg.Models["m"] = json.RawMessage(`{"mask":[1,null,2],"rows":[[null],{"a":null,"b":1}]}`)
+	data, err := cfg.ToTOML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := FromTOML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(back.Models["m"]), `{"mask":[1,null,2],"rows":[[null],{"b":1}]}`; got != want {
+		t.Errorf("model = %s, want %s", got, want)
+	}
+}
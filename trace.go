@@ -0,0 +1,116 @@
+package drift
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// Tracer keeps snapshots of a runtime's last steps in a ring buffer, so that
+// when a model misbehaves the payloads that flowed through each link in the
+// preceding steps can be reconstructed. A snapshot is taken after every step,
+// including a failed one, and the whole trace is written to Dump as JSON
+// when a step fails.
+type Tracer struct {
+	Dump io.Writer // Receives the trace when a step fails; may be nil
+
+	mu   sync.Mutex
+	ring []*RuntimeSnapshot
+	next int // Slot the next snapshot goes to
+	full bool
+	err  error
+}
+
+// NewTracer creates a tracer that keeps the last size snapshots and writes
+// them to dump (which may be nil) when a step fails.
+func NewTracer(size int, dump io.Writer) *Tracer {
+	if size < 1 {
+		size = 1
+	}
+	return &Tracer{Dump: dump, ring: make([]*RuntimeSnapshot, size)}
+}
+
+// SetTracer attaches t to the runtime. The tracer is flushed on Shutdown.
+// Passing nil detaches the current tracer.
+func (r *Runtime) SetTracer(t *Tracer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tracer = t
+}
+
+// trace snapshots the step just run, which failed with err if it isn't nil.
+// The caller holds r.mu.
+func (r *Runtime) trace(err error) {
+	t := r.tracer
+	if t == nil {
+		return
+	}
+	t.add(r.state())
+	if err != nil && err != errRolledBack {
+		t.dump()
+	}
+}
+
+func (t *Tracer) add(s *RuntimeSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ring[t.next] = s
+	t.next = (t.next + 1) % len(t.ring)
+	if t.next == 0 {
+		t.full = true
+	}
+}
+
+// Snapshots returns the retained snapshots, oldest first.
+func (t *Tracer) Snapshots() []*RuntimeSnapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.full {
+		return append([]*RuntimeSnapshot(nil), t.ring[:t.next]...)
+	}
+	return append(append([]*RuntimeSnapshot(nil), t.ring[t.next:]...), t.ring[:t.next]...)
+}
+
+// Reset discards the retained snapshots.
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	clear(t.ring)
+	t.next, t.full = 0, false
+}
+
+// WriteJSON writes the retained snapshots to w as a JSON array, oldest first.
+func (t *Tracer) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t.Snapshots())
+}
+
+// SaveToFile writes the retained snapshots to path as a JSON array.
+func (t *Tracer) SaveToFile(path string) error {
+	data, err := json.MarshalIndent(t.Snapshots(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0644, false)
+}
+
+// dump writes the trace to Dump, keeping the first error for Flush.
+func (t *Tracer) dump() {
+	if t.Dump == nil {
+		return
+	}
+	err := t.WriteJSON(t.Dump)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+// Flush reports the first error met while dumping the trace.
+func (t *Tracer) Flush() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
@@ -0,0 +1,368 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metricKey flattens a metric name and its labels into a single tracking key,
+// e.g. window_accuracy_pct/mode=linked/terrain=road.
+func metricKey(m Metric) string {
+	if len(m.Labels) == 0 {
+		return m.Name
+	}
+	keys := make([]string, 0, len(m.Labels))
+	for k := range m.Labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteString(m.Name)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "/%s=%s", k, m.Labels[k])
+	}
+	return b.String()
+}
+
+// postJSON sends v as JSON and decodes a JSON response into out, if non-nil.
+func postJSON(client *http.Client, method, url string, v, out any, auth func(*http.Request)) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if auth != nil {
+		auth(req)
+	}
+	return doRequest(client, req, out)
+}
+
+func doRequest(client *http.Client, req *http.Request, out any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+// MLflowSink logs a run to an MLflow tracking server through its REST API.
+// Metrics are buffered and sent in batches on Flush; Close marks the run
+// finished. The drift run ID is used as the MLflow run name and stored in
+// the drift.run_id tag.
+type MLflowSink struct {
+	TrackingURI  string // e.g. http://localhost:5000
+	ExperimentID string // Defaults to "0", MLflow's default experiment
+	RunID        string
+	Token        string // Optional bearer token
+	Client       *http.Client
+
+	mu       sync.Mutex
+	mlflowID string
+	pending  []Metric
+}
+
+// NewMLflowSink creates an MLflow run named runID in experimentID.
+func NewMLflowSink(trackingURI, experimentID, runID string) (*MLflowSink, error) {
+	s := &MLflowSink{TrackingURI: strings.TrimRight(trackingURI, "/"), ExperimentID: experimentID, RunID: runID}
+	if s.ExperimentID == "" {
+		s.ExperimentID = "0"
+	}
+	var resp struct {
+		Run struct {
+			Info struct {
+				RunID string `json:"run_id"`
+			} `json:"info"`
+		} `json:"run"`
+	}
+	err := s.call("runs/create", map[string]any{
+		"experiment_id": s.ExperimentID,
+		"run_name":      runID,
+		"start_time":    time.Now().UnixMilli(),
+		"tags":          []map[string]string{{"key": "drift.run_id", "value": runID}},
+	}, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("mlflow: create run: %w", err)
+	}
+	s.mlflowID = resp.Run.Info.RunID
+	return s, nil
+}
+
+func (s *MLflowSink) call(endpoint string, v, out any) error {
+	return postJSON(s.Client, http.MethodPost, s.TrackingURI+"/api/2.0/mlflow/"+endpoint, v, out, s.auth)
+}
+
+func (s *MLflowSink) auth(req *http.Request) {
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+}
+
+// Record implements MetricSink.
+func (s *MLflowSink) Record(m Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, m)
+	return nil
+}
+
+// Flush implements MetricSink, sending buffered metrics in batches of up to
+// 1000, MLflow's per-request limit.
+func (s *MLflowSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.pending) > 0 {
+		n := min(len(s.pending), 1000)
+		batch := make([]map[string]any, n)
+		for i, m := range s.pending[:n] {
+			ts := m.Time
+			if ts.IsZero() {
+				ts = time.Now()
+			}
+			batch[i] = map[string]any{"key": metricKey(m), "value": m.Value, "timestamp": ts.UnixMilli(), "step": m.Step}
+		}
+		if err := s.call("runs/log-batch", map[string]any{"run_id": s.mlflowID, "metrics": batch}, nil); err != nil {
+			return fmt.Errorf("mlflow: log metrics: %w", err)
+		}
+		s.pending = s.pending[n:]
+	}
+	return nil
+}
+
+// LogConfig records the config's name, seed and links as run parameters and
+// uploads the full config as the config.json artifact.
+func (s *MLflowSink) LogConfig(cfg *Config) error {
+	params := []map[string]string{{"key": "config.name", "value": cfg.Name}}
+	if cfg.Seed != 0 {
+		params = append(params, map[string]string{"key": "config.seed", "value": fmt.Sprint(cfg.Seed)})
+	}
+	for _, l := range cfg.Links {
+		params = append(params, map[string]string{
+			"key":   "link." + l.Name,
+			"value": fmt.Sprintf("%s[%d]->%s@%d size=%d enabled=%v", l.SourceModel, l.SourceLayer, l.TargetModel, l.TargetOffset, l.LinkSize, l.Enabled),
+		})
+	}
+	if err := s.call("runs/log-batch", map[string]any{"run_id": s.mlflowID, "params": params}, nil); err != nil {
+		return fmt.Errorf("mlflow: log params: %w", err)
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.upload("config.json", data)
+}
+
+// LogArtifact uploads the file at path, such as a checkpoint, through the
+// tracking server's artifact proxy.
+func (s *MLflowSink) LogArtifact(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return s.upload(filepath.Base(path), data)
+}
+
+func (s *MLflowSink) upload(name string, data []byte) error {
+	url := fmt.Sprintf("%s/api/2.0/mlflow-artifacts/artifacts/%s/%s/artifacts/%s", s.TrackingURI, s.ExperimentID, s.mlflowID, name)
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	s.auth(req)
+	if err := doRequest(s.Client, req, nil); err != nil {
+		return fmt.Errorf("mlflow: upload %s: %w", name, err)
+	}
+	return nil
+}
+
+// Close flushes pending metrics and marks the run finished.
+func (s *MLflowSink) Close() error {
+	err := s.Flush()
+	status := "FINISHED"
+	if err != nil {
+		status = "FAILED"
+	}
+	uerr := s.call("runs/update", map[string]any{"run_id": s.mlflowID, "status": status, "end_time": time.Now().UnixMilli()}, nil)
+	if uerr != nil {
+		uerr = fmt.Errorf("mlflow: finish run: %w", uerr)
+	}
+	return errors.Join(err, uerr)
+}
+
+// WandbSink logs a run to Weights & Biases through its HTTP API. The drift
+// run ID is used as the W&B run ID, so resuming a run appends to it. Metrics
+// are buffered and streamed as history rows on Flush, one row per step.
+type WandbSink struct {
+	BaseURL string // Defaults to $WANDB_BASE_URL, then https://api.wandb.ai
+	APIKey  string
+	Entity  string
+	Project string
+	RunID   string
+	Client  *http.Client
+
+	mu      sync.Mutex
+	pending []Metric
+	offset  int
+}
+
+// NewWandbSink creates or resumes the W&B run runID in entity/project.
+func NewWandbSink(apiKey, entity, project, runID string) (*WandbSink, error) {
+	s := &WandbSink{BaseURL: "https://api.wandb.ai", APIKey: apiKey, Entity: entity, Project: project, RunID: runID}
+	if base := os.Getenv("WANDB_BASE_URL"); base != "" {
+		s.BaseURL = strings.TrimRight(base, "/")
+	}
+	if err := s.upsert(nil); err != nil {
+		return nil, fmt.Errorf("wandb: create run: %w", err)
+	}
+	return s, nil
+}
+
+func (s *WandbSink) auth(req *http.Request) {
+	req.SetBasicAuth("api", s.APIKey)
+}
+
+const wandbUpsert = `mutation UpsertBucket($name: String, $project: String, $entity: String, $config: JSONString) {
+  upsertBucket(input: {name: $name, modelName: $project, entityName: $entity, config: $config}) { bucket { id } }
+}`
+
+// upsert creates the run, or updates its config when config is non-nil.
+func (s *WandbSink) upsert(config map[string]any) error {
+	vars := map[string]any{"name": s.RunID, "project": s.Project, "entity": s.Entity}
+	if config != nil {
+		data, err := json.Marshal(config)
+		if err != nil {
+			return err
+		}
+		vars["config"] = string(data)
+	}
+	var resp struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	err := postJSON(s.Client, http.MethodPost, s.BaseURL+"/graphql", map[string]any{"query": wandbUpsert, "variables": vars}, &resp, s.auth)
+	if err == nil && len(resp.Errors) > 0 {
+		err = errors.New(resp.Errors[0].Message)
+	}
+	return err
+}
+
+// stream posts to the run's file stream endpoint.
+func (s *WandbSink) stream(body map[string]any) error {
+	url := fmt.Sprintf("%s/files/%s/%s/%s/file_stream", s.BaseURL, s.Entity, s.Project, s.RunID)
+	return postJSON(s.Client, http.MethodPost, url, body, nil, s.auth)
+}
+
+// Record implements MetricSink.
+func (s *WandbSink) Record(m Metric) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, m)
+	return nil
+}
+
+// Flush implements MetricSink.
+func (s *WandbSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+	rows := map[uint64]map[string]any{}
+	var steps []uint64
+	for _, m := range s.pending {
+		row, ok := rows[m.Step]
+		if !ok {
+			row = map[string]any{"_step": m.Step}
+			rows[m.Step] = row
+			steps = append(steps, m.Step)
+		}
+		ts := m.Time
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		row["_timestamp"] = float64(ts.UnixNano()) / 1e9
+		row[metricKey(m)] = m.Value
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i] < steps[j] })
+	content := make([]string, len(steps))
+	for i, step := range steps {
+		data, err := json.Marshal(rows[step])
+		if err != nil {
+			return err
+		}
+		content[i] = string(data)
+	}
+	err := s.stream(map[string]any{"files": map[string]any{
+		"wandb-history.jsonl": map[string]any{"offset": s.offset, "content": content},
+	}})
+	if err != nil {
+		return fmt.Errorf("wandb: log metrics: %w", err)
+	}
+	s.offset += len(content)
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// LogConfig stores the config as the run's W&B config.
+func (s *WandbSink) LogConfig(cfg *Config) error {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	config := make(map[string]any, len(m))
+	for k, v := range m {
+		config[k] = map[string]any{"value": v}
+	}
+	if err := s.upsert(config); err != nil {
+		return fmt.Errorf("wandb: log config: %w", err)
+	}
+	return nil
+}
+
+// LogArtifact is not supported: W&B artifact uploads go through signed
+// storage URLs that require its client library.
+func (s *WandbSink) LogArtifact(path string) error {
+	return fmt.Errorf("wandb: upload %s: %w", filepath.Base(path), errors.ErrUnsupported)
+}
+
+// Close flushes pending metrics and marks the run complete.
+func (s *WandbSink) Close() error {
+	err := s.Flush()
+	exit := 0
+	if err != nil {
+		exit = 1
+	}
+	if serr := s.stream(map[string]any{"complete": true, "exitcode": exit}); serr != nil {
+		err = errors.Join(err, fmt.Errorf("wandb: finish run: %w", serr))
+	}
+	return err
+}
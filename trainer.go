@@ -0,0 +1,259 @@
+package drift
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// TrainingPhase is one stage of a freeze-thaw schedule: the models listed
+// in Train learn for the phase's length while every other model is frozen.
+type TrainingPhase struct {
+	Name     string   `json:"name"`
+	Train    []string `json:"train"`              // Models updated during the phase
+	Steps    uint64   `json:"steps,omitempty"`    // Length in trainer iterations
+	Duration string   `json:"duration,omitempty"` // Length in wall time, e.g. "30s"; used when Steps is 0
+	LR       float32  `json:"lr,omitempty"`       // Learning rate; defaults to 0.05
+
+	// CoTrain names a link whose source and target models both learn from
+	// the trainer's Reward during the phase, splitting credit by
+	// SourceCredit and TargetCredit (both default to 0.5).
+	CoTrain      string  `json:"co_train,omitempty"`
+	SourceCredit float64 `json:"source_credit,omitempty"`
+	TargetCredit float64 `json:"target_credit,omitempty"`
+}
+
+// Lesson produces one supervised example for a model: the input to train on
+// and the target class. It may read the runtime, e.g. to include the latest
+// link payload in the input.
+type Lesson func(r *Runtime) (input []float32, target int)
+
+// Trainer executes a training schedule against a runtime's networks with
+// chain-rule tween updates.
+type Trainer struct {
+	Runtime *Runtime
+	// Advance, if set, runs once per iteration before any lesson, typically
+	// stepping the environment and the runtime.
+	Advance func(r *Runtime) error
+	// Monitors, keyed by model, wrap that model's updates; see TrainingMonitor.
+	Monitors map[string]*TrainingMonitor
+	// OnPhase, if set, is called as each phase starts.
+	OnPhase func(TrainingPhase)
+	// Reward returns the task reward for the latest step. It is required by
+	// co-training phases.
+	Reward func(r *Runtime) float64
+	// BaselineRate is the EMA rate of the reward baseline that co-training
+	// advantages are measured against; defaults to 0.01.
+	BaselineRate float64
+
+	lessons  map[string]Lesson
+	tweens   map[string]*modelTween
+	classes  map[string]int
+	baseline float64
+}
+
+// modelTween is the tween state of the network it was created for.
+type modelTween struct {
+	net *nn.Network
+	ts  *nn.TweenState
+}
+
+// NewTrainer creates a trainer for r.
+func NewTrainer(r *Runtime) *Trainer {
+	return &Trainer{
+		Runtime:  r,
+		Monitors: make(map[string]*TrainingMonitor),
+		lessons:  make(map[string]Lesson),
+		tweens:   make(map[string]*modelTween),
+		classes:  make(map[string]int),
+	}
+}
+
+// Teach sets the lesson a model learns from when it is trainable.
+func (t *Trainer) Teach(model string, l Lesson) {
+	t.lessons[model] = l
+}
+
+// Run executes phases in order, or the config's training schedule when
+// phases is nil.
+func (t *Trainer) Run(ctx context.Context, phases []TrainingPhase) error {
+	if phases == nil {
+		phases = t.Runtime.Config().Training
+	}
+	for _, p := range phases {
+		if err := t.RunPhase(ctx, p); err != nil {
+			return fmt.Errorf("phase %q: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// RunPhase executes a single phase.
+func (t *Trainer) RunPhase(ctx context.Context, p TrainingPhase) error {
+	steps, d, err := p.Length()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(d)
+	for _, model := range p.Train {
+		if t.Runtime.Network(model) == nil {
+			return fmt.Errorf("model %q not found", model)
+		}
+		if t.lessons[model] == nil {
+			return fmt.Errorf("model %q has no lesson", model)
+		}
+	}
+	var co *linkEnds
+	if p.CoTrain != "" {
+		ends, err := t.endsOf(p.CoTrain)
+		if err != nil {
+			return err
+		}
+		if t.Reward == nil {
+			return fmt.Errorf("co-training needs a Reward function")
+		}
+		co = ends
+	}
+	lr := p.LR
+	if lr == 0 {
+		lr = 0.05
+	}
+	if t.OnPhase != nil {
+		t.OnPhase(p)
+	}
+
+	for i := uint64(0); steps == 0 || i < steps; i++ {
+		if steps == 0 && !time.Now().Before(deadline) {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if t.Advance != nil {
+			if err := t.Advance(t.Runtime); err != nil {
+				return err
+			}
+		}
+		for _, model := range p.Train {
+			input, target := t.lessons[model](t.Runtime)
+			t.update(model, input, target, lr)
+		}
+		if co != nil {
+			t.shareReward(co, t.Reward(t.Runtime), p.SourceCredit, p.TargetCredit, lr)
+		}
+	}
+	return nil
+}
+
+// Length returns the phase's length as a number of iterations or, when
+// Steps is 0, as a wall-clock duration.
+func (p TrainingPhase) Length() (uint64, time.Duration, error) {
+	if p.Steps > 0 {
+		return p.Steps, 0, nil
+	}
+	d, err := time.ParseDuration(p.Duration)
+	if err != nil || d <= 0 {
+		return 0, 0, fmt.Errorf("phase needs steps or a positive duration")
+	}
+	return 0, d, nil
+}
+
+// linkEnds names the models at either end of a link.
+type linkEnds struct {
+	source, target string
+}
+
+func (t *Trainer) endsOf(link string) (*linkEnds, error) {
+	cfg := t.Runtime.Config()
+	l, ok := cfg.GetLink(link)
+	if !ok {
+		return nil, fmt.Errorf("link %q not found", link)
+	}
+	l = cfg.resolveLink(l)
+	return &linkEnds{source: l.SourceModel, target: l.TargetModel}, nil
+}
+
+// ShareReward gives both ends of a link the same learning signal from a task
+// reward. The advantage over a running baseline is split between the models
+// by the credit weights (0.5 each when both are zero); each model reinforces
+// the decision it made on the latest step when the advantage is positive and
+// shifts toward its runner-up decision when it is negative.
+func (t *Trainer) ShareReward(link string, reward, sourceCredit, targetCredit float64, lr float32) error {
+	ends, err := t.endsOf(link)
+	if err != nil {
+		return err
+	}
+	t.shareReward(ends, reward, sourceCredit, targetCredit, lr)
+	return nil
+}
+
+func (t *Trainer) shareReward(ends *linkEnds, reward, sourceCredit, targetCredit float64, lr float32) {
+	if sourceCredit == 0 && targetCredit == 0 {
+		sourceCredit, targetCredit = 0.5, 0.5
+	}
+	rate := t.BaselineRate
+	if rate <= 0 {
+		rate = 0.01
+	}
+	adv := reward - t.baseline
+	t.baseline += rate * adv
+
+	for _, end := range []struct {
+		model  string
+		credit float64
+	}{{ends.source, sourceCredit}, {ends.target, targetCredit}} {
+		step := float32(math.Abs(adv)*end.credit) * lr
+		if step == 0 {
+			continue
+		}
+		input, output := t.Runtime.Input(end.model), t.Runtime.Output(end.model)
+		if len(output) == 0 {
+			continue
+		}
+		target := Argmax(output)
+		if adv < 0 {
+			target = runnerUp(output, target)
+		}
+		t.update(end.model, input, target, step)
+	}
+}
+
+// runnerUp returns the index of the largest value other than best.
+func runnerUp(v []float32, best int) int {
+	idx := -1
+	for i, x := range v {
+		if i != best && (idx < 0 || x > v[idx]) {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return best
+	}
+	return idx
+}
+
+// update applies one tween step to model.
+func (t *Trainer) update(model string, input []float32, target int, lr float32) {
+	net := t.Runtime.Network(model)
+	mt := t.tweens[model]
+	if mt == nil || mt.net != net {
+		mt = &modelTween{net: net, ts: nn.NewTweenState(net, nil)}
+		mt.ts.Config.UseChainRule = true
+		mt.ts.Config.ExplosionDetection = false
+		t.tweens[model] = mt
+	}
+	ts := mt.ts
+	classes, ok := t.classes[model]
+	if !ok {
+		classes = len(ts.ForwardPass(net, input))
+		t.classes[model] = classes
+	}
+	if m := t.Monitors[model]; m != nil {
+		m.TweenStep(net, ts, input, target, classes, lr)
+		return
+	}
+	ts.TweenStep(net, input, target, classes, lr)
+}
@@ -0,0 +1,173 @@
+package drift
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// TrainingWindow summarizes a window of online training updates of one model.
+type TrainingWindow struct {
+	Model       string  `json:"model"`
+	Updates     int     `json:"updates"`
+	GradNorm    float64 `json:"grad_norm"`     // Mean L2 norm of the backpropagated gradients
+	MaxGradNorm float64 `json:"max_grad_norm"` // Largest gradient norm in the window
+	UpdateNorm  float64 `json:"update_norm"`   // Mean L2 norm of the weight change per update
+	WeightNorm  float64 `json:"weight_norm"`   // L2 norm of all weights at the end of the window
+	Clipped     int     `json:"clipped"`       // Updates scaled down by ClipNorm
+}
+
+// TrainingMonitor wraps the tween updates of one model, measuring gradient
+// norms and weight-update magnitudes and optionally clipping updates. Every
+// Window updates it reports a TrainingWindow to OnWindow and to Sink as
+// train_grad_norm, train_update_norm, train_weight_norm and train_clipped
+// metrics labeled with the model.
+type TrainingMonitor struct {
+	Model    string
+	Window   int                  // Updates per window; defaults to 100
+	ClipNorm float64              // If positive, updates with a larger L2 norm are scaled down to it
+	Sink     MetricSink           // Optional
+	OnWindow func(TrainingWindow) // Optional
+
+	updates uint64
+	cur     TrainingWindow
+	before  [][]float32
+	err     error
+}
+
+// NewTrainingMonitor creates a monitor for model reporting every window
+// updates to sink, which may be nil.
+func NewTrainingMonitor(model string, window int, sink MetricSink) *TrainingMonitor {
+	return &TrainingMonitor{Model: model, Window: window, Sink: sink}
+}
+
+// TweenStep performs ts.TweenStep on net and records the update. It returns
+// the step's loss.
+func (m *TrainingMonitor) TweenStep(net *nn.Network, ts *nn.TweenState, input []float32, targetClass, outputSize int, lr float32) float32 {
+	params := paramSlices(net)
+	m.before = m.before[:0]
+	for _, p := range params {
+		m.before = append(m.before, append([]float32(nil), p...))
+	}
+
+	loss := ts.TweenStep(net, input, targetClass, outputSize, lr)
+
+	var grad float64
+	for _, g := range ts.ChainGradients {
+		grad += sumSquares(g)
+	}
+	grad = math.Sqrt(grad)
+
+	var upd float64
+	for i, p := range params {
+		for j, v := range p {
+			d := float64(v - m.before[i][j])
+			upd += d * d
+		}
+	}
+	upd = math.Sqrt(upd)
+
+	if m.ClipNorm > 0 && upd > m.ClipNorm {
+		scale := float32(m.ClipNorm / upd)
+		for i, p := range params {
+			for j := range p {
+				old := m.before[i][j]
+				p[j] = old + (p[j]-old)*scale
+			}
+		}
+		upd = m.ClipNorm
+		m.cur.Clipped++
+	}
+
+	m.cur.Updates++
+	m.cur.GradNorm += grad
+	m.cur.UpdateNorm += upd
+	if grad > m.cur.MaxGradNorm {
+		m.cur.MaxGradNorm = grad
+	}
+	m.updates++
+
+	window := m.Window
+	if window <= 0 {
+		window = 100
+	}
+	if m.cur.Updates >= window {
+		m.closeWindow(params)
+	}
+	return loss
+}
+
+// closeWindow finalizes the current window and reports it.
+func (m *TrainingMonitor) closeWindow(params [][]float32) {
+	w := m.cur
+	w.Model = m.Model
+	w.GradNorm /= float64(w.Updates)
+	w.UpdateNorm /= float64(w.Updates)
+	for _, p := range params {
+		w.WeightNorm += sumSquares(p)
+	}
+	w.WeightNorm = math.Sqrt(w.WeightNorm)
+	m.cur = TrainingWindow{}
+
+	if m.OnWindow != nil {
+		m.OnWindow(w)
+	}
+	if m.Sink != nil {
+		labels := map[string]string{"model": m.Model}
+		now := time.Now()
+		for _, metric := range []Metric{
+			{Name: "train_grad_norm", Value: w.GradNorm},
+			{Name: "train_update_norm", Value: w.UpdateNorm},
+			{Name: "train_weight_norm", Value: w.WeightNorm},
+			{Name: "train_clipped", Value: float64(w.Clipped)},
+		} {
+			metric.Step, metric.Labels, metric.Time = m.updates, labels, now
+			if err := m.Sink.Record(metric); err != nil && m.err == nil {
+				m.err = err
+			}
+		}
+	}
+}
+
+// Flush flushes the sink, reporting the first error encountered while
+// recording to it.
+func (m *TrainingMonitor) Flush() error {
+	if m.err != nil {
+		return m.err
+	}
+	if m.Sink != nil {
+		return m.Sink.Flush()
+	}
+	return nil
+}
+
+// paramSlices returns every parameter slice of net's layers, parallel
+// branches included. The slices alias the network's weights.
+func paramSlices(net *nn.Network) [][]float32 {
+	var out [][]float32
+	var walk func(layers []nn.LayerConfig)
+	floatSlice := reflect.TypeOf([]float32(nil))
+	walk = func(layers []nn.LayerConfig) {
+		for i := range layers {
+			v := reflect.ValueOf(&layers[i]).Elem()
+			for f := 0; f < v.NumField(); f++ {
+				if field := v.Field(f); field.CanInterface() && field.Type() == floatSlice && field.Len() > 0 {
+					out = append(out, field.Interface().([]float32))
+				}
+			}
+			walk(layers[i].ParallelBranches)
+		}
+	}
+	walk(net.Layers)
+	return out
+}
+
+func sumSquares(s []float32) float64 {
+	var sum float64
+	for _, v := range s {
+		sum += float64(v) * float64(v)
+	}
+	return sum
+}
@@ -0,0 +1,109 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+)
+
+// Transform types for TransformSpec.
+const (
+	TransformMinMax = "minmax" // Rescale the payload's own min..max onto [Min, Max]; default [0, 1]
+	TransformZScore = "zscore" // Subtract the payload's mean and divide by its standard deviation
+	TransformScale  = "scale"  // Multiply by Factor, then add Offset
+	TransformClip   = "clip"   // Clamp to [Min, Max]
+)
+
+// TransformSpec is one stage of a link's transform pipeline, which reshapes
+// each payload before it reaches the target, e.g. so that raw hidden
+// activations don't swamp the inputs next to them:
+//
+//	"transforms": [
+//	  {"type": "zscore"},
+//	  {"type": "scale", "factor": 0.25},
+//	  {"type": "clip", "min": -1, "max": 1}
+//	]
+//
+// Statistics are those of the payload being transformed, so stages hold no
+// state between steps.
+type TransformSpec struct {
+	Type   string  `json:"type"`             // One of the Transform constants
+	Min    float64 `json:"min,omitempty"`    // Lower bound, for minmax and clip
+	Max    float64 `json:"max,omitempty"`    // Upper bound, for minmax and clip
+	Factor float64 `json:"factor,omitempty"` // For scale; 0 means 1
+	Offset float64 `json:"offset,omitempty"` // For scale
+}
+
+// check describes what is wrong with the stage, or returns "".
+func (t *TransformSpec) check() string {
+	switch t.Type {
+	case TransformZScore, TransformScale:
+	case TransformMinMax:
+		if (t.Min != 0 || t.Max != 0) && t.Max <= t.Min {
+			return fmt.Sprintf("empty range [%g, %g]", t.Min, t.Max)
+		}
+	case TransformClip:
+		if t.Max <= t.Min {
+			return fmt.Sprintf("empty range [%g, %g]", t.Min, t.Max)
+		}
+	default:
+		return fmt.Sprintf("unknown transform %q", t.Type)
+	}
+	return ""
+}
+
+// apply transforms values in place.
+func (t *TransformSpec) apply(values []float32) {
+	if len(values) == 0 {
+		return
+	}
+	switch t.Type {
+	case TransformMinMax:
+		lo, hi := t.Min, t.Max
+		if lo == 0 && hi == 0 {
+			hi = 1
+		}
+		vmin, vmax := math.Inf(1), math.Inf(-1)
+		for _, v := range values {
+			vmin, vmax = math.Min(vmin, float64(v)), math.Max(vmax, float64(v))
+		}
+		// A constant payload carries no spread to rescale; it maps to the
+		// middle of the range.
+		width := vmax - vmin
+		for i, v := range values {
+			z := 0.5
+			if width > 0 {
+				z = (float64(v) - vmin) / width
+			}
+			values[i] = float32(lo + z*(hi-lo))
+		}
+	case TransformZScore:
+		mean := reduce(ReduceMean, values)
+		std := reduce(ReduceStd, values)
+		for i, v := range values {
+			if std > 0 {
+				values[i] = float32((float64(v) - mean) / std)
+			} else {
+				values[i] = 0
+			}
+		}
+	case TransformScale:
+		factor := t.Factor
+		if factor == 0 {
+			factor = 1
+		}
+		for i, v := range values {
+			values[i] = float32(float64(v)*factor + t.Offset)
+		}
+	case TransformClip:
+		for i, v := range values {
+			values[i] = float32(math.Max(t.Min, math.Min(t.Max, float64(v))))
+		}
+	}
+}
+
+// transform runs a link's transform pipeline over values in place.
+func transform(specs []TransformSpec, values []float32) {
+	for i := range specs {
+		specs[i].apply(values)
+	}
+}
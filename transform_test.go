@@ -0,0 +1,80 @@
+package drift
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTransformMinMax(t *testing.T) {
+	v := []float32{2, 4, 6}
+	(&TransformSpec{Type: TransformMinMax}).apply(v)
+	if want := []float32{0, 0.5, 1}; !slices.Equal(v, want) {
+		t.Errorf("minmax = %v, want %v", v, want)
+	}
+	v = []float32{2, 4, 6}
+	(&TransformSpec{Type: TransformMinMax, Min: -1, Max: 1}).apply(v)
+	if want := []float32{-1, 0, 1}; !slices.Equal(v, want) {
+		t.Errorf("minmax [-1, 1] = %v, want %v", v, want)
+	}
+}
+
+func TestTransformMinMaxConstant(t *testing.T) {
+	v := []float32{3, 3, 3}
+	(&TransformSpec{Type: TransformMinMax, Min: -2, Max: 4}).apply(v)
+	if want := []float32{1, 1, 1}; !slices.Equal(v, want) {
+		t.Errorf("minmax of a constant payload = %v, want the midpoint %v", v, want)
+	}
+}
+
+func TestTransformZScore(t *testing.T) {
+	v := []float32{1, 3}
+	(&TransformSpec{Type: TransformZScore}).apply(v)
+	if want := []float32{-1, 1}; !slices.Equal(v, want) {
+		t.Errorf("zscore = %v, want %v", v, want)
+	}
+	v = []float32{5, 5, 5}
+	(&TransformSpec{Type: TransformZScore}).apply(v)
+	if want := []float32{0, 0, 0}; !slices.Equal(v, want) {
+		t.Errorf("zscore with std 0 = %v, want %v", v, want)
+	}
+}
+
+func TestTransformScale(t *testing.T) {
+	v := []float32{1, -2}
+	(&TransformSpec{Type: TransformScale, Factor: 2, Offset: 1}).apply(v)
+	if want := []float32{3, -3}; !slices.Equal(v, want) {
+		t.Errorf("scale = %v, want %v", v, want)
+	}
+	v = []float32{1, -2}
+	(&TransformSpec{Type: TransformScale, Offset: 1}).apply(v)
+	if want := []float32{2, -1}; !slices.Equal(v, want) {
+		t.Errorf("scale with factor 0 = %v, want %v", v, want)
+	}
+}
+
+func TestTransformClip(t *testing.T) {
+	v := []float32{-5, 0.5, 5}
+	(&TransformSpec{Type: TransformClip, Min: -1, Max: 1}).apply(v)
+	if want := []float32{-1, 0.5, 1}; !slices.Equal(v, want) {
+		t.Errorf("clip = %v, want %v", v, want)
+	}
+	if msg := (&TransformSpec{Type: TransformClip, Min: 1, Max: 1}).check(); msg == "" {
+		t.Error("clip with an empty range passed check")
+	}
+}
+
+func TestTransformPipelineOrder(t *testing.T) {
+	scale := TransformSpec{Type: TransformScale, Factor: 10}
+	clip := TransformSpec{Type: TransformClip, Min: 0, Max: 1}
+
+	v := []float32{0.05, 0.5}
+	transform([]TransformSpec{scale, clip}, v)
+	if want := []float32{0.5, 1}; !slices.Equal(v, want) {
+		t.Errorf("scale then clip = %v, want %v", v, want)
+	}
+	v = []float32{0.05, 0.5}
+	transform([]TransformSpec{clip, scale}, v)
+	if want := []float32{0.5, 5}; !slices.Equal(v, want) {
+		t.Errorf("clip then scale = %v, want %v", v, want)
+	}
+}
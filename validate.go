@@ -0,0 +1,200 @@
+package drift
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError is a single problem found by Config.Validate.
+type ValidationError struct {
+	Field   string `json:"field"` // JSON path of the offending value, e.g. links[2].source_model
+	Message string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors collects every problem found by Config.Validate.
+type ValidationErrors []*ValidationError
+
+func (es ValidationErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Error()
+	}
+	return "invalid config: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap exposes the individual errors to errors.Is and errors.As.
+func (es ValidationErrors) Unwrap() []error {
+	errs := make([]error, len(es))
+	for i, e := range es {
+		errs[i] = e
+	}
+	return errs
+}
+
+func (es *ValidationErrors) add(field, format string, args ...any) {
+	*es = append(*es, &ValidationError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the config's structure without building any network:
+// loom model definitions must use registered layer types with their
+// required fields and no unknown ones (see RegisterLayerType), and links
+// must name existing models and have unique, non-empty names, unique IDs,
+// non-negative layers and offsets, and a positive size; metadata must
+// belong to existing models and carry semantic versions; probes must have
+// unique names and read an existing model or link. It returns nil or a
+// ValidationErrors listing every problem found.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	if len(c.Models) == 0 {
+		errs.add("models", "no models defined")
+	}
+	for _, name := range sortedKeys(c.Models) {
+		validateModel(&errs, fmt.Sprintf("models[%s]", name), c.Models[name])
+	}
+	for _, name := range sortedKeys(c.Metadata) {
+		field := fmt.Sprintf("metadata[%s]", name)
+		if _, ok := c.Models[name]; !ok {
+			errs.add(field, "unknown model %q", name)
+		}
+		if v := c.Metadata[name].Version; v != "" && !semver.MatchString(v) {
+			errs.add(field+".version", "%q is not a semantic version", v)
+		}
+	}
+	seen := make(map[string]int, len(c.Links))
+	ids := make(map[string]int, len(c.Links))
+	for i, l := range c.Links {
+		field := func(name string) string { return fmt.Sprintf("links[%d].%s", i, name) }
+		if l.Name == "" {
+			errs.add(field("name"), "empty link name")
+		} else if j, dup := seen[l.Name]; dup {
+			errs.add(field("name"), "duplicate link name %q (also links[%d])", l.Name, j)
+		} else {
+			seen[l.Name] = i
+		}
+		if j, dup := ids[l.LinkID()]; dup {
+			errs.add(field("id"), "duplicate link ID %q (also links[%d])", l.LinkID(), j)
+		} else {
+			ids[l.LinkID()] = i
+		}
+		if _, err := c.ResolveModel(l.SourceModel); err != nil {
+			errs.add(field("source_model"), "%v", err)
+		}
+		if _, err := c.ResolveModel(l.TargetModel); err != nil {
+			errs.add(field("target_model"), "%v", err)
+		}
+		if l.SourceLayer < 0 {
+			errs.add(field("source_layer"), "negative layer %d", l.SourceLayer)
+		}
+		if l.TargetOffset < 0 {
+			errs.add(field("target_offset"), "negative offset %d", l.TargetOffset)
+		}
+		if l.LinkSize <= 0 {
+			errs.add(field("link_size"), "size must be positive, got %d", l.LinkSize)
+		}
+		if l.Delivery < 0 || l.Delivery > 1 {
+			errs.add(field("delivery"), "probability must be in [0, 1], got %g", l.Delivery)
+		}
+		if l.Homeostasis != nil {
+			if msg := l.Homeostasis.check(); msg != "" {
+				errs.add(field("homeostasis"), "%s", msg)
+			}
+		}
+		if l.Range != nil {
+			if msg := l.Range.check(); msg != "" {
+				errs.add(field("range"), "%s", msg)
+			}
+		}
+		for j := range l.Transforms {
+			if msg := l.Transforms[j].check(); msg != "" {
+				errs.add(field(fmt.Sprintf("transforms[%d]", j)), "%s", msg)
+			}
+		}
+		for j, w := range l.Active {
+			if msg := w.check(); msg != "" {
+				errs.add(field(fmt.Sprintf("active[%d]", j)), "%s", msg)
+			}
+		}
+	}
+	probes := make(map[string]int, len(c.Probes))
+	for i, p := range c.Probes {
+		field := fmt.Sprintf("probes[%d]", i)
+		if p.Name == "" {
+			errs.add(field+".name", "empty probe name")
+		} else if j, dup := probes[p.Name]; dup {
+			errs.add(field+".name", "duplicate probe name %q (also probes[%d])", p.Name, j)
+		} else {
+			probes[p.Name] = i
+		}
+		if msg := p.check(); msg != "" {
+			errs.add(field, "%s", msg)
+			continue
+		}
+		if p.Model != "" {
+			if _, err := c.ResolveModel(p.Model); err != nil {
+				errs.add(field+".model", "%v", err)
+			}
+		} else if _, ok := c.GetLink(p.Link); !ok {
+			if _, ok := c.GetLinkByID(p.Link); !ok {
+				errs.add(field+".link", "unknown link %q", p.Link)
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateWithModels runs Validate and also checks every link against the
+// layer shapes of its models, inferred from their loom definitions: the
+// source layer must exist and produce at least LinkSize values, and the
+// payload must fit in the target's input. The runtime zero-pads and clips
+// such links silently. Shapes that can't be inferred aren't checked.
+func (c *Config) ValidateWithModels() error {
+	var errs ValidationErrors
+	if err := c.Validate(); err != nil {
+		errs = err.(ValidationErrors)
+	}
+	shapes := make(map[string][]int)
+	sizes := func(model string) []int {
+		if s, ok := shapes[model]; ok {
+			return s
+		}
+		s, _ := layerSizes(c.Models[model])
+		shapes[model] = s
+		return s
+	}
+	for i, l := range c.Links {
+		field := func(name string) string { return fmt.Sprintf("links[%d].%s", i, name) }
+		l = c.resolveLink(l)
+		if src := sizes(l.SourceModel); src != nil && l.SourceLayer >= 0 {
+			if l.SourceLayer >= len(src) {
+				errs.add(field("source_layer"), "model %q has no layer %d (%d layers)", l.SourceModel, l.SourceLayer, len(src)-1)
+			} else if n := src[l.SourceLayer]; n > 0 && n < l.LinkSize {
+				errs.add(field("link_size"), "layer %d of %q has %d outputs, fewer than the %d the link carries", l.SourceLayer, l.SourceModel, n, l.LinkSize)
+			}
+		}
+		if dst := sizes(l.TargetModel); dst != nil && dst[0] > 0 && l.TargetOffset >= 0 {
+			if end := l.TargetOffset + l.LinkSize; end > dst[0] {
+				errs.add(field("target_offset"), "offset %d + size %d exceeds the %d inputs of %q", l.TargetOffset, l.LinkSize, dst[0], l.TargetModel)
+			}
+		}
+	}
+	for i, p := range c.Probes {
+		if p.Model == "" {
+			continue
+		}
+		model, _ := c.ResolveModel(p.Model)
+		if s := sizes(model); s != nil && p.Layer >= len(s) {
+			errs.add(fmt.Sprintf("probes[%d].layer", i), "model %q has no layer %d (%d layers)", model, p.Layer, len(s)-1)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package drift
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is how long Watch waits for a file to stop changing before
+// reloading it. Editors and atomic saves produce several events per save.
+const DefaultDebounce = 200 * time.Millisecond
+
+// WatchOptions configures WatchWithOptions.
+type WatchOptions struct {
+	Debounce time.Duration // Quiet period before reloading; 0 means DefaultDebounce
+	OnError  func(error)   // Optional; receives load, validation and watcher errors
+}
+
+// Watcher reloads a config file whenever it changes on disk.
+type Watcher struct {
+	fsw  *fsnotify.Watcher
+	done chan struct{}
+}
+
+// Watch calls onChange with the freshly loaded config every time the file at
+// path is written, created, or replaced. The containing directory is watched
+// rather than the file itself so atomic saves, which rename a new file over
+// the old one, are picked up. Bursts of events are debounced by
+// DefaultDebounce, and changes that fail to load or validate, or that leave
+// the config as it was, are skipped.
+func Watch(path string, onChange func(*Config)) (*Watcher, error) {
+	return WatchWithOptions(path, WatchOptions{}, onChange)
+}
+
+// WatchWithOptions is Watch with a custom debounce period and error
+// reporting, e.g. to log an edit that broke the config.
+func WatchWithOptions(path string, opts WatchOptions, onChange func(*Config)) (*Watcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fsw.Add(filepath.Dir(abs)); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	debounce := opts.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+	report := func(err error) {
+		if opts.OnError != nil {
+			opts.OnError(err)
+		}
+	}
+	// The hash of the config as last delivered, or as it was when watching
+	// started, so that saves that change nothing are skipped.
+	var last string
+	if cfg, err := LoadFromFile(abs); err == nil {
+		last, _ = cfg.Hash()
+	}
+
+	w := &Watcher{fsw: fsw, done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		timer := time.NewTimer(debounce)
+		timer.Stop()
+		defer timer.Stop()
+		for {
+			select {
+			case ev, ok := <-fsw.Events:
+				if !ok {
+					return
+				}
+				if filepath.Clean(ev.Name) == abs && ev.Has(fsnotify.Write|fsnotify.Create|fsnotify.Rename) {
+					timer.Reset(debounce)
+				}
+			case <-timer.C:
+				cfg, err := LoadFromFile(abs)
+				if err != nil {
+					report(err)
+					continue
+				}
+				if err := cfg.Validate(); err != nil {
+					report(err)
+					continue
+				}
+				if hash, err := cfg.Hash(); err == nil {
+					if hash == last {
+						continue
+					}
+					last = hash
+				}
+				onChange(cfg)
+			case err, ok := <-fsw.Errors:
+				if !ok {
+					return
+				}
+				report(err)
+			}
+		}
+	}()
+	return w, nil
+}
+
+// Close stops watching and waits for any in-progress callback to return.
+// A change still waiting out its debounce period is dropped.
+func (w *Watcher) Close() error {
+	err := w.fsw.Close()
+	<-w.done
+	return err
+}
@@ -0,0 +1,185 @@
+package drift
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// cloneNetwork returns an independent copy of net, weights included.
+func cloneNetwork(net *nn.Network) (*nn.Network, error) {
+	saved, err := net.SerializeModel("clone")
+	if err != nil {
+		return nil, err
+	}
+	return nn.DeserializeModel(saved)
+}
+
+// saveModels serializes the networks of the named models between steps.
+func (r *Runtime) saveModels(names []string) ([]nn.SavedModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := make([]nn.SavedModel, len(names))
+	for i, name := range names {
+		m, ok := r.models[name]
+		if !ok {
+			return nil, fmt.Errorf("model %q not found", name)
+		}
+		s, err := m.net.SerializeModel(name)
+		if err != nil {
+			return nil, fmt.Errorf("model %q: %w", name, err)
+		}
+		saved[i] = s
+	}
+	return saved, nil
+}
+
+// SetNetwork replaces a model's network, for example with a copy trained
+// elsewhere. The step state is reset; the input size must not change.
+func (r *Runtime) SetNetwork(model string, net *nn.Network) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.models[model]
+	if !ok {
+		return fmt.Errorf("model %q not found", model)
+	}
+	m.net = net
+	m.state = net.InitStepState(len(m.input))
+	return nil
+}
+
+// CopyWeightsFrom copies the networks of src into r for every model whose
+// definition is identical in both configs, and returns the names copied.
+// Models that differ keep their own weights.
+func (r *Runtime) CopyWeightsFrom(src *Runtime) ([]string, error) {
+	var copied []string
+	for _, name := range r.Models() {
+		a, okA := r.cfg.Models[name]
+		b, okB := src.cfg.Models[name]
+		if !okA || !okB || string(a) != string(b) {
+			continue
+		}
+		net, err := cloneNetwork(src.Network(name))
+		if err != nil {
+			return copied, fmt.Errorf("model %q: %w", name, err)
+		}
+		if err := r.SetNetwork(name, net); err != nil {
+			return copied, err
+		}
+		copied = append(copied, name)
+	}
+	return copied, nil
+}
+
+// exportWeights serializes net and decodes its weight payload.
+func exportWeights(net *nn.Network) (nn.SavedModel, nn.WeightsData, error) {
+	saved, err := net.SerializeModel("weights")
+	if err != nil {
+		return saved, nn.WeightsData{}, err
+	}
+	wd, err := decodeWeights(saved)
+	return saved, wd, err
+}
+
+// importWeights builds a network from saved with its weights replaced by wd.
+func importWeights(saved nn.SavedModel, wd nn.WeightsData) (*nn.Network, error) {
+	if err := encodeWeights(&saved, wd); err != nil {
+		return nil, err
+	}
+	return nn.DeserializeModel(saved)
+}
+
+// decodeWeights decodes the weight payload of a saved model.
+func decodeWeights(saved nn.SavedModel) (nn.WeightsData, error) {
+	var wd nn.WeightsData
+	raw, err := base64.StdEncoding.DecodeString(saved.Weights.Data)
+	if err != nil {
+		return wd, err
+	}
+	err = json.Unmarshal(raw, &wd)
+	return wd, err
+}
+
+// encodeWeights replaces the weight payload of a saved model with wd.
+func encodeWeights(saved *nn.SavedModel, wd nn.WeightsData) error {
+	raw, err := json.Marshal(wd)
+	if err != nil {
+		return err
+	}
+	saved.Weights.Data = base64.StdEncoding.EncodeToString(raw)
+	return nil
+}
+
+// forEachWeightSlice calls fn with every non-empty weight and bias slice in
+// layers, along with the definition of the layer it belongs to and its field
+// name, descending into parallel branches.
+func forEachWeightSlice(layers []nn.LayerWeights, defs []nn.LayerDefinition, fn func(def nn.LayerDefinition, field string, s []float32)) {
+	floatSlice := reflect.TypeOf([]float32(nil))
+	for i := range layers {
+		var def nn.LayerDefinition
+		if i < len(defs) {
+			def = defs[i]
+		}
+		v := reflect.ValueOf(&layers[i]).Elem()
+		for f := 0; f < v.NumField(); f++ {
+			if field := v.Field(f); field.Type() == floatSlice && field.Len() > 0 {
+				fn(def, v.Type().Field(f).Name, field.Interface().([]float32))
+			}
+		}
+		forEachWeightSlice(layers[i].BranchWeights, def.Branches, fn)
+	}
+}
+
+// initRange returns the interval loom's initializer draws a weight field
+// from. Fields it fills with constants (zero biases, unit norm gains) report
+// ok=false.
+func initRange(def nn.LayerDefinition, field string, s []float32) (lo, hi float32, ok bool) {
+	for _, v := range s[1:] {
+		if v != s[0] {
+			ok = true
+			break
+		}
+	}
+	if !ok {
+		return 0, 0, false
+	}
+	switch field {
+	case "Kernel":
+		// Loom writes dense layers with Width 1 and the input size in
+		// InputHeight; hand-written ones use Width or InputSize.
+		fanIn := firstPositive(def.InputHeight, def.InputSize, def.Width)
+		if def.Type == "conv2d" {
+			fanIn = def.InputChannels * def.KernelSize * def.KernelSize
+		}
+		if fanIn <= 0 {
+			return 0, 0, false
+		}
+		return -1 / float32(fanIn), 1 / float32(fanIn), true
+	case "Biases", "ConvBias":
+		return 0, 0.01, true
+	}
+	return -0.1, 0.1, true
+}
+
+// seedNetwork returns a copy of net whose weights are redrawn from rng,
+// the model's weights stream, using the same ranges as loom's initializer,
+// so the same seeded config always starts from the same weights.
+func seedNetwork(net *nn.Network, rng Rand) (*nn.Network, error) {
+	saved, wd, err := exportWeights(net)
+	if err != nil {
+		return nil, err
+	}
+	forEachWeightSlice(wd.Layers, saved.Config.Layers, func(def nn.LayerDefinition, field string, s []float32) {
+		lo, hi, ok := initRange(def, field, s)
+		if !ok {
+			return
+		}
+		for i := range s {
+			s[i] = lo + rng.Float32()*(hi-lo)
+		}
+	})
+	return importWeights(saved, wd)
+}
@@ -0,0 +1,61 @@
+package drift
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/openfluke/loom/nn"
+)
+
+// kernelBounds returns the largest |w| of each dense kernel of a model, in
+// layer order.
+func kernelBounds(t *testing.T, r *Runtime, model string) []float32 {
+	t.Helper()
+	saved, wd, err := exportWeights(r.Network(model))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []float32
+	forEachWeightSlice(wd.Layers, saved.Config.Layers, func(def nn.LayerDefinition, field string, s []float32) {
+		if field != "Kernel" {
+			return
+		}
+		var m float32
+		for _, v := range s {
+			m = max(m, v, -v)
+		}
+		out = append(out, m)
+	})
+	return out
+}
+
+func TestSeededWeightRangesMatchLoom(t *testing.T) {
+	build := func(seed int64) *Runtime {
+		c := NewConfig("weights")
+		c.Seed = seed
+		c.Models["m"] = json.RawMessage(`{"batch_size":1,"grid_rows":1,"grid_cols":1,"layers_per_cell":2,"layers":[
+			{"type": "dense", "input_size": 64, "output_size": 32, "activation": "leaky_relu"},
+			{"type": "dense", "input_size": 32, "output_size": 4, "activation": "none"}]}`)
+		r, err := NewRuntime(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	fanIn := []float32{64, 32}
+	unseeded, seeded := kernelBounds(t, build(0), "m"), kernelBounds(t, build(7), "m")
+	if len(unseeded) != len(fanIn) || len(seeded) != len(fanIn) {
+		t.Fatalf("got %d and %d kernels, want %d", len(unseeded), len(seeded), len(fanIn))
+	}
+	for i, n := range fanIn {
+		bound := 1 / n
+		// Both draw thousands of weights uniformly from ±1/fan-in, so each
+		// should come close to the bound without crossing it.
+		for _, got := range []float32{unseeded[i], seeded[i]} {
+			if got > bound || got < 0.9*bound {
+				t.Errorf("layer %d: max |w| unseeded %g, seeded %g; want both near %g", i, unseeded[i], seeded[i], bound)
+				break
+			}
+		}
+	}
+}
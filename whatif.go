@@ -0,0 +1,112 @@
+package drift
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScoreFunc scores the outputs a runtime produced for one recorded step.
+// Higher is better; typical scores compare an output against a label stored
+// alongside the recording.
+type ScoreFunc func(rec StepRecord, outputs map[string][]float32) float64
+
+// WhatIfOptions configures WhatIf.
+type WhatIfOptions struct {
+	// Checkpoint, if set, is loaded into the base runtime before replay so the
+	// comparison uses trained weights.
+	Checkpoint string
+	// Score, if set, is evaluated on both runtimes at every step.
+	Score ScoreFunc
+}
+
+// WhatIfReport compares a config edit against its base on recorded data.
+type WhatIfReport struct {
+	Steps        int                `json:"steps"`
+	BaseScore    float64            `json:"base_score"`
+	VariantScore float64            `json:"variant_score"`
+	ScoreDelta   float64            `json:"score_delta"`
+	SharedModels []string           `json:"shared_models"`
+	Divergence   map[string]float64 `json:"output_divergence"` // Mean absolute output difference per model
+}
+
+// WhatIf replays recorded observations through base and through an edited
+// variant (halved LinkSize, added scenario, disabled link, ...) and reports
+// how outputs and the optional score change, without a live environment.
+// Models defined identically in both configs share weights, so differences
+// come only from the edit.
+func WhatIf(base, variant *Config, steps []StepRecord, opts WhatIfOptions) (*WhatIfReport, error) {
+	br, err := NewRuntime(base)
+	if err != nil {
+		return nil, fmt.Errorf("base: %w", err)
+	}
+	if opts.Checkpoint != "" {
+		if err := br.LoadCheckpoint(opts.Checkpoint); err != nil {
+			return nil, fmt.Errorf("base: %w", err)
+		}
+	}
+	vr, err := NewRuntime(variant)
+	if err != nil {
+		return nil, fmt.Errorf("variant: %w", err)
+	}
+	shared, err := vr.CopyWeightsFrom(br)
+	if err != nil {
+		return nil, fmt.Errorf("variant: %w", err)
+	}
+
+	report := &WhatIfReport{SharedModels: shared, Divergence: make(map[string]float64)}
+	for _, rec := range steps {
+		bo, err := br.Step(rec.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("base step %d: %w", rec.Step, err)
+		}
+		vo, err := vr.Step(rec.Inputs)
+		if err != nil {
+			return nil, fmt.Errorf("variant step %d: %w", rec.Step, err)
+		}
+		if opts.Score != nil {
+			report.BaseScore += opts.Score(rec, bo)
+			report.VariantScore += opts.Score(rec, vo)
+		}
+		for name, out := range bo {
+			if other, ok := vo[name]; ok {
+				report.Divergence[name] += meanAbsDiff(out, other)
+			}
+		}
+		report.Steps++
+	}
+
+	if report.Steps > 0 {
+		n := float64(report.Steps)
+		report.BaseScore /= n
+		report.VariantScore /= n
+		for name := range report.Divergence {
+			report.Divergence[name] /= n
+		}
+	}
+	report.ScoreDelta = report.VariantScore - report.BaseScore
+	return report, nil
+}
+
+// meanAbsDiff returns the mean absolute difference over the common prefix of
+// a and b, counting each extra element of the longer slice as fully different.
+func meanAbsDiff(a, b []float32) float64 {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	if n == 0 {
+		return 0
+	}
+	var sum float64
+	for i := 0; i < n; i++ {
+		switch {
+		case i >= len(a):
+			sum += math.Abs(float64(b[i]))
+		case i >= len(b):
+			sum += math.Abs(float64(a[i]))
+		default:
+			sum += math.Abs(float64(a[i] - b[i]))
+		}
+	}
+	return sum / float64(n)
+}
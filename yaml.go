@@ -0,0 +1,149 @@
+package drift
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ToYAML serializes the config to a YAML string. Fields keep the order of
+// the JSON form and model definitions become nested YAML documents, so loom
+// layers can be read and edited without embedded JSON.
+func (c *Config) ToYAML() (string, error) {
+	data, err := c.yaml()
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FromYAML deserializes a YAML string into a Config.
+func FromYAML(data string) (*Config, error) {
+	return parseYAML([]byte(data))
+}
+
+// SaveToYAML saves the config to a YAML file, atomically and under the same
+// advisory lock as SaveToFile.
+func (c *Config) SaveToYAML(path string) error {
+	data, err := c.yaml()
+	if err != nil {
+		return err
+	}
+	return withFileLock(path, func() error {
+		return writeFileAtomic(path, data, 0644, false)
+	})
+}
+
+// LoadFromYAML loads a config from a YAML file, holding a shared advisory
+// lock while reading.
+func LoadFromYAML(path string) (*Config, error) {
+	unlock, err := lockFile(path, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseYAML(data)
+}
+
+// yaml renders c through its JSON form, so struct tags and model bodies are
+// treated exactly as by ToJSON.
+func (c *Config) yaml() ([]byte, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := jsonToYAML(dec)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseYAML decodes a YAML document through the JSON form, so models end
+// up as raw JSON definitions exactly as when loading JSON.
+func parseYAML(data []byte) (*Config, error) {
+	raw, err := yamlToJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	return decodeConfig(raw, nil)
+}
+
+// yamlToJSON converts a YAML document to JSON.
+func yamlToJSON(data []byte) ([]byte, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("yaml: %w", err)
+	}
+	return raw, nil
+}
+
+// jsonToYAML reads the next JSON value from dec and returns it as a YAML
+// node, preserving key order.
+func jsonToYAML(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if t == '{' {
+			node.Kind = yaml.MappingNode
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := jsonToYAML(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if bytes.ContainsAny([]byte(t), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return nil, io.ErrUnexpectedEOF
+}